@@ -0,0 +1,110 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds. Every *Error matches exactly one of these with errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrPreconditionRequired = errors.New("precondition required")
+)
+
+// ErrNotAuthenticated is reported by handlers reached without an
+// authenticated user
+var ErrNotAuthenticated = Unauthorized("not_authenticated", "User not authenticated")
+
+// Error is a domain error with a machine-readable code and optional
+// per-field details
+type Error struct {
+	Kind    error             `json:"-"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Details interface{}       `json:"details,omitempty"`
+	Err     error             `json:"-"`
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Is reports whether target is this error's kind, or an *Error with the
+// same kind and code
+func (e *Error) Is(target error) bool {
+	if t, ok := target.(*Error); ok {
+		return t.Kind == e.Kind && t.Code == e.Code
+	}
+	return target == e.Kind
+}
+
+// Unwrap returns the underlying cause, if any
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns a copy of the error with cause attached
+func (e *Error) Wrap(cause error) *Error {
+	cp := *e
+	cp.Err = cause
+	return &cp
+}
+
+// WithDetails returns a copy of the error carrying extra data for the client
+func (e *Error) WithDetails(details interface{}) *Error {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+// NotFound creates a not found error
+func NotFound(code, message string) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+// Conflict creates a conflict error
+func Conflict(code, message string) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+// Validation creates a validation error with optional field details
+func Validation(code, message string, fields map[string]string) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Message: message, Fields: fields}
+}
+
+// Unauthorized creates an unauthorized error
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: message}
+}
+
+// Forbidden creates a forbidden error
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+// PreconditionFailed creates an error for a failed conditional request
+func PreconditionFailed(code, message string) *Error {
+	return &Error{Kind: ErrPreconditionFailed, Code: code, Message: message}
+}
+
+// PreconditionRequired creates an error for a request missing a required
+// condition
+func PreconditionRequired(code, message string) *Error {
+	return &Error{Kind: ErrPreconditionRequired, Code: code, Message: message}
+}
+
+// Field creates a validation error for a single invalid field
+func Field(field, message string) *Error {
+	return Validation("invalid_field", message, map[string]string{field: message})
+}
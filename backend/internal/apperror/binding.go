@@ -0,0 +1,42 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FromBinding converts a request binding error into a validation error with
+// one entry per invalid field
+func FromBinding(err error) *Error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return Validation("invalid_request", err.Error(), nil)
+	}
+
+	fields := make(map[string]string, len(verrs))
+	for _, fe := range verrs {
+		fields[fe.Field()] = describeFieldError(fe)
+	}
+
+	return Validation("invalid_request", "Invalid request body", fields)
+}
+
+func describeFieldError(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	case "uuid":
+		return "must be a UUID"
+	}
+	return fmt.Sprintf("failed %s validation", fe.Tag())
+}
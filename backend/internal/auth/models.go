@@ -29,8 +29,42 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// RefreshRequest exchanges a refresh token for a new token pair
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest ends the session a refresh token belongs to, or every
+// session of its user when All is set
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+	All          bool   `json:"all"`
+}
+
 // AuthResponse represents the response after successful authentication
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  *User  `json:"user"`
-}
\ No newline at end of file
+	Token            string    `json:"token"`
+	TokenExpiresAt   time.Time `json:"token_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	User             *User     `json:"user"`
+}
+
+// Session is a login on one device. Every refresh token issued for it
+// belongs to the same family, and revoking the session revokes them all.
+type Session struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt     *time.Time `json:"revoked_at" db:"revoked_at"`
+	RevokedReason *string    `json:"revoked_reason" db:"revoked_reason"`
+}
+
+// RefreshToken is a stored refresh token. Only a hash of the token is kept.
+type RefreshToken struct {
+	TokenHash string     `db:"token_hash"`
+	SessionID uuid.UUID  `db:"session_id"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
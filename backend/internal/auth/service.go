@@ -1,39 +1,53 @@
 package auth
 
 import (
-	"database/sql"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+
+	"character-sheet-backend/internal/jwtkeys"
 )
 
+// TokenConfig controls how tokens are signed and how long they last
+type TokenConfig struct {
+	Keys       *jwtkeys.Keyring
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
 // Service handles authentication operations
 type Service struct {
-	db        *sql.DB
-	jwtSecret string
+	users    UserStore
+	sessions SessionStore
+	tokens   TokenConfig
 }
 
 // NewService creates a new auth service
-func NewService(db *sql.DB, jwtSecret string) *Service {
+func NewService(users UserStore, sessions SessionStore, tokens TokenConfig) *Service {
 	return &Service{
-		db:        db,
-		jwtSecret: jwtSecret,
+		users:    users,
+		sessions: sessions,
+		tokens:   tokens,
 	}
 }
 
 // Register creates a new user account
 func (s *Service) Register(req *RegisterRequest) (*AuthResponse, error) {
 	// Check if user already exists
-	var exists bool
-	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", req.Email).Scan(&exists)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check existing user: %w", err)
+	_, err := s.users.GetByEmail(req.Email)
+	if err == nil {
+		return nil, ErrEmailTaken
 	}
-	if exists {
-		return nil, fmt.Errorf("user with email %s already exists", req.Email)
+	if !errors.Is(err, ErrUserNotFound) {
+		return nil, fmt.Errorf("failed to check existing user: %w", err)
 	}
 
 	// Hash password
@@ -52,95 +66,181 @@ func (s *Service) Register(req *RegisterRequest) (*AuthResponse, error) {
 		UpdatedAt:    time.Now(),
 	}
 
-	query := `
-		INSERT INTO users (id, name, email, password_hash, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
-	_, err = s.db.Exec(query, user.ID, user.Name, user.Email, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
-
-	// Generate JWT token
-	token, err := s.generateToken(user.ID.String())
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+	if err := s.users.Create(user); err != nil {
+		return nil, err
 	}
 
-	return &AuthResponse{
-		Token: token,
-		User:  user,
-	}, nil
+	return s.startSession(user)
 }
 
-// Login authenticates a user and returns a JWT token
+// Login authenticates a user and starts a new session
 func (s *Service) Login(req *LoginRequest) (*AuthResponse, error) {
 	// Get user by email
-	user := &User{}
-	query := `
-		SELECT id, name, email, password_hash, created_at, updated_at
-		FROM users
-		WHERE email = $1
-	`
-	err := s.db.QueryRow(query, req.Email).Scan(
-		&user.ID, &user.Name, &user.Email, &user.PasswordHash,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("invalid email or password")
+	user, err := s.users.GetByEmail(req.Email)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrInvalidCredentials
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, err
 	}
 
 	// Check password
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
-		return nil, fmt.Errorf("invalid email or password")
+		return nil, ErrInvalidCredentials
 	}
 
-	// Generate JWT token
-	token, err := s.generateToken(user.ID.String())
+	return s.startSession(user)
+}
+
+// GetUserByID retrieves a user by their ID
+func (s *Service) GetUserByID(userID string) (*User, error) {
+	return s.users.GetByID(userID)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new
+// refresh token. Each refresh token works once; presenting a used one again
+// means it was stolen or replayed, so the whole session is revoked.
+func (s *Service) Refresh(req *RefreshRequest) (*AuthResponse, error) {
+	tokenHash := hashToken(req.RefreshToken)
+
+	stored, session, err := s.sessions.GetRefreshToken(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+	if stored.UsedAt != nil {
+		return nil, s.revokeForReuse(session)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	refreshToken, next, err := s.newRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.RotateRefreshToken(tokenHash, next); err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			// Another request rotated this token first
+			return nil, s.revokeForReuse(session)
+		}
+		return nil, err
+	}
+
+	user, err := s.users.GetByID(session.UserID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.respond(user, session.ID, refreshToken, next)
+}
+
+// Logout revokes the session a refresh token belongs to, or every session of
+// the token's user when req.All is set
+func (s *Service) Logout(req *LogoutRequest) error {
+	_, session, err := s.sessions.GetRefreshToken(hashToken(req.RefreshToken))
+	if err != nil {
+		return err
+	}
+
+	if req.All {
+		return s.sessions.RevokeUserSessions(session.UserID.String(), "logged out everywhere")
+	}
+	return s.sessions.RevokeSession(session.ID.String(), "logged out")
+}
+
+// LogoutAll revokes every session of a user
+func (s *Service) LogoutAll(userID string) error {
+	return s.sessions.RevokeUserSessions(userID, "logged out everywhere")
+}
+
+// IsSessionActive reports whether an access token's session is still valid
+func (s *Service) IsSessionActive(sessionID string) (bool, error) {
+	return s.sessions.IsSessionActive(sessionID)
+}
+
+// startSession opens a new session for a user and issues its first tokens
+func (s *Service) startSession(user *User) (*AuthResponse, error) {
+	session := &Session{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		CreatedAt: time.Now(),
+	}
+
+	refreshToken, stored, err := s.newRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.CreateSession(session, stored); err != nil {
+		return nil, err
+	}
+
+	return s.respond(user, session.ID, refreshToken, stored)
+}
+
+// respond issues an access token for the session and builds the response
+func (s *Service) respond(user *User, sessionID uuid.UUID, refreshToken string, stored *RefreshToken) (*AuthResponse, error) {
+	expiresAt := time.Now().Add(s.tokens.AccessTTL)
+	token, err := s.generateToken(user.ID.String(), sessionID.String(), expiresAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
 	return &AuthResponse{
-		Token: token,
-		User:  user,
+		Token:            token,
+		TokenExpiresAt:   expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+		User:             user,
 	}, nil
 }
 
-// GetUserByID retrieves a user by their ID
-func (s *Service) GetUserByID(userID string) (*User, error) {
-	user := &User{}
-	query := `
-		SELECT id, name, email, password_hash, created_at, updated_at
-		FROM users
-		WHERE id = $1
-	`
-	err := s.db.QueryRow(query, userID).Scan(
-		&user.ID, &user.Name, &user.Email, &user.PasswordHash,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+// revokeForReuse ends a session whose refresh token was replayed
+func (s *Service) revokeForReuse(session *Session) error {
+	if err := s.sessions.RevokeSession(session.ID.String(), "refresh token reused"); err != nil {
+		return err
 	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+	return ErrRefreshTokenReused
+}
+
+// newRefreshToken creates a random refresh token, returning it along with
+// the hashed record to store
+func (s *Service) newRefreshToken(sessionID uuid.UUID) (string, *RefreshToken, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	now := time.Now()
+	return token, &RefreshToken{
+		TokenHash: hashToken(token),
+		SessionID: sessionID,
+		ExpiresAt: now.Add(s.tokens.RefreshTTL),
+		CreatedAt: now,
+	}, nil
+}
 
-	return user, nil
+// hashToken returns the SHA-256 of a refresh token. Refresh tokens are long
+// and random, so a fast unsalted hash is enough to keep them useless if the
+// table leaks.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
-// generateToken creates a new JWT token for the user
-func (s *Service) generateToken(userID string) (string, error) {
+// generateToken creates a new JWT access token for a user's session
+func (s *Service) generateToken(userID, sessionID string, expiresAt time.Time) (string, error) {
 	claims := jwt.MapClaims{
+		"sub":     userID,
 		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(), // 7 days
-		"iat":     time.Now().Unix(),
+		"sid":     sessionID,
+		"jti":     uuid.New().String(),
+		"exp":     expiresAt.Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
-}
\ No newline at end of file
+	return s.tokens.Keys.Sign(claims, time.Now())
+}
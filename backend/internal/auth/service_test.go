@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"character-sheet-backend/internal/jwtkeys"
+)
+
+// newTestService creates a service over in-memory stores signing with an
+// HMAC key, whose refresh tokens last refreshTTL
+func newTestService(t *testing.T, refreshTTL time.Duration) (*Service, *jwtkeys.Keyring) {
+	t.Helper()
+	key, err := jwtkeys.HMACKey("test-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := jwtkeys.NewKeyring("character-sheet-test", "character-sheet-test", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(NewMemoryUserStore(), NewMemorySessionStore(), TokenConfig{
+		Keys:       keys,
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: refreshTTL,
+	})
+	return s, keys
+}
+
+func register(t *testing.T, s *Service, email string) *AuthResponse {
+	t.Helper()
+	resp, err := s.Register(&RegisterRequest{Name: "Test", Email: email, Password: "password123"})
+	if err != nil {
+		t.Fatalf("Register returned %v", err)
+	}
+	return resp
+}
+
+func login(t *testing.T, s *Service, email string) *AuthResponse {
+	t.Helper()
+	resp, err := s.Login(&LoginRequest{Email: email, Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login returned %v", err)
+	}
+	return resp
+}
+
+// sessionID returns the session an access token belongs to
+func sessionID(t *testing.T, keys *jwtkeys.Keyring, resp *AuthResponse) string {
+	t.Helper()
+	claims, err := keys.Parse(resp.Token)
+	if err != nil {
+		t.Fatalf("parsing the access token: %v", err)
+	}
+	sid, _ := claims["sid"].(string)
+	return sid
+}
+
+// expectActive checks whether a session is still active
+func expectActive(t *testing.T, s *Service, sid string, want bool) {
+	t.Helper()
+	active, err := s.IsSessionActive(sid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active != want {
+		t.Errorf("session %s active = %t, want %t", sid, active, want)
+	}
+}
+
+func TestRefreshRotation(t *testing.T) {
+	s, keys := newTestService(t, time.Hour)
+	first := register(t, s, "owner@example.com")
+	sid := sessionID(t, keys, first)
+
+	second, err := s.Refresh(&RefreshRequest{RefreshToken: first.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("refreshing returned the same refresh token")
+	}
+	if got := sessionID(t, keys, second); got != sid {
+		t.Errorf("refreshed access token has session %s, want %s", got, sid)
+	}
+	third, err := s.Refresh(&RefreshRequest{RefreshToken: second.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replaying a rotated token revokes the session, so the newest token in
+	// the family stops working too
+	if _, err := s.Refresh(&RefreshRequest{RefreshToken: first.RefreshToken}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("replaying a rotated token returned %v, want ErrRefreshTokenReused", err)
+	}
+	expectActive(t, s, sid, false)
+	if _, err := s.Refresh(&RefreshRequest{RefreshToken: third.RefreshToken}); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("the newest token after a replay returned %v, want ErrSessionRevoked", err)
+	}
+	if _, err := s.Refresh(&RefreshRequest{RefreshToken: first.RefreshToken}); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("replaying again returned %v, want ErrSessionRevoked", err)
+	}
+
+	// Logging in again starts a new session
+	again := login(t, s, "owner@example.com")
+	if _, err := s.Refresh(&RefreshRequest{RefreshToken: again.RefreshToken}); err != nil {
+		t.Errorf("refreshing a new session returned %v", err)
+	}
+}
+
+func TestRefreshInvalid(t *testing.T) {
+	s, _ := newTestService(t, -time.Minute)
+	resp := register(t, s, "owner@example.com")
+
+	if _, err := s.Refresh(&RefreshRequest{RefreshToken: resp.RefreshToken}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("an expired token returned %v, want ErrInvalidRefreshToken", err)
+	}
+	if _, err := s.Refresh(&RefreshRequest{RefreshToken: "not-a-token"}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("an unknown token returned %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	s, keys := newTestService(t, time.Hour)
+	phone := register(t, s, "owner@example.com")
+	laptop := login(t, s, "owner@example.com")
+	tablet := login(t, s, "owner@example.com")
+	other := register(t, s, "other@example.com")
+
+	// Logging out ends only that session
+	if err := s.Logout(&LogoutRequest{RefreshToken: phone.RefreshToken}); err != nil {
+		t.Fatal(err)
+	}
+	expectActive(t, s, sessionID(t, keys, phone), false)
+	expectActive(t, s, sessionID(t, keys, laptop), true)
+	if _, err := s.Refresh(&RefreshRequest{RefreshToken: phone.RefreshToken}); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("refreshing a logged out session returned %v, want ErrSessionRevoked", err)
+	}
+
+	// Logging out everywhere ends every session of the user and no one else's
+	if err := s.Logout(&LogoutRequest{RefreshToken: laptop.RefreshToken, All: true}); err != nil {
+		t.Fatal(err)
+	}
+	for _, resp := range []*AuthResponse{laptop, tablet} {
+		expectActive(t, s, sessionID(t, keys, resp), false)
+		if _, err := s.Refresh(&RefreshRequest{RefreshToken: resp.RefreshToken}); !errors.Is(err, ErrSessionRevoked) {
+			t.Errorf("refreshing after logging out everywhere returned %v, want ErrSessionRevoked", err)
+		}
+	}
+	expectActive(t, s, sessionID(t, keys, other), true)
+
+	if err := s.Logout(&LogoutRequest{RefreshToken: "not-a-token"}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("logging out an unknown token returned %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestLogoutAll(t *testing.T) {
+	s, keys := newTestService(t, time.Hour)
+	first := register(t, s, "owner@example.com")
+	second := login(t, s, "owner@example.com")
+	other := register(t, s, "other@example.com")
+
+	if err := s.LogoutAll(first.User.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+	expectActive(t, s, sessionID(t, keys, first), false)
+	expectActive(t, s, sessionID(t, keys, second), false)
+	expectActive(t, s, sessionID(t, keys, other), true)
+	expectActive(t, s, "no-such-session", false)
+}
+
+func TestLoginRejected(t *testing.T) {
+	s, _ := newTestService(t, time.Hour)
+	register(t, s, "owner@example.com")
+
+	if _, err := s.Register(&RegisterRequest{Name: "Again", Email: "owner@example.com", Password: "password123"}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("registering a taken email returned %v, want ErrEmailTaken", err)
+	}
+	if _, err := s.Login(&LoginRequest{Email: "owner@example.com", Password: "wrong"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("a wrong password returned %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := s.Login(&LoginRequest{Email: "nobody@example.com", Password: "password123"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("an unknown email returned %v, want ErrInvalidCredentials", err)
+	}
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown or expired refresh tokens
+	ErrInvalidRefreshToken = apperror.Unauthorized("invalid_refresh_token", "Invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token
+	// is presented again; the whole session is revoked when this happens
+	ErrRefreshTokenReused = apperror.Unauthorized("refresh_token_reused", "Refresh token has already been used; please log in again")
+	// ErrSessionRevoked is returned for tokens belonging to a revoked session
+	ErrSessionRevoked = apperror.Unauthorized("session_revoked", "Session has been logged out")
+)
+
+// SessionStore persists login sessions and their refresh tokens
+type SessionStore interface {
+	// CreateSession stores a new session with its first refresh token
+	CreateSession(session *Session, token *RefreshToken) error
+	// GetRefreshToken returns a refresh token and the session it belongs to
+	GetRefreshToken(tokenHash string) (*RefreshToken, *Session, error)
+	// RotateRefreshToken marks a refresh token used and stores its
+	// replacement, failing with ErrRefreshTokenReused if it was already used
+	RotateRefreshToken(oldHash string, next *RefreshToken) error
+	// RevokeSession revokes a session and with it every refresh token
+	RevokeSession(sessionID, reason string) error
+	// RevokeUserSessions revokes every active session of a user
+	RevokeUserSessions(userID, reason string) error
+	// IsSessionActive reports whether a session exists and isn't revoked
+	IsSessionActive(sessionID string) (bool, error)
+}
+
+// MemorySessionStore is a SessionStore kept in process memory, for tests
+// and demos that run without a database
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+	tokens   map[string]*RefreshToken
+}
+
+// NewMemorySessionStore creates an empty in-memory session store
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		sessions: make(map[string]*Session),
+		tokens:   make(map[string]*RefreshToken),
+	}
+}
+
+// CreateSession stores a new session with its first refresh token
+func (s *MemorySessionStore) CreateSession(session *Session, token *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionCopy := *session
+	tokenCopy := *token
+	s.sessions[session.ID.String()] = &sessionCopy
+	s.tokens[token.TokenHash] = &tokenCopy
+	return nil
+}
+
+// GetRefreshToken returns a refresh token and the session it belongs to
+func (s *MemorySessionStore) GetRefreshToken(tokenHash string) (*RefreshToken, *Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token, ok := s.tokens[tokenHash]
+	if !ok {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	session, ok := s.sessions[token.SessionID.String()]
+	if !ok {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	tokenCopy := *token
+	sessionCopy := *session
+	return &tokenCopy, &sessionCopy, nil
+}
+
+// RotateRefreshToken marks a refresh token used and stores its replacement
+func (s *MemorySessionStore) RotateRefreshToken(oldHash string, next *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.tokens[oldHash]
+	if !ok {
+		return ErrInvalidRefreshToken
+	}
+	if old.UsedAt != nil {
+		return ErrRefreshTokenReused
+	}
+
+	now := time.Now()
+	old.UsedAt = &now
+	nextCopy := *next
+	s.tokens[next.TokenHash] = &nextCopy
+	return nil
+}
+
+// RevokeSession revokes a session and with it every refresh token
+func (s *MemorySessionStore) RevokeSession(sessionID, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[sessionID]; ok {
+		revoke(session, reason)
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes every active session of a user
+func (s *MemorySessionStore) RevokeUserSessions(userID, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, session := range s.sessions {
+		if session.UserID.String() == userID {
+			revoke(session, reason)
+		}
+	}
+	return nil
+}
+
+// IsSessionActive reports whether a session exists and isn't revoked
+func (s *MemorySessionStore) IsSessionActive(sessionID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[sessionID]
+	return ok && session.RevokedAt == nil, nil
+}
+
+func revoke(session *Session, reason string) {
+	if session.RevokedAt != nil {
+		return
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	session.RevokedReason = &reason
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// PostgresSessionStore is a SessionStore backed by PostgreSQL
+type PostgresSessionStore struct {
+	db *sql.DB
+}
+
+// NewPostgresSessionStore creates a session store using the given database
+func NewPostgresSessionStore(db *sql.DB) *PostgresSessionStore {
+	return &PostgresSessionStore{db: db}
+}
+
+// CreateSession stores a new session with its first refresh token
+func (s *PostgresSessionStore) CreateSession(session *Session, token *RefreshToken) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"INSERT INTO auth_sessions (id, user_id, created_at) VALUES ($1, $2, $3)",
+		session.ID, session.UserID, session.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	if err := insertRefreshToken(tx, token); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit session: %w", err)
+	}
+	return nil
+}
+
+// GetRefreshToken returns a refresh token and the session it belongs to
+func (s *PostgresSessionStore) GetRefreshToken(tokenHash string) (*RefreshToken, *Session, error) {
+	query := `
+		SELECT t.token_hash, t.session_id, t.expires_at, t.used_at, t.created_at,
+		       s.id, s.user_id, s.created_at, s.revoked_at, s.revoked_reason
+		FROM refresh_tokens t
+		JOIN auth_sessions s ON s.id = t.session_id
+		WHERE t.token_hash = $1
+	`
+
+	token := &RefreshToken{}
+	session := &Session{}
+	err := s.db.QueryRow(query, tokenHash).Scan(
+		&token.TokenHash, &token.SessionID, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt,
+		&session.ID, &session.UserID, &session.CreatedAt, &session.RevokedAt, &session.RevokedReason,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return token, session, nil
+}
+
+// RotateRefreshToken marks a refresh token used and stores its replacement
+func (s *PostgresSessionStore) RotateRefreshToken(oldHash string, next *RefreshToken) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Only one request can flip used_at, so concurrent reuse is caught too
+	result, err := tx.Exec(
+		"UPDATE refresh_tokens SET used_at = NOW() WHERE token_hash = $1 AND used_at IS NULL",
+		oldHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrRefreshTokenReused
+	}
+
+	if err := insertRefreshToken(tx, next); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit refresh token rotation: %w", err)
+	}
+	return nil
+}
+
+// RevokeSession revokes a session and with it every refresh token
+func (s *PostgresSessionStore) RevokeSession(sessionID, reason string) error {
+	_, err := s.db.Exec(
+		"UPDATE auth_sessions SET revoked_at = NOW(), revoked_reason = $2 WHERE id = $1 AND revoked_at IS NULL",
+		sessionID, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes every active session of a user
+func (s *PostgresSessionStore) RevokeUserSessions(userID, reason string) error {
+	_, err := s.db.Exec(
+		"UPDATE auth_sessions SET revoked_at = NOW(), revoked_reason = $2 WHERE user_id = $1 AND revoked_at IS NULL",
+		userID, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// IsSessionActive reports whether a session exists and isn't revoked
+func (s *PostgresSessionStore) IsSessionActive(sessionID string) (bool, error) {
+	var active bool
+	err := s.db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM auth_sessions WHERE id = $1 AND revoked_at IS NULL)",
+		sessionID,
+	).Scan(&active)
+	if err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return active, nil
+}
+
+func insertRefreshToken(tx *sql.Tx, token *RefreshToken) error {
+	_, err := tx.Exec(
+		"INSERT INTO refresh_tokens (token_hash, session_id, expires_at, created_at) VALUES ($1, $2, $3, $4)",
+		token.TokenHash, token.SessionID, token.ExpiresAt, token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return nil
+}
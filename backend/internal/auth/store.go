@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"sync"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+var (
+	// ErrUserNotFound is returned when no user matches a lookup
+	ErrUserNotFound = apperror.NotFound("user_not_found", "User not found")
+	// ErrEmailTaken is returned when creating a user with an email already in use
+	ErrEmailTaken = apperror.Conflict("email_taken", "A user with this email already exists")
+	// ErrInvalidCredentials is returned when login details don't match a user
+	ErrInvalidCredentials = apperror.Unauthorized("invalid_credentials", "Invalid email or password")
+)
+
+// UserStore persists user accounts
+type UserStore interface {
+	// GetByID returns the user with the given ID
+	GetByID(userID string) (*User, error)
+	// GetByEmail returns the user with the given email
+	GetByEmail(email string) (*User, error)
+	// Create stores a new user, failing with ErrEmailTaken on duplicates
+	Create(user *User) error
+}
+
+// MemoryUserStore is a UserStore kept in process memory, for tests and
+// demos that run without a database
+type MemoryUserStore struct {
+	mu      sync.RWMutex
+	users   map[string]*User
+	byEmail map[string]string
+}
+
+// NewMemoryUserStore creates an empty in-memory user store
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{
+		users:   make(map[string]*User),
+		byEmail: make(map[string]string),
+	}
+}
+
+// GetByID returns the user with the given ID
+func (s *MemoryUserStore) GetByID(userID string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	cp := *user
+	return &cp, nil
+}
+
+// GetByEmail returns the user with the given email
+func (s *MemoryUserStore) GetByEmail(email string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.byEmail[email]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	cp := *s.users[id]
+	return &cp, nil
+}
+
+// Create stores a new user
+func (s *MemoryUserStore) Create(user *User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.byEmail[user.Email]; exists {
+		return ErrEmailTaken
+	}
+
+	cp := *user
+	s.users[user.ID.String()] = &cp
+	s.byEmail[user.Email] = user.ID.String()
+	return nil
+}
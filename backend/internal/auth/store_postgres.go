@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// PostgresUserStore is a UserStore backed by PostgreSQL
+type PostgresUserStore struct {
+	db *sql.DB
+}
+
+// NewPostgresUserStore creates a user store using the given database
+func NewPostgresUserStore(db *sql.DB) *PostgresUserStore {
+	return &PostgresUserStore{db: db}
+}
+
+// GetByID returns the user with the given ID
+func (s *PostgresUserStore) GetByID(userID string) (*User, error) {
+	query := `
+		SELECT id, name, email, password_hash, created_at, updated_at
+		FROM users
+		WHERE id = $1
+	`
+	return s.getUser(query, userID)
+}
+
+// GetByEmail returns the user with the given email
+func (s *PostgresUserStore) GetByEmail(email string) (*User, error) {
+	query := `
+		SELECT id, name, email, password_hash, created_at, updated_at
+		FROM users
+		WHERE email = $1
+	`
+	return s.getUser(query, email)
+}
+
+func (s *PostgresUserStore) getUser(query string, arg interface{}) (*User, error) {
+	user := &User{}
+	err := s.db.QueryRow(query, arg).Scan(
+		&user.ID, &user.Name, &user.Email, &user.PasswordHash,
+		&user.CreatedAt, &user.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// Create stores a new user
+func (s *PostgresUserStore) Create(user *User) error {
+	query := `
+		INSERT INTO users (id, name, email, password_hash, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := s.db.Exec(query, user.ID, user.Name, user.Email, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrEmailTaken
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package campaign
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// Handler handles campaign HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new campaign handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListCampaigns returns the campaigns the authenticated user belongs to
+func (h *Handler) ListCampaigns(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	campaigns, err := h.service.ListCampaigns(userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, campaigns)
+}
+
+// CreateCampaign creates a campaign run by the authenticated user
+func (h *Handler) CreateCampaign(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req CreateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	campaign, err := h.service.CreateCampaign(userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, campaign)
+}
+
+// GetCampaign returns a campaign with its members and characters
+func (h *Handler) GetCampaign(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	detail, err := h.service.GetCampaign(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// UpdateCampaign updates a campaign's name, description or settings
+func (h *Handler) UpdateCampaign(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req UpdateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	campaign, err := h.service.UpdateCampaign(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+// DeleteCampaign deletes a campaign
+func (h *Handler) DeleteCampaign(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	if err := h.service.DeleteCampaign(c.Param("id"), userID.(string)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign deleted successfully"})
+}
+
+// RegenerateInviteCode issues a new invite code for a campaign
+func (h *Handler) RegenerateInviteCode(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	campaign, err := h.service.RegenerateInviteCode(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+// JoinCampaign joins a campaign using an invite code
+func (h *Handler) JoinCampaign(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req JoinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	campaign, err := h.service.JoinCampaign(userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
+
+// UpdateMember changes a member's role
+func (h *Handler) UpdateMember(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	member, err := h.service.UpdateMember(c.Param("id"), userID.(string), c.Param("userId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember removes a member from a campaign, or leaves it when the
+// member is the authenticated user
+func (h *Handler) RemoveMember(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	if err := h.service.RemoveMember(c.Param("id"), userID.(string), c.Param("userId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// ListCharacters returns the full sheets of attached characters the user
+// may read
+func (h *Handler) ListCharacters(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	characters, err := h.service.ListCharacters(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, characters)
+}
+
+// AttachCharacter adds one of the user's characters to a campaign
+func (h *Handler) AttachCharacter(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req AttachCharacterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	attachment, err := h.service.AttachCharacter(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DetachCharacter removes a character from a campaign
+func (h *Handler) DetachCharacter(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	err := h.service.DetachCharacter(c.Param("id"), userID.(string), c.Param("characterId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Character removed from campaign"})
+}
@@ -0,0 +1,87 @@
+package campaign
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is a member's part in a campaign
+type Role string
+
+// Membership roles. The campaign owner is always a DM; other DMs act as
+// co-DMs with the same access to member characters.
+const (
+	RoleDM     Role = "dm"
+	RolePlayer Role = "player"
+)
+
+// Campaign is a group of players and their characters run by a DM
+type Campaign struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	OwnerID     uuid.UUID `json:"owner_id" db:"owner_id"`
+	Name        string    `json:"name" db:"name"`
+	Description *string   `json:"description" db:"description"`
+	// InviteCode is only shown to DMs
+	InviteCode string `json:"invite_code,omitempty" db:"invite_code"`
+	// DMCanEdit lets DMs change hit points on attached characters
+	DMCanEdit bool `json:"dm_can_edit" db:"dm_can_edit"`
+	// Role is the requesting user's role in the campaign
+	Role      Role      `json:"role,omitempty" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Member is a user's membership in a campaign
+type Member struct {
+	CampaignID uuid.UUID `json:"campaign_id" db:"campaign_id"`
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Role       Role      `json:"role" db:"role"`
+	JoinedAt   time.Time `json:"joined_at" db:"joined_at"`
+}
+
+// Attachment places a member's character in a campaign. A character belongs
+// to at most one campaign at a time.
+type Attachment struct {
+	CampaignID  uuid.UUID `json:"campaign_id" db:"campaign_id"`
+	CharacterID uuid.UUID `json:"character_id" db:"character_id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	AttachedAt  time.Time `json:"attached_at" db:"attached_at"`
+}
+
+// Detail is a campaign together with its members and attached characters
+type Detail struct {
+	*Campaign
+	Members    []*Member     `json:"members"`
+	Characters []*Attachment `json:"characters"`
+}
+
+// CreateCampaignRequest represents the request to create a campaign
+type CreateCampaignRequest struct {
+	Name        string  `json:"name" binding:"required,max=255"`
+	Description *string `json:"description"`
+	DMCanEdit   bool    `json:"dm_can_edit"`
+}
+
+// UpdateCampaignRequest represents the request to update a campaign
+type UpdateCampaignRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description"`
+	DMCanEdit   *bool   `json:"dm_can_edit"`
+}
+
+// JoinRequest represents the request to join a campaign by invite code
+type JoinRequest struct {
+	InviteCode string `json:"invite_code" binding:"required"`
+}
+
+// UpdateMemberRequest represents the request to change a member's role
+type UpdateMemberRequest struct {
+	Role Role `json:"role" binding:"required,oneof=dm player"`
+}
+
+// AttachCharacterRequest represents the request to add a character to a
+// campaign
+type AttachCharacterRequest struct {
+	CharacterID string `json:"character_id" binding:"required,uuid"`
+}
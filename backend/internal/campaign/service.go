@@ -0,0 +1,422 @@
+package campaign
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/character"
+)
+
+var (
+	// ErrDMOnly is returned when a player attempts something only DMs may do
+	ErrDMOnly = apperror.Forbidden("dm_only", "Only the campaign's DMs can do that")
+	// ErrOwnerOnly is returned when a non-owner attempts an owner action
+	ErrOwnerOnly = apperror.Forbidden("owner_only", "Only the campaign owner can do that")
+	// ErrOwnerMembership is returned when removing or demoting the owner
+	ErrOwnerMembership = apperror.Forbidden("owner_membership", "The campaign owner can't leave or stop being a DM")
+)
+
+// inviteAlphabet leaves out characters that are easily confused when read
+// aloud or copied by hand
+const inviteAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// inviteCodeLength is the number of characters in an invite code
+const inviteCodeLength = 8
+
+// Service handles campaign operations and decides which characters a DM
+// may see
+type Service struct {
+	store      Store
+	characters character.CharacterStore
+}
+
+// NewService creates a new campaign service
+func NewService(store Store, characters character.CharacterStore) *Service {
+	return &Service{store: store, characters: characters}
+}
+
+// ListCampaigns returns the campaigns a user belongs to
+func (s *Service) ListCampaigns(userID string) ([]*Campaign, error) {
+	campaigns, err := s.store.ListForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, campaign := range campaigns {
+		if campaign.Role != RoleDM {
+			campaign.InviteCode = ""
+		}
+	}
+
+	return campaigns, nil
+}
+
+// CreateCampaign creates a campaign with the user as its owner and DM
+func (s *Service) CreateCampaign(userID string, req *CreateCampaignRequest) (*Campaign, error) {
+	code, err := newInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	campaign := &Campaign{
+		ID:          uuid.New(),
+		OwnerID:     uuid.MustParse(userID),
+		Name:        req.Name,
+		Description: req.Description,
+		InviteCode:  code,
+		DMCanEdit:   req.DMCanEdit,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.store.Create(campaign); err != nil {
+		return nil, err
+	}
+
+	campaign.Role = RoleDM
+	return campaign, nil
+}
+
+// GetCampaign returns a campaign with its members and attached characters
+func (s *Service) GetCampaign(campaignID, userID string) (*Detail, error) {
+	campaign, _, err := s.membership(campaignID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.store.ListMembers(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := s.store.ListAttachments(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Detail{Campaign: campaign, Members: members, Characters: attachments}, nil
+}
+
+// UpdateCampaign changes a campaign's name, description or settings
+func (s *Service) UpdateCampaign(campaignID, userID string, req *UpdateCampaignRequest) (*Campaign, error) {
+	campaign, err := s.requireDM(campaignID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		campaign.Name = *req.Name
+	}
+	if req.Description != nil {
+		campaign.Description = req.Description
+	}
+	if req.DMCanEdit != nil {
+		campaign.DMCanEdit = *req.DMCanEdit
+	}
+	campaign.UpdatedAt = time.Now()
+
+	if err := s.store.Update(campaign); err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+// DeleteCampaign deletes a campaign; only its owner may do so
+func (s *Service) DeleteCampaign(campaignID, userID string) error {
+	campaign, _, err := s.membership(campaignID, userID)
+	if err != nil {
+		return err
+	}
+	if campaign.OwnerID.String() != userID {
+		return ErrOwnerOnly
+	}
+
+	return s.store.Delete(campaignID)
+}
+
+// RegenerateInviteCode replaces a campaign's invite code, invalidating the
+// old one
+func (s *Service) RegenerateInviteCode(campaignID, userID string) (*Campaign, error) {
+	campaign, err := s.requireDM(campaignID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if campaign.InviteCode, err = newInviteCode(); err != nil {
+		return nil, err
+	}
+	campaign.UpdatedAt = time.Now()
+
+	if err := s.store.Update(campaign); err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+// JoinCampaign adds the user to the campaign with the given invite code as
+// a player
+func (s *Service) JoinCampaign(userID string, req *JoinRequest) (*Campaign, error) {
+	code := strings.ToUpper(strings.TrimSpace(req.InviteCode))
+	campaign, err := s.store.GetByInviteCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.AddMember(&Member{
+		CampaignID: campaign.ID,
+		UserID:     uuid.MustParse(userID),
+		Role:       RolePlayer,
+		JoinedAt:   time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	campaign.Role = RolePlayer
+	campaign.InviteCode = ""
+	return campaign, nil
+}
+
+// UpdateMember changes a member's role; only the owner may promote or
+// demote DMs
+func (s *Service) UpdateMember(campaignID, userID, memberID string, req *UpdateMemberRequest) (*Member, error) {
+	campaign, _, err := s.membership(campaignID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.OwnerID.String() != userID {
+		return nil, ErrOwnerOnly
+	}
+	if memberID == userID {
+		return nil, ErrOwnerMembership
+	}
+	if !isValidID(memberID) {
+		return nil, ErrMemberNotFound
+	}
+
+	if err := s.store.UpdateMemberRole(campaignID, memberID, req.Role); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetMember(campaignID, memberID)
+}
+
+// RemoveMember removes a member and detaches their characters. Members may
+// leave on their own, DMs may remove players and the owner may remove
+// anyone but themselves.
+func (s *Service) RemoveMember(campaignID, userID, memberID string) error {
+	campaign, actor, err := s.membership(campaignID, userID)
+	if err != nil {
+		return err
+	}
+	if memberID == campaign.OwnerID.String() {
+		return ErrOwnerMembership
+	}
+	if !isValidID(memberID) {
+		return ErrMemberNotFound
+	}
+
+	if memberID != userID && campaign.OwnerID.String() != userID {
+		if actor.Role != RoleDM {
+			return ErrDMOnly
+		}
+		target, err := s.store.GetMember(campaignID, memberID)
+		if err != nil {
+			return err
+		}
+		if target.Role == RoleDM {
+			return ErrOwnerOnly
+		}
+	}
+
+	return s.store.RemoveMember(campaignID, memberID)
+}
+
+// ListCharacters returns the attached characters the user may read: every
+// one for DMs, only their own for players
+func (s *Service) ListCharacters(campaignID, userID string) ([]*character.Character, error) {
+	_, member, err := s.membership(campaignID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.store.ListAttachments(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	characters := []*character.Character{}
+	for _, a := range attachments {
+		if member.Role != RoleDM && a.UserID.String() != userID {
+			continue
+		}
+
+		char, err := s.characters.Get(a.CharacterID.String())
+		if errors.Is(err, character.ErrCharacterNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		char.Derived = character.ComputeDerived(char)
+		characters = append(characters, char)
+	}
+
+	return characters, nil
+}
+
+// AttachCharacter adds one of the user's characters to a campaign they
+// belong to
+func (s *Service) AttachCharacter(campaignID, userID string, req *AttachCharacterRequest) (*Attachment, error) {
+	if _, _, err := s.membership(campaignID, userID); err != nil {
+		return nil, err
+	}
+
+	char, err := s.characters.Get(req.CharacterID)
+	if err != nil {
+		return nil, err
+	}
+	if char.UserID.String() != userID {
+		return nil, character.ErrCharacterNotFound
+	}
+
+	attachment := &Attachment{
+		CampaignID:  uuid.MustParse(campaignID),
+		CharacterID: char.ID,
+		UserID:      char.UserID,
+		AttachedAt:  time.Now(),
+	}
+	if err := s.store.Attach(attachment); err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// DetachCharacter removes a character from a campaign. The character's
+// owner and the campaign's DMs may do this.
+func (s *Service) DetachCharacter(campaignID, userID, characterID string) error {
+	_, member, err := s.membership(campaignID, userID)
+	if err != nil {
+		return err
+	}
+	if !isValidID(characterID) {
+		return ErrNotAttached
+	}
+
+	attachment, err := s.store.GetAttachment(characterID)
+	if err != nil {
+		return err
+	}
+	if attachment.CampaignID.String() != campaignID {
+		return ErrNotAttached
+	}
+	if attachment.UserID.String() != userID && member.Role != RoleDM {
+		return ErrDMOnly
+	}
+
+	return s.store.Detach(campaignID, characterID)
+}
+
+// CharacterAccess grants a campaign's DMs read access to attached
+// characters, plus combat access when the campaign allows DM edits. It
+// implements character.AccessPolicy.
+func (s *Service) CharacterAccess(characterID, userID string) (character.Access, error) {
+	attachment, err := s.store.GetAttachment(characterID)
+	if errors.Is(err, ErrNotAttached) {
+		return character.AccessNone, nil
+	}
+	if err != nil {
+		return character.AccessNone, err
+	}
+
+	campaignID := attachment.CampaignID.String()
+	member, err := s.store.GetMember(campaignID, userID)
+	if errors.Is(err, ErrMemberNotFound) {
+		return character.AccessNone, nil
+	}
+	if err != nil {
+		return character.AccessNone, err
+	}
+	if member.Role != RoleDM {
+		return character.AccessNone, nil
+	}
+
+	campaign, err := s.store.Get(campaignID)
+	if err != nil {
+		return character.AccessNone, err
+	}
+	if campaign.DMCanEdit {
+		return character.AccessCombat, nil
+	}
+	return character.AccessRead, nil
+}
+
+// membership loads a campaign and the user's membership in it. Campaigns the
+// user doesn't belong to are reported as missing.
+func (s *Service) membership(campaignID, userID string) (*Campaign, *Member, error) {
+	if !isValidID(campaignID) {
+		return nil, nil, ErrCampaignNotFound
+	}
+
+	member, err := s.store.GetMember(campaignID, userID)
+	if errors.Is(err, ErrMemberNotFound) {
+		return nil, nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	campaign, err := s.store.Get(campaignID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	campaign.Role = member.Role
+	if member.Role != RoleDM {
+		campaign.InviteCode = ""
+	}
+	return campaign, member, nil
+}
+
+// requireDM loads a campaign the user is a DM of
+func (s *Service) requireDM(campaignID, userID string) (*Campaign, error) {
+	campaign, member, err := s.membership(campaignID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role != RoleDM {
+		return nil, ErrDMOnly
+	}
+	return campaign, nil
+}
+
+// newInviteCode generates a random invite code
+func newInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+
+	code := make([]byte, inviteCodeLength)
+	for i, b := range buf {
+		code[i] = inviteAlphabet[int(b)%len(inviteAlphabet)]
+	}
+	return string(code), nil
+}
+
+// isValidID reports whether id is a well-formed UUID, so malformed IDs are
+// treated as missing rather than reaching the database
+func isValidID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
@@ -0,0 +1,58 @@
+package campaign
+
+import "character-sheet-backend/internal/apperror"
+
+var (
+	// ErrCampaignNotFound is returned when a campaign doesn't exist or the
+	// user isn't a member of it
+	ErrCampaignNotFound = apperror.NotFound("campaign_not_found", "Campaign not found")
+	// ErrInvalidInviteCode is returned when no campaign has the given code
+	ErrInvalidInviteCode = apperror.NotFound("invalid_invite_code", "Invite code is not valid")
+	// ErrMemberNotFound is returned when a user isn't a member of a campaign
+	ErrMemberNotFound = apperror.NotFound("member_not_found", "Member not found")
+	// ErrAlreadyMember is returned when joining a campaign twice
+	ErrAlreadyMember = apperror.Conflict("already_member", "Already a member of this campaign")
+	// ErrNotAttached is returned when a character isn't in the campaign
+	ErrNotAttached = apperror.NotFound("character_not_attached", "Character is not part of this campaign")
+	// ErrAlreadyAttached is returned when a character is already part of a
+	// campaign
+	ErrAlreadyAttached = apperror.Conflict("character_already_attached", "Character is already part of a campaign")
+)
+
+// Store persists campaigns, their members and attached characters
+type Store interface {
+	// ListForUser returns the campaigns a user belongs to, newest first,
+	// with Role set to the user's role
+	ListForUser(userID string) ([]*Campaign, error)
+	// Get returns a campaign by ID
+	Get(campaignID string) (*Campaign, error)
+	// GetByInviteCode returns the campaign with the given invite code
+	GetByInviteCode(code string) (*Campaign, error)
+	// Create stores a new campaign and makes its owner a DM member
+	Create(campaign *Campaign) error
+	// Update saves a campaign's name, description, settings and invite code
+	Update(campaign *Campaign) error
+	// Delete removes a campaign with its memberships and attachments
+	Delete(campaignID string) error
+
+	// ListMembers returns a campaign's members in the order they joined
+	ListMembers(campaignID string) ([]*Member, error)
+	// GetMember returns a user's membership in a campaign
+	GetMember(campaignID, userID string) (*Member, error)
+	// AddMember stores a new membership, failing with ErrAlreadyMember
+	AddMember(member *Member) error
+	// UpdateMemberRole changes a member's role
+	UpdateMemberRole(campaignID, userID string, role Role) error
+	// RemoveMember deletes a membership and detaches the member's characters
+	RemoveMember(campaignID, userID string) error
+
+	// ListAttachments returns the characters attached to a campaign
+	ListAttachments(campaignID string) ([]*Attachment, error)
+	// GetAttachment returns the campaign a character is attached to
+	GetAttachment(characterID string) (*Attachment, error)
+	// Attach adds a character to a campaign, failing with ErrAlreadyAttached
+	// if it already belongs to one
+	Attach(attachment *Attachment) error
+	// Detach removes a character from a campaign
+	Detach(campaignID, characterID string) error
+}
@@ -0,0 +1,278 @@
+package campaign
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a campaign Store kept in process memory, for tests and
+// demos that run without a database
+type MemoryStore struct {
+	mu          sync.RWMutex
+	campaigns   map[string]*Campaign
+	members     map[string]map[string]*Member
+	attachments map[string]*Attachment
+}
+
+// NewMemoryStore creates an empty in-memory campaign store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		campaigns:   make(map[string]*Campaign),
+		members:     make(map[string]map[string]*Member),
+		attachments: make(map[string]*Attachment),
+	}
+}
+
+// ListForUser returns the campaigns a user belongs to, newest first
+func (s *MemoryStore) ListForUser(userID string) ([]*Campaign, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	campaigns := []*Campaign{}
+	for id, members := range s.members {
+		if member, ok := members[userID]; ok {
+			cp := *s.campaigns[id]
+			cp.Role = member.Role
+			campaigns = append(campaigns, &cp)
+		}
+	}
+
+	sort.Slice(campaigns, func(i, j int) bool {
+		return campaigns[i].CreatedAt.After(campaigns[j].CreatedAt)
+	})
+
+	return campaigns, nil
+}
+
+// Get returns a campaign by ID
+func (s *MemoryStore) Get(campaignID string) (*Campaign, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	campaign, ok := s.campaigns[campaignID]
+	if !ok {
+		return nil, ErrCampaignNotFound
+	}
+
+	cp := *campaign
+	return &cp, nil
+}
+
+// GetByInviteCode returns the campaign with the given invite code
+func (s *MemoryStore) GetByInviteCode(code string) (*Campaign, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, campaign := range s.campaigns {
+		if campaign.InviteCode == code {
+			cp := *campaign
+			return &cp, nil
+		}
+	}
+
+	return nil, ErrInvalidInviteCode
+}
+
+// Create stores a new campaign and makes its owner a DM member
+func (s *MemoryStore) Create(campaign *Campaign) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := campaign.ID.String()
+	cp := *campaign
+	cp.Role = ""
+	s.campaigns[id] = &cp
+	s.members[id] = map[string]*Member{
+		campaign.OwnerID.String(): {
+			CampaignID: campaign.ID,
+			UserID:     campaign.OwnerID,
+			Role:       RoleDM,
+			JoinedAt:   campaign.CreatedAt,
+		},
+	}
+	return nil
+}
+
+// Update saves a campaign's name, description, settings and invite code
+func (s *MemoryStore) Update(campaign *Campaign) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := campaign.ID.String()
+	if _, ok := s.campaigns[id]; !ok {
+		return ErrCampaignNotFound
+	}
+
+	cp := *campaign
+	cp.Role = ""
+	s.campaigns[id] = &cp
+	return nil
+}
+
+// Delete removes a campaign with its memberships and attachments
+func (s *MemoryStore) Delete(campaignID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.campaigns[campaignID]; !ok {
+		return ErrCampaignNotFound
+	}
+
+	delete(s.campaigns, campaignID)
+	delete(s.members, campaignID)
+	for characterID, attachment := range s.attachments {
+		if attachment.CampaignID.String() == campaignID {
+			delete(s.attachments, characterID)
+		}
+	}
+	return nil
+}
+
+// ListMembers returns a campaign's members in the order they joined
+func (s *MemoryStore) ListMembers(campaignID string) ([]*Member, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	members := []*Member{}
+	for _, member := range s.members[campaignID] {
+		cp := *member
+		members = append(members, &cp)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].JoinedAt.Before(members[j].JoinedAt)
+	})
+
+	return members, nil
+}
+
+// GetMember returns a user's membership in a campaign
+func (s *MemoryStore) GetMember(campaignID, userID string) (*Member, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	member, ok := s.members[campaignID][userID]
+	if !ok {
+		return nil, ErrMemberNotFound
+	}
+
+	cp := *member
+	return &cp, nil
+}
+
+// AddMember stores a new membership
+func (s *MemoryStore) AddMember(member *Member) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members, ok := s.members[member.CampaignID.String()]
+	if !ok {
+		return ErrCampaignNotFound
+	}
+	if _, ok := members[member.UserID.String()]; ok {
+		return ErrAlreadyMember
+	}
+
+	cp := *member
+	members[member.UserID.String()] = &cp
+	return nil
+}
+
+// UpdateMemberRole changes a member's role
+func (s *MemoryStore) UpdateMemberRole(campaignID, userID string, role Role) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.members[campaignID][userID]
+	if !ok {
+		return ErrMemberNotFound
+	}
+
+	member.Role = role
+	return nil
+}
+
+// RemoveMember deletes a membership and detaches the member's characters
+func (s *MemoryStore) RemoveMember(campaignID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.members[campaignID][userID]; !ok {
+		return ErrMemberNotFound
+	}
+
+	delete(s.members[campaignID], userID)
+	for characterID, attachment := range s.attachments {
+		if attachment.CampaignID.String() == campaignID && attachment.UserID.String() == userID {
+			delete(s.attachments, characterID)
+		}
+	}
+	return nil
+}
+
+// ListAttachments returns the characters attached to a campaign
+func (s *MemoryStore) ListAttachments(campaignID string) ([]*Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attachments := []*Attachment{}
+	for _, attachment := range s.attachments {
+		if attachment.CampaignID.String() == campaignID {
+			cp := *attachment
+			attachments = append(attachments, &cp)
+		}
+	}
+
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].AttachedAt.Before(attachments[j].AttachedAt)
+	})
+
+	return attachments, nil
+}
+
+// GetAttachment returns the campaign a character is attached to
+func (s *MemoryStore) GetAttachment(characterID string) (*Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attachment, ok := s.attachments[characterID]
+	if !ok {
+		return nil, ErrNotAttached
+	}
+
+	cp := *attachment
+	return &cp, nil
+}
+
+// Attach adds a character to a campaign
+func (s *MemoryStore) Attach(attachment *Attachment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.members[attachment.CampaignID.String()][attachment.UserID.String()]; !ok {
+		return ErrMemberNotFound
+	}
+
+	id := attachment.CharacterID.String()
+	if _, ok := s.attachments[id]; ok {
+		return ErrAlreadyAttached
+	}
+
+	cp := *attachment
+	s.attachments[id] = &cp
+	return nil
+}
+
+// Detach removes a character from a campaign
+func (s *MemoryStore) Detach(campaignID, characterID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attachment, ok := s.attachments[characterID]
+	if !ok || attachment.CampaignID.String() != campaignID {
+		return ErrNotAttached
+	}
+
+	delete(s.attachments, characterID)
+	return nil
+}
@@ -0,0 +1,340 @@
+package campaign
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// campaignColumns is the column list shared by every campaign SELECT
+const campaignColumns = `
+	c.id, c.owner_id, c.name, c.description, c.invite_code, c.dm_can_edit,
+	c.created_at, c.updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// PostgresStore is a campaign Store backed by PostgreSQL
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a campaign store using the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func scanCampaign(row rowScanner, extra ...interface{}) (*Campaign, error) {
+	campaign := &Campaign{}
+	dest := []interface{}{
+		&campaign.ID, &campaign.OwnerID, &campaign.Name, &campaign.Description,
+		&campaign.InviteCode, &campaign.DMCanEdit, &campaign.CreatedAt, &campaign.UpdatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return campaign, err
+}
+
+// ListForUser returns the campaigns a user belongs to, newest first
+func (s *PostgresStore) ListForUser(userID string) ([]*Campaign, error) {
+	query := `SELECT ` + campaignColumns + `, m.role
+		FROM campaigns c
+		JOIN campaign_members m ON m.campaign_id = c.id
+		WHERE m.user_id = $1
+		ORDER BY c.created_at DESC
+	`
+
+	rows, err := s.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query campaigns: %w", err)
+	}
+	defer rows.Close()
+
+	campaigns := []*Campaign{}
+	for rows.Next() {
+		var role Role
+		campaign, err := scanCampaign(rows, &role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan campaign: %w", err)
+		}
+		campaign.Role = role
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, rows.Err()
+}
+
+// Get returns a campaign by ID
+func (s *PostgresStore) Get(campaignID string) (*Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns c WHERE c.id = $1`
+	return s.getCampaign(query, campaignID, ErrCampaignNotFound)
+}
+
+// GetByInviteCode returns the campaign with the given invite code
+func (s *PostgresStore) GetByInviteCode(code string) (*Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns c WHERE c.invite_code = $1`
+	return s.getCampaign(query, code, ErrInvalidInviteCode)
+}
+
+func (s *PostgresStore) getCampaign(query string, arg interface{}, notFound error) (*Campaign, error) {
+	campaign, err := scanCampaign(s.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get campaign: %w", err)
+	}
+
+	return campaign, nil
+}
+
+// Create stores a new campaign and makes its owner a DM member
+func (s *PostgresStore) Create(campaign *Campaign) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO campaigns (id, owner_id, name, description, invite_code, dm_can_edit, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		campaign.ID, campaign.OwnerID, campaign.Name, campaign.Description,
+		campaign.InviteCode, campaign.DMCanEdit, campaign.CreatedAt, campaign.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create campaign: %w", err)
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO campaign_members (campaign_id, user_id, role, joined_at) VALUES ($1, $2, $3, $4)",
+		campaign.ID, campaign.OwnerID, RoleDM, campaign.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add campaign owner: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit campaign: %w", err)
+	}
+
+	return nil
+}
+
+// Update saves a campaign's name, description, settings and invite code
+func (s *PostgresStore) Update(campaign *Campaign) error {
+	result, err := s.db.Exec(`
+		UPDATE campaigns SET
+			name = $2, description = $3, invite_code = $4, dm_can_edit = $5, updated_at = $6
+		WHERE id = $1`,
+		campaign.ID, campaign.Name, campaign.Description, campaign.InviteCode,
+		campaign.DMCanEdit, campaign.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update campaign: %w", err)
+	}
+
+	return expectRow(result, ErrCampaignNotFound)
+}
+
+// Delete removes a campaign with its memberships and attachments
+func (s *PostgresStore) Delete(campaignID string) error {
+	result, err := s.db.Exec("DELETE FROM campaigns WHERE id = $1", campaignID)
+	if err != nil {
+		return fmt.Errorf("failed to delete campaign: %w", err)
+	}
+
+	return expectRow(result, ErrCampaignNotFound)
+}
+
+// ListMembers returns a campaign's members in the order they joined
+func (s *PostgresStore) ListMembers(campaignID string) ([]*Member, error) {
+	rows, err := s.db.Query(`
+		SELECT campaign_id, user_id, role, joined_at
+		FROM campaign_members
+		WHERE campaign_id = $1
+		ORDER BY joined_at`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query campaign members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []*Member{}
+	for rows.Next() {
+		member := &Member{}
+		if err := rows.Scan(&member.CampaignID, &member.UserID, &member.Role, &member.JoinedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan campaign member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// GetMember returns a user's membership in a campaign
+func (s *PostgresStore) GetMember(campaignID, userID string) (*Member, error) {
+	member := &Member{}
+	err := s.db.QueryRow(`
+		SELECT campaign_id, user_id, role, joined_at
+		FROM campaign_members
+		WHERE campaign_id = $1 AND user_id = $2`,
+		campaignID, userID,
+	).Scan(&member.CampaignID, &member.UserID, &member.Role, &member.JoinedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get campaign member: %w", err)
+	}
+
+	return member, nil
+}
+
+// AddMember stores a new membership
+func (s *PostgresStore) AddMember(member *Member) error {
+	_, err := s.db.Exec(
+		"INSERT INTO campaign_members (campaign_id, user_id, role, joined_at) VALUES ($1, $2, $3, $4)",
+		member.CampaignID, member.UserID, member.Role, member.JoinedAt,
+	)
+	if err != nil {
+		switch pqCode(err) {
+		case "23505":
+			return ErrAlreadyMember
+		case "23503":
+			return ErrCampaignNotFound
+		}
+		return fmt.Errorf("failed to add campaign member: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateMemberRole changes a member's role
+func (s *PostgresStore) UpdateMemberRole(campaignID, userID string, role Role) error {
+	result, err := s.db.Exec(
+		"UPDATE campaign_members SET role = $3 WHERE campaign_id = $1 AND user_id = $2",
+		campaignID, userID, role,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update campaign member: %w", err)
+	}
+
+	return expectRow(result, ErrMemberNotFound)
+}
+
+// RemoveMember deletes a membership; the member's attachments go with it
+// through the campaign_characters foreign key
+func (s *PostgresStore) RemoveMember(campaignID, userID string) error {
+	result, err := s.db.Exec(
+		"DELETE FROM campaign_members WHERE campaign_id = $1 AND user_id = $2",
+		campaignID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove campaign member: %w", err)
+	}
+
+	return expectRow(result, ErrMemberNotFound)
+}
+
+// ListAttachments returns the characters attached to a campaign
+func (s *PostgresStore) ListAttachments(campaignID string) ([]*Attachment, error) {
+	rows, err := s.db.Query(`
+		SELECT campaign_id, character_id, user_id, attached_at
+		FROM campaign_characters
+		WHERE campaign_id = $1
+		ORDER BY attached_at`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query campaign characters: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []*Attachment{}
+	for rows.Next() {
+		a := &Attachment{}
+		if err := rows.Scan(&a.CampaignID, &a.CharacterID, &a.UserID, &a.AttachedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan campaign character: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// GetAttachment returns the campaign a character is attached to
+func (s *PostgresStore) GetAttachment(characterID string) (*Attachment, error) {
+	a := &Attachment{}
+	err := s.db.QueryRow(`
+		SELECT campaign_id, character_id, user_id, attached_at
+		FROM campaign_characters
+		WHERE character_id = $1`,
+		characterID,
+	).Scan(&a.CampaignID, &a.CharacterID, &a.UserID, &a.AttachedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotAttached
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get campaign character: %w", err)
+	}
+
+	return a, nil
+}
+
+// Attach adds a character to a campaign
+func (s *PostgresStore) Attach(a *Attachment) error {
+	_, err := s.db.Exec(
+		"INSERT INTO campaign_characters (campaign_id, character_id, user_id, attached_at) VALUES ($1, $2, $3, $4)",
+		a.CampaignID, a.CharacterID, a.UserID, a.AttachedAt,
+	)
+	if err != nil {
+		switch pqCode(err) {
+		case "23505":
+			return ErrAlreadyAttached
+		case "23503":
+			return ErrMemberNotFound
+		}
+		return fmt.Errorf("failed to attach character: %w", err)
+	}
+
+	return nil
+}
+
+// Detach removes a character from a campaign
+func (s *PostgresStore) Detach(campaignID, characterID string) error {
+	result, err := s.db.Exec(
+		"DELETE FROM campaign_characters WHERE campaign_id = $1 AND character_id = $2",
+		campaignID, characterID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to detach character: %w", err)
+	}
+
+	return expectRow(result, ErrNotAttached)
+}
+
+// expectRow turns a write that matched no rows into notFound
+func expectRow(result sql.Result, notFound error) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// pqCode returns the SQLSTATE of a PostgreSQL error, or "" for other errors
+func pqCode(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package character
+
+import "character-sheet-backend/internal/apperror"
+
+// Access is how much a user may do with a character
+type Access int
+
+const (
+	// AccessNone hides the character entirely
+	AccessNone Access = iota
+	// AccessRead allows viewing the sheet and its history
+	AccessRead
+	// AccessCombat also allows changing hit points during play
+	AccessCombat
+	// AccessOwner allows every operation
+	AccessOwner
+)
+
+var (
+	// ErrCharacterForbidden is returned when a user can see a character but
+	// not perform the requested operation on it
+	ErrCharacterForbidden = apperror.Forbidden("character_forbidden", "Only the character's owner can do that")
+	// ErrCombatFieldsOnly is returned when a non-owner with combat access
+	// tries to change anything other than hit points
+	ErrCombatFieldsOnly = apperror.Forbidden("combat_fields_only", "Only current_hp and temp_hp can be changed on another player's character")
+)
+
+// AccessPolicy grants users access to characters they don't own, such as a
+// dungeon master reading the sheets of their players
+type AccessPolicy interface {
+	CharacterAccess(characterID, userID string) (Access, error)
+}
+
+// authorize loads a character and checks that the user has at least the
+// wanted access to it. Characters the user can't see at all are reported as
+// missing so their existence isn't revealed.
+func (s *Service) authorize(characterID, userID string, want Access) (*Character, Access, error) {
+	if !isValidID(characterID) {
+		return nil, AccessNone, ErrCharacterNotFound
+	}
+
+	char, err := s.store.Get(characterID)
+	if err != nil {
+		return nil, AccessNone, err
+	}
+
+	access := AccessOwner
+	if char.UserID.String() != userID {
+		access = AccessNone
+		if s.policy != nil {
+			if access, err = s.policy.CharacterAccess(characterID, userID); err != nil {
+				return nil, AccessNone, err
+			}
+		}
+	}
+
+	if access == AccessNone {
+		return nil, AccessNone, ErrCharacterNotFound
+	}
+	if access < want {
+		return nil, access, ErrCharacterForbidden
+	}
+
+	return char, access, nil
+}
+
+// combatOnly reports whether an update touches nothing but the fields a
+// user with AccessCombat may change
+func (r *UpdateCharacterRequest) combatOnly() bool {
+	return r.Name == nil && r.Race == nil && r.Classes == nil && r.Class == nil && r.Subclass == nil && r.Level == nil &&
+		r.Background == nil && r.Strength == nil && r.Dexterity == nil &&
+		r.Constitution == nil && r.Intelligence == nil && r.Wisdom == nil &&
+		r.Charisma == nil && r.MaxHP == nil && r.Speed == nil && r.ArmorClass == nil &&
+		r.Armor == nil && r.Shield == nil && r.ACBonuses == nil && r.Defenses == nil && len(r.Clear) == 0 &&
+		r.Notes == nil && r.Proficiencies == nil && r.JackOfAllTrades == nil
+}
+
+// Authorize loads a character with its derived stats, checking that the user
+// has at least the wanted access. Packages storing data that belongs to a
+// character use it to apply the same access rules.
+func (s *Service) Authorize(characterID, userID string, want Access) (*Character, error) {
+	char, _, err := s.authorize(characterID, userID, want)
+	if err != nil {
+		return nil, err
+	}
+
+	char.Derived = ComputeDerived(char)
+	return char, nil
+}
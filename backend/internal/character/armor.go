@@ -0,0 +1,132 @@
+package character
+
+import (
+	"fmt"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// Armor types
+const (
+	ArmorLight  = "light"
+	ArmorMedium = "medium"
+	ArmorHeavy  = "heavy"
+)
+
+// MediumArmorDexCap is the most Dexterity adds to AC in medium armor
+const MediumArmorDexCap = 2
+
+// ShieldBonus is the AC a shield adds before any magic bonus
+const ShieldBonus = 2
+
+// Armor is the body armor a character wears
+type Armor struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Type string `json:"type" binding:"required,oneof=light medium heavy"`
+	// BaseAC is the armor's AC before Dexterity, e.g. 11 for leather
+	BaseAC     int `json:"base_ac" binding:"required,min=1,max=30"`
+	MagicBonus int `json:"magic_bonus" binding:"min=-5,max=5"`
+}
+
+// Shield is the shield a character carries
+type Shield struct {
+	Name       string `json:"name" binding:"required,max=100"`
+	MagicBonus int    `json:"magic_bonus" binding:"min=-5,max=5"`
+}
+
+// ACBonus is any other named bonus to AC, such as a Ring of Protection or
+// the Defense fighting style
+type ACBonus struct {
+	Source string `json:"source" binding:"required,max=100"`
+	Value  int    `json:"value" binding:"min=-10,max=10"`
+}
+
+// ArmorClass explains a character's AC
+type ArmorClass struct {
+	// Total is the AC in play: the override when set, otherwise Computed
+	Total      int  `json:"total"`
+	Computed   int  `json:"computed"`
+	Overridden bool `json:"overridden"`
+	// Breakdown adds up to Computed
+	Breakdown []ACPart `json:"breakdown"`
+}
+
+// ACPart is one contribution to a computed AC
+type ACPart struct {
+	Source string `json:"source"`
+	Value  int    `json:"value"`
+}
+
+// ComputeArmorClass works out a character's AC from their armor, shield,
+// unarmored defense and other bonuses. An explicit armor_class on the
+// character overrides the result.
+func ComputeArmorClass(c *Character, mods AbilityModifiers) *ArmorClass {
+	var parts []ACPart
+	add := func(source string, value int) {
+		parts = append(parts, ACPart{Source: source, Value: value})
+	}
+
+	switch {
+	case c.Armor != nil:
+		add(c.Armor.Name, c.Armor.BaseAC)
+		switch c.Armor.Type {
+		case ArmorLight:
+			add("Dexterity modifier", mods.Dexterity)
+		case ArmorMedium:
+			if mods.Dexterity > MediumArmorDexCap {
+				add(fmt.Sprintf("Dexterity modifier (capped at +%d by medium armor)", MediumArmorDexCap), MediumArmorDexCap)
+			} else {
+				add("Dexterity modifier", mods.Dexterity)
+			}
+		}
+		if c.Armor.MagicBonus != 0 {
+			add(c.Armor.Name+" magic bonus", c.Armor.MagicBonus)
+		}
+	case c.ClassLevel("Monk") > 0 && c.Shield == nil &&
+		(c.ClassLevel("Barbarian") == 0 || mods.Wisdom >= mods.Constitution):
+		add("Unarmored Defense (Monk)", 10)
+		add("Dexterity modifier", mods.Dexterity)
+		add("Wisdom modifier", mods.Wisdom)
+	case c.ClassLevel("Barbarian") > 0:
+		add("Unarmored Defense (Barbarian)", 10)
+		add("Dexterity modifier", mods.Dexterity)
+		add("Constitution modifier", mods.Constitution)
+	default:
+		add("Unarmored", 10)
+		add("Dexterity modifier", mods.Dexterity)
+	}
+
+	if c.Shield != nil {
+		add(c.Shield.Name, ShieldBonus)
+		if c.Shield.MagicBonus != 0 {
+			add(c.Shield.Name+" magic bonus", c.Shield.MagicBonus)
+		}
+	}
+
+	for _, bonus := range c.ACBonuses {
+		add(bonus.Source, bonus.Value)
+	}
+
+	ac := &ArmorClass{Breakdown: parts}
+	for _, part := range parts {
+		ac.Computed += part.Value
+	}
+	ac.Total = ac.Computed
+	if c.ArmorClass != nil {
+		ac.Total = *c.ArmorClass
+		ac.Overridden = true
+	}
+	return ac
+}
+
+// validateClear rejects an update that both sets and clears a field
+func (r *UpdateCharacterRequest) validateClear() error {
+	for _, field := range r.Clear {
+		if (field == "armor" && r.Armor != nil) ||
+			(field == "shield" && r.Shield != nil) ||
+			(field == "armor_class" && r.ArmorClass != nil) {
+			return apperror.Field("clear", fmt.Sprintf("can't set and clear %s in the same update", field))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package character
+
+import "testing"
+
+func TestComputeArmorClass(t *testing.T) {
+	override := 21
+	tests := []struct {
+		name   string
+		modify func(c *Character)
+		want   int
+	}{
+		{"unarmored", func(c *Character) {}, 14},
+		{"light armor", func(c *Character) {
+			c.Armor = &Armor{Name: "Studded leather", Type: ArmorLight, BaseAC: 12}
+		}, 16},
+		{"medium armor caps Dexterity", func(c *Character) {
+			c.Armor = &Armor{Name: "Half plate", Type: ArmorMedium, BaseAC: 15, MagicBonus: 1}
+		}, 18},
+		{"heavy armor ignores Dexterity", func(c *Character) {
+			c.Armor = &Armor{Name: "Plate", Type: ArmorHeavy, BaseAC: 18}
+			c.Shield = &Shield{Name: "Shield", MagicBonus: 1}
+		}, 21},
+		{"monk unarmored defense", func(c *Character) {
+			c.Classes = []ClassEntry{{Class: "Monk", Levels: 5, HitDie: 8}}
+		}, 15},
+		{"barbarian unarmored defense", func(c *Character) {
+			c.Classes = []ClassEntry{{Class: "Barbarian", Levels: 5, HitDie: 12}}
+			c.Shield = &Shield{Name: "Shield"}
+		}, 18},
+		{"monk with a shield", func(c *Character) {
+			c.Classes = []ClassEntry{{Class: "Monk", Levels: 5, HitDie: 8}}
+			c.Shield = &Shield{Name: "Shield"}
+		}, 16},
+		{"other bonuses", func(c *Character) {
+			c.ACBonuses = []ACBonus{{Source: "Ring of Protection", Value: 1}}
+		}, 15},
+		{"override", func(c *Character) { c.ArmorClass = &override }, 21},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCharacter()
+			tt.modify(c)
+			ac := ComputeDerived(c).ArmorClass
+			if ac.Total != tt.want {
+				t.Errorf("AC = %d, want %d (%+v)", ac.Total, tt.want, ac.Breakdown)
+			}
+			sum := 0
+			for _, part := range ac.Breakdown {
+				sum += part.Value
+			}
+			if sum != ac.Computed {
+				t.Errorf("breakdown adds up to %d, computed %d", sum, ac.Computed)
+			}
+			if ac.Overridden != (c.ArmorClass != nil) {
+				t.Errorf("overridden = %v", ac.Overridden)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/content"
+)
+
+// MaxLevel is the highest total character level
+const MaxLevel = 20
+
+// ClassEntry is a character's levels in one class
+type ClassEntry struct {
+	Class    string `json:"class" binding:"required,max=100"`
+	Subclass string `json:"subclass" binding:"max=100"`
+	Levels   int    `json:"levels" binding:"required,min=1,max=20"`
+	// HitDie is filled in from the class rules when left out. Classes
+	// that can't be looked up must give it.
+	HitDie int `json:"hit_die" binding:"omitempty,oneof=6 8 10 12"`
+	// HitDiceSpent counts the class's hit dice spent on short rests and
+	// not yet regained on a long rest
+	HitDiceSpent int `json:"hit_dice_spent" binding:"omitempty,min=0,max=20"`
+}
+
+// ClassRules looks up the rules for a class by index or name, including
+// homebrew classes from the packs a character uses. It returns nil for
+// classes it doesn't know.
+type ClassRules interface {
+	Class(characterID, class string) (*content.Class, error)
+}
+
+// TotalLevel returns the sum of a character's class levels
+func TotalLevel(classes []ClassEntry) int {
+	total := 0
+	for _, entry := range classes {
+		total += entry.Levels
+	}
+	return total
+}
+
+// ClassLevel returns the character's levels in the named class
+func (c *Character) ClassLevel(class string) int {
+	for _, entry := range c.Classes {
+		if strings.EqualFold(strings.TrimSpace(entry.Class), class) {
+			return entry.Levels
+		}
+	}
+	return 0
+}
+
+// Score returns the named ability score
+func (c *Character) Score(ability string) int {
+	switch ability {
+	case Strength:
+		return c.Strength
+	case Dexterity:
+		return c.Dexterity
+	case Constitution:
+		return c.Constitution
+	case Intelligence:
+		return c.Intelligence
+	case Wisdom:
+		return c.Wisdom
+	case Charisma:
+		return c.Charisma
+	}
+	return 0
+}
+
+// SetScore sets the named ability score
+func (c *Character) SetScore(ability string, score int) {
+	switch ability {
+	case Strength:
+		c.Strength = score
+	case Dexterity:
+		c.Dexterity = score
+	case Constitution:
+		c.Constitution = score
+	case Intelligence:
+		c.Intelligence = score
+	case Wisdom:
+		c.Wisdom = score
+	case Charisma:
+		c.Charisma = score
+	}
+}
+
+// CheckClasses validates a character's class entries against the class
+// rules, filling in missing hit dice and the total level and capping spent
+// hit dice at the class level. Multiclass
+// prerequisites are checked when checkPrerequisites is set, which callers
+// do when the classes change so that lowering an ability score later
+// doesn't lock the sheet.
+func (s *Service) CheckClasses(c *Character, checkPrerequisites bool) error {
+	fields := map[string]string{}
+	if len(c.Classes) == 0 {
+		fields["classes"] = "must list at least one class"
+	}
+
+	seen := map[string]bool{}
+	rules := make([]*content.Class, len(c.Classes))
+	for i := range c.Classes {
+		entry := &c.Classes[i]
+		entry.Class = strings.TrimSpace(entry.Class)
+		entry.Subclass = strings.TrimSpace(entry.Subclass)
+		path := fmt.Sprintf("classes[%d]", i)
+
+		key := strings.ToLower(entry.Class)
+		if entry.Class == "" {
+			fields[path+".class"] = "is required"
+			continue
+		}
+		if seen[key] {
+			fields[path+".class"] = "is listed more than once"
+			continue
+		}
+		seen[key] = true
+
+		if s.classes != nil {
+			class, err := s.classes.Class(c.ID.String(), entry.Class)
+			if err != nil {
+				return err
+			}
+			rules[i] = class
+		}
+		if entry.HitDie == 0 && rules[i] != nil {
+			entry.HitDie = rules[i].HitDie
+		}
+		if entry.HitDie == 0 {
+			fields[path+".hit_die"] = "is required for classes that aren't in the SRD or the character's homebrew packs"
+		}
+		entry.HitDiceSpent = min(max(entry.HitDiceSpent, 0), entry.Levels)
+	}
+
+	c.Level = TotalLevel(c.Classes)
+	if c.Level > MaxLevel {
+		fields["classes"] = fmt.Sprintf("levels must add up to at most %d", MaxLevel)
+	}
+	if len(fields) > 0 {
+		return apperror.Validation("invalid_classes", "The character's classes are invalid", fields)
+	}
+
+	if checkPrerequisites && len(c.Classes) > 1 {
+		for i, class := range rules {
+			if class == nil {
+				continue
+			}
+			if missing := unmetPrerequisites(c, class.Multiclass); missing != "" {
+				fields[fmt.Sprintf("classes[%d].class", i)] = fmt.Sprintf("%s requires %s to multiclass", class.Name, missing)
+			}
+		}
+		if len(fields) > 0 {
+			return apperror.Validation("multiclass_prerequisites", "The character doesn't meet the ability score prerequisites for multiclassing", fields)
+		}
+	}
+	return nil
+}
+
+// unmetPrerequisites describes the multiclass prerequisites a character
+// doesn't meet, or returns "" when it meets them
+func unmetPrerequisites(c *Character, multiclass content.Multiclass) string {
+	abilities := make([]string, 0, len(multiclass.Prerequisites))
+	for ability := range multiclass.Prerequisites {
+		abilities = append(abilities, ability)
+	}
+	sort.Strings(abilities)
+
+	var unmet []string
+	for _, ability := range abilities {
+		min := multiclass.Prerequisites[ability]
+		if c.Score(ability) >= min {
+			if multiclass.AnyPrerequisite {
+				return ""
+			}
+			continue
+		}
+		unmet = append(unmet, fmt.Sprintf("%s %d", ability, min))
+	}
+
+	if multiclass.AnyPrerequisite {
+		return strings.Join(unmet, " or ")
+	}
+	return strings.Join(unmet, " and ")
+}
+
+// UnmarshalJSON reads characters recorded before multiclassing, whose
+// revision snapshots have a single class, subclass and level, and before
+// speed, which they get the default of
+func (c *Character) UnmarshalJSON(data []byte) error {
+	type plain Character
+	legacy := struct {
+		*plain
+		Class    string `json:"class"`
+		Subclass string `json:"subclass"`
+		Speed    *int   `json:"speed"`
+	}{plain: (*plain)(c)}
+
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	if c.Classes == nil && legacy.Class != "" {
+		c.Classes = []ClassEntry{{Class: legacy.Class, Subclass: legacy.Subclass, Levels: c.Level}}
+	}
+	c.Speed = DefaultSpeed
+	if legacy.Speed != nil {
+		c.Speed = *legacy.Speed
+	}
+	return nil
+}
+
+// applyClasses applies an update's class changes to a character and reports
+// whether its classes changed. The single-class fields may only be used on
+// characters with one class.
+func (r *UpdateCharacterRequest) applyClasses(c *Character) (bool, error) {
+	if r.Classes != nil {
+		if r.Class != nil || r.Subclass != nil || r.Level != nil {
+			return false, apperror.Field("classes", "can't be changed together with class, subclass or level")
+		}
+		changed := !reflect.DeepEqual(c.Classes, *r.Classes)
+		c.Classes = append([]ClassEntry{}, *r.Classes...)
+		return changed, nil
+	}
+
+	if r.Class == nil && r.Subclass == nil && r.Level == nil {
+		return false, nil
+	}
+	if len(c.Classes) != 1 {
+		return false, apperror.Field("classes", "must be used to change the classes of a multiclass character")
+	}
+
+	entry := &c.Classes[0]
+	if r.Class != nil && !strings.EqualFold(strings.TrimSpace(*r.Class), entry.Class) {
+		entry.Class = *r.Class
+		// The new class's hit die is looked up again
+		entry.HitDie = 0
+	}
+	if r.Subclass != nil {
+		entry.Subclass = *r.Subclass
+	}
+	if r.Level != nil {
+		entry.Levels = *r.Level
+	}
+	return true, nil
+}
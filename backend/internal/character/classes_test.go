@@ -0,0 +1,137 @@
+package character
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/content"
+	"character-sheet-backend/internal/dice"
+)
+
+// srdClasses has the hit dice and multiclass prerequisites of a few SRD
+// classes
+var srdClasses = classRules{
+	"fighter": {Index: "fighter", Name: "Fighter", HitDie: 10, Multiclass: content.Multiclass{
+		Prerequisites: map[string]int{Strength: 13, Dexterity: 13}, AnyPrerequisite: true,
+	}},
+	"paladin": {Index: "paladin", Name: "Paladin", HitDie: 10, Multiclass: content.Multiclass{
+		Prerequisites: map[string]int{Strength: 13, Charisma: 13},
+	}},
+	"wizard": {Index: "wizard", Name: "Wizard", HitDie: 6, Multiclass: content.Multiclass{
+		Prerequisites: map[string]int{Intelligence: 13},
+	}},
+}
+
+func TestUnmetPrerequisites(t *testing.T) {
+	tests := []struct {
+		class string
+		str   int
+		dex   int
+		cha   int
+		want  string
+	}{
+		{"fighter", 13, 8, 8, ""},
+		{"fighter", 8, 13, 8, ""},
+		{"fighter", 12, 12, 8, "dexterity 13 or strength 13"},
+		{"paladin", 13, 8, 13, ""},
+		{"paladin", 13, 8, 12, "charisma 13"},
+		{"paladin", 8, 8, 8, "charisma 13 and strength 13"},
+		{"wizard", 8, 8, 8, "intelligence 13"},
+	}
+	for _, tt := range tests {
+		c := &Character{Strength: tt.str, Dexterity: tt.dex, Charisma: tt.cha, Intelligence: 10}
+		if got := unmetPrerequisites(c, srdClasses[tt.class].Multiclass); got != tt.want {
+			t.Errorf("%s with STR %d, DEX %d, CHA %d: unmet %q, want %q", tt.class, tt.str, tt.dex, tt.cha, got, tt.want)
+		}
+	}
+}
+
+func TestCheckClasses(t *testing.T) {
+	tests := []struct {
+		name    string
+		classes []ClassEntry
+		// check sets checkPrerequisites
+		check  bool
+		code   string
+		fields []string
+	}{
+		{"a known class", []ClassEntry{{Class: "Fighter", Levels: 3}}, true, "", nil},
+		{"a multiclass meeting the prerequisites", []ClassEntry{{Class: "fighter", Levels: 3}, {Class: "Paladin", Levels: 2}}, true, "", nil},
+		{"a homebrew class with its hit die", []ClassEntry{{Class: "Gunslinger", Levels: 1, HitDie: 8}}, true, "", nil},
+		{"a homebrew class without a hit die", []ClassEntry{{Class: "Gunslinger", Levels: 1}}, true, "invalid_classes", []string{"classes[0].hit_die"}},
+		{"no classes", []ClassEntry{}, true, "invalid_classes", []string{"classes"}},
+		{"a blank class", []ClassEntry{{Class: " ", Levels: 1}}, true, "invalid_classes", []string{"classes[0].class"}},
+		{"a class twice", []ClassEntry{{Class: "Fighter", Levels: 1}, {Class: " fighter ", Levels: 1}}, true, "invalid_classes", []string{"classes[1].class"}},
+		{"more than 20 levels", []ClassEntry{{Class: "Fighter", Levels: 15}, {Class: "Paladin", Levels: 6}}, true, "invalid_classes", []string{"classes"}},
+		{"a multiclass missing a prerequisite", []ClassEntry{{Class: "Fighter", Levels: 3}, {Class: "Wizard", Levels: 2}}, true, "multiclass_prerequisites", []string{"classes[1].class"}},
+		{"prerequisites not checked", []ClassEntry{{Class: "Fighter", Levels: 3}, {Class: "Wizard", Levels: 2}}, false, "", nil},
+	}
+
+	s := NewService(NewMemoryStore(), nil, dice.NewSeededRoller(1), srdClasses)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Character{Classes: tt.classes, Strength: 16, Dexterity: 12, Intelligence: 10, Charisma: 13}
+			err := s.CheckClasses(c, tt.check)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("CheckClasses returned %v", err)
+				}
+				return
+			}
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Code != tt.code {
+				t.Fatalf("CheckClasses returned %v, want %s", err, tt.code)
+			}
+			for _, field := range tt.fields {
+				if _, ok := appErr.Fields[field]; !ok {
+					t.Errorf("fields = %v, want %s", appErr.Fields, field)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckClassesFillsIn(t *testing.T) {
+	s := NewService(NewMemoryStore(), nil, dice.NewSeededRoller(1), srdClasses)
+	c := &Character{Strength: 13, Intelligence: 13, Charisma: 13, Classes: []ClassEntry{
+		{Class: " Fighter ", Subclass: " Champion ", Levels: 3, HitDiceSpent: 5},
+		{Class: "Wizard", Levels: 2, HitDiceSpent: -1},
+		// A hit die given for a known class is kept
+		{Class: "Paladin", Levels: 1, HitDie: 8},
+	}}
+	if err := s.CheckClasses(c, true); err != nil {
+		t.Fatal(err)
+	}
+	want := []ClassEntry{
+		{Class: "Fighter", Subclass: "Champion", Levels: 3, HitDie: 10, HitDiceSpent: 3},
+		{Class: "Wizard", Levels: 2, HitDie: 6},
+		{Class: "Paladin", Levels: 1, HitDie: 8},
+	}
+	if !reflect.DeepEqual(c.Classes, want) || c.Level != 6 {
+		t.Errorf("classes = %+v at level %d, want %+v at level 6", c.Classes, c.Level, want)
+	}
+}
+
+func TestUnmarshalLegacyClass(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []ClassEntry
+	}{
+		{"a single class", `{"class": "Rogue", "subclass": "Thief", "level": 4}`, []ClassEntry{{Class: "Rogue", Subclass: "Thief", Levels: 4}}},
+		{"classes", `{"classes": [{"class": "Rogue", "levels": 2, "hit_die": 8}], "class": "Fighter", "level": 2}`, []ClassEntry{{Class: "Rogue", Levels: 2, HitDie: 8}}},
+		{"no class", `{"level": 1}`, nil},
+	}
+	for _, tt := range tests {
+		var c Character
+		if err := json.Unmarshal([]byte(tt.data), &c); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(c.Classes, tt.want) {
+			t.Errorf("%s: classes = %+v, want %+v", tt.name, c.Classes, tt.want)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package character
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// Revision actions recorded for conditions and exhaustion
+const (
+	RevisionAddCondition    = "add_condition"
+	RevisionRemoveCondition = "remove_condition"
+	RevisionExhaustion      = "exhaustion"
+)
+
+// MaxExhaustion is the exhaustion level at which a character dies
+const MaxExhaustion = 6
+
+// DefaultSpeed is the walking speed of a character created without one
+const DefaultSpeed = 30
+
+// Speed modifiers reported in a character's condition effects
+const (
+	SpeedNormal = "normal"
+	SpeedHalved = "halved"
+	SpeedZero   = "zero"
+)
+
+// ErrConditionNotFound is returned when removing a condition the character
+// doesn't have
+var ErrConditionNotFound = apperror.NotFound("condition_not_found", "The character doesn't have that condition")
+
+// conditionRule describes the mechanical effects of one SRD condition
+type conditionRule struct {
+	incapacitated        bool
+	checkDisadvantage    bool
+	attackAdvantage      bool
+	attackDisadvantage   bool
+	attackedAdvantage    bool
+	attackedDisadvantage bool
+	speedZero            bool
+	saveDisadvantage     []string
+	saveAutoFail         []string
+	// implies lists the conditions this one includes, such as unconscious
+	// creatures also being incapacitated and prone
+	implies []string
+}
+
+// conditionRules maps each SRD condition to its effects. Exhaustion has
+// levels and is tracked separately. Effects that depend on the situation,
+// like a prone creature being easier to hit only from within 5 feet, are
+// left to the table.
+var conditionRules = map[string]conditionRule{
+	"blinded":       {attackDisadvantage: true, attackedAdvantage: true},
+	"charmed":       {},
+	"deafened":      {},
+	"frightened":    {checkDisadvantage: true, attackDisadvantage: true},
+	"grappled":      {speedZero: true},
+	"incapacitated": {incapacitated: true},
+	"invisible":     {attackAdvantage: true, attackedDisadvantage: true},
+	"paralyzed": {
+		speedZero: true, attackedAdvantage: true,
+		saveAutoFail: []string{Strength, Dexterity}, implies: []string{"incapacitated"},
+	},
+	"petrified": {
+		speedZero: true, attackedAdvantage: true,
+		saveAutoFail: []string{Strength, Dexterity}, implies: []string{"incapacitated"},
+	},
+	"poisoned": {checkDisadvantage: true, attackDisadvantage: true},
+	"prone":    {attackDisadvantage: true},
+	"restrained": {
+		speedZero: true, attackDisadvantage: true, attackedAdvantage: true,
+		saveDisadvantage: []string{Dexterity},
+	},
+	"stunned": {
+		speedZero: true, attackedAdvantage: true,
+		saveAutoFail: []string{Strength, Dexterity}, implies: []string{"incapacitated"},
+	},
+	"unconscious": {
+		speedZero: true, attackedAdvantage: true,
+		saveAutoFail: []string{Strength, Dexterity}, implies: []string{"incapacitated", "prone"},
+	},
+}
+
+// Conditions are the names of the conditions a character can have, sorted
+var Conditions = func() []string {
+	names := make([]string, 0, len(conditionRules))
+	for name := range conditionRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}()
+
+// Duration is how long a condition lasts from when it was applied, in
+// rounds or minutes
+type Duration struct {
+	Amount int    `json:"amount" binding:"required,min=1,max=10000"`
+	Unit   string `json:"unit" binding:"required,oneof=rounds minutes"`
+}
+
+// Condition is a condition affecting a character. Source names what caused
+// it, such as the creature frightening them; the same condition from two
+// sources is tracked twice so ending one leaves the other.
+type Condition struct {
+	Name      string    `json:"name"`
+	Source    *string   `json:"source"`
+	Duration  *Duration `json:"duration"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// AddConditionRequest represents applying a condition to a character
+type AddConditionRequest struct {
+	Name     string    `json:"name" binding:"required"`
+	Source   *string   `json:"source" binding:"omitempty,max=100"`
+	Duration *Duration `json:"duration"`
+}
+
+// ExhaustionRequest represents setting a character's exhaustion level
+type ExhaustionRequest struct {
+	Level *int `json:"level" binding:"required,min=0,max=6"`
+}
+
+// ConditionEffects are the mechanical effects of a character's conditions
+// and exhaustion. Advantage and disadvantage are reported separately; when
+// both apply to a roll they cancel out.
+type ConditionEffects struct {
+	Incapacitated            bool `json:"incapacitated"`
+	AbilityCheckDisadvantage bool `json:"ability_check_disadvantage"`
+	AttackAdvantage          bool `json:"attack_advantage"`
+	AttackDisadvantage       bool `json:"attack_disadvantage"`
+	// AttackedWithAdvantage and AttackedWithDisadvantage apply to attack
+	// rolls made against the character
+	AttackedWithAdvantage    bool `json:"attacked_with_advantage"`
+	AttackedWithDisadvantage bool `json:"attacked_with_disadvantage"`
+	// SaveDisadvantage and SaveAutoFail list the abilities whose saving
+	// throws are made with disadvantage or fail automatically
+	SaveDisadvantage []string `json:"save_disadvantage"`
+	SaveAutoFail     []string `json:"save_auto_fail"`
+	// Speed is the character's walking speed after SpeedModifier
+	Speed         int    `json:"speed"`
+	SpeedModifier string `json:"speed_modifier"`
+	// MaxHP is the hit point maximum after exhaustion, halved from level 4
+	MaxHP       *int `json:"max_hp"`
+	MaxHPHalved bool `json:"max_hp_halved"`
+}
+
+// AddCondition applies a condition to a character. Applying a condition
+// the character already has from the same source replaces it, restarting
+// its duration.
+func (s *Service) AddCondition(characterID, userID string, req *AddConditionRequest) (*Character, error) {
+	if _, ok := conditionRules[req.Name]; !ok {
+		msg := "must be a condition"
+		if req.Name == "exhaustion" {
+			msg = "must be a condition; set exhaustion levels on the exhaustion endpoint"
+		}
+		return nil, apperror.Field("name", msg)
+	}
+
+	return s.Apply(characterID, userID, AccessCombat, RevisionAddCondition, func(c *Character) error {
+		c.Conditions = slices.DeleteFunc(c.Conditions, func(existing Condition) bool {
+			return existing.matches(req.Name, req.Source)
+		})
+		c.Conditions = append(c.Conditions, Condition{
+			Name:      req.Name,
+			Source:    req.Source,
+			Duration:  req.Duration,
+			AppliedAt: time.Now(),
+		})
+		return nil
+	})
+}
+
+// RemoveCondition ends a condition on a character. Given a source, only the
+// condition from that source ends; otherwise it ends from every source.
+func (s *Service) RemoveCondition(characterID, userID, name string, source *string) (*Character, error) {
+	return s.Apply(characterID, userID, AccessCombat, RevisionRemoveCondition, func(c *Character) error {
+		remaining := slices.DeleteFunc(c.Conditions, func(existing Condition) bool {
+			return existing.Name == name && (source == nil || existing.matches(name, source))
+		})
+		if len(remaining) == len(c.Conditions) {
+			return ErrConditionNotFound
+		}
+		c.Conditions = remaining
+		return nil
+	})
+}
+
+// SetExhaustion sets a character's exhaustion level. Reaching level 6 kills
+// the character; from level 4 their current hit points can't exceed the
+// halved maximum.
+func (s *Service) SetExhaustion(characterID, userID string, req *ExhaustionRequest) (*Character, error) {
+	return s.Apply(characterID, userID, AccessCombat, RevisionExhaustion, func(c *Character) error {
+		c.setExhaustion(*req.Level)
+		return nil
+	})
+}
+
+// setExhaustion changes the exhaustion level and applies its consequences
+// to hit points and death
+func (c *Character) setExhaustion(level int) {
+	c.Exhaustion = min(max(level, 0), MaxExhaustion)
+	if c.Exhaustion >= MaxExhaustion {
+		c.DeathSaves = DeathSaves{Dead: true}
+	}
+	if c.MaxHP != nil && c.CurrentHP != nil {
+		hp := min(*c.CurrentHP, c.HPMax())
+		c.CurrentHP = &hp
+	}
+}
+
+// HPMax returns the character's hit point maximum after exhaustion, which
+// current hit points can't exceed. MaxHP must be set.
+func (c *Character) HPMax() int {
+	if c.Exhaustion >= 4 {
+		// Halving never takes a character below 1 hit point
+		return max(*c.MaxHP/2, min(*c.MaxHP, 1))
+	}
+	return *c.MaxHP
+}
+
+// conditionEffects works out the combined effects of a character's
+// conditions and exhaustion level
+func (c *Character) conditionEffects() *ConditionEffects {
+	effects := &ConditionEffects{
+		SaveDisadvantage: []string{},
+		SaveAutoFail:     []string{},
+		Speed:            c.Speed,
+		SpeedModifier:    SpeedNormal,
+	}
+
+	var speedZero bool
+	applied := map[string]bool{}
+	var apply func(name string)
+	apply = func(name string) {
+		if applied[name] {
+			return
+		}
+		applied[name] = true
+		rule := conditionRules[name]
+		effects.Incapacitated = effects.Incapacitated || rule.incapacitated
+		effects.AbilityCheckDisadvantage = effects.AbilityCheckDisadvantage || rule.checkDisadvantage
+		effects.AttackAdvantage = effects.AttackAdvantage || rule.attackAdvantage
+		effects.AttackDisadvantage = effects.AttackDisadvantage || rule.attackDisadvantage
+		effects.AttackedWithAdvantage = effects.AttackedWithAdvantage || rule.attackedAdvantage
+		effects.AttackedWithDisadvantage = effects.AttackedWithDisadvantage || rule.attackedDisadvantage
+		speedZero = speedZero || rule.speedZero
+		effects.SaveDisadvantage = append(effects.SaveDisadvantage, rule.saveDisadvantage...)
+		effects.SaveAutoFail = append(effects.SaveAutoFail, rule.saveAutoFail...)
+		for _, implied := range rule.implies {
+			apply(implied)
+		}
+	}
+	for _, condition := range c.Conditions {
+		apply(condition.Name)
+	}
+
+	// Exhaustion levels are cumulative
+	exhaustion := c.Exhaustion
+	if exhaustion >= 1 {
+		effects.AbilityCheckDisadvantage = true
+	}
+	if exhaustion >= 3 {
+		effects.AttackDisadvantage = true
+		effects.SaveDisadvantage = append(effects.SaveDisadvantage, Abilities...)
+	}
+	if exhaustion >= 5 {
+		speedZero = true
+	}
+
+	switch {
+	case speedZero:
+		effects.Speed = 0
+		effects.SpeedModifier = SpeedZero
+	case exhaustion >= 2:
+		effects.Speed = c.Speed / 2
+		effects.SpeedModifier = SpeedHalved
+	}
+
+	if c.MaxHP != nil {
+		hp := c.HPMax()
+		effects.MaxHP = &hp
+		effects.MaxHPHalved = exhaustion >= 4
+	}
+
+	effects.SaveDisadvantage = abilityOrder(effects.SaveDisadvantage)
+	effects.SaveAutoFail = abilityOrder(effects.SaveAutoFail)
+	return effects
+}
+
+// abilityOrder drops duplicate abilities and sorts them in the usual order,
+// Strength first
+func abilityOrder(abilities []string) []string {
+	ordered := []string{}
+	for _, ability := range Abilities {
+		if slices.Contains(abilities, ability) {
+			ordered = append(ordered, ability)
+		}
+	}
+	return ordered
+}
+
+// matches reports whether a condition has the given name and source
+func (c Condition) matches(name string, source *string) bool {
+	if c.Name != name {
+		return false
+	}
+	if c.Source == nil || source == nil {
+		return c.Source == nil && source == nil
+	}
+	return *c.Source == *source
+}
+
+// cloneConditions returns a deep copy of a list of conditions, never nil
+func cloneConditions(conditions []Condition) []Condition {
+	cp := make([]Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		if condition.Source != nil {
+			source := *condition.Source
+			condition.Source = &source
+		}
+		if condition.Duration != nil {
+			duration := *condition.Duration
+			condition.Duration = &duration
+		}
+		cp = append(cp, condition)
+	}
+	return cp
+}
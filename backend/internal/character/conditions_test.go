@@ -0,0 +1,222 @@
+package character
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+func TestConditionEffects(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []string
+		want       ConditionEffects
+	}{
+		{"none", nil, ConditionEffects{Speed: 30, SpeedModifier: SpeedNormal}},
+		{"poisoned", []string{"poisoned"}, ConditionEffects{
+			AbilityCheckDisadvantage: true, AttackDisadvantage: true, Speed: 30, SpeedModifier: SpeedNormal,
+		}},
+		{"restrained", []string{"restrained"}, ConditionEffects{
+			AttackDisadvantage: true, AttackedWithAdvantage: true,
+			SaveDisadvantage: []string{Dexterity}, Speed: 0, SpeedModifier: SpeedZero,
+		}},
+		// Unconscious creatures are also incapacitated and prone, so their own
+		// attacks have disadvantage
+		{"unconscious", []string{"unconscious"}, ConditionEffects{
+			Incapacitated: true, AttackDisadvantage: true, AttackedWithAdvantage: true,
+			SaveAutoFail: []string{Strength, Dexterity}, Speed: 0, SpeedModifier: SpeedZero,
+		}},
+		{"stunned", []string{"stunned"}, ConditionEffects{
+			Incapacitated: true, AttackedWithAdvantage: true,
+			SaveAutoFail: []string{Strength, Dexterity}, Speed: 0, SpeedModifier: SpeedZero,
+		}},
+		// Advantage and disadvantage are both reported
+		{"invisible and blinded", []string{"invisible", "blinded"}, ConditionEffects{
+			AttackAdvantage: true, AttackDisadvantage: true,
+			AttackedWithAdvantage: true, AttackedWithDisadvantage: true, Speed: 30, SpeedModifier: SpeedNormal,
+		}},
+		{"paralyzed and restrained", []string{"paralyzed", "restrained"}, ConditionEffects{
+			Incapacitated: true, AttackDisadvantage: true, AttackedWithAdvantage: true,
+			SaveDisadvantage: []string{Dexterity}, SaveAutoFail: []string{Strength, Dexterity},
+			Speed: 0, SpeedModifier: SpeedZero,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCharacter()
+			for _, name := range tt.conditions {
+				c.Conditions = append(c.Conditions, Condition{Name: name})
+			}
+			if tt.want.SaveDisadvantage == nil {
+				tt.want.SaveDisadvantage = []string{}
+			}
+			if tt.want.SaveAutoFail == nil {
+				tt.want.SaveAutoFail = []string{}
+			}
+			if got := c.conditionEffects(); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("effects = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionEffectsExhaustion(t *testing.T) {
+	tests := []struct {
+		level              int
+		checkDisadvantage  bool
+		attackDisadvantage bool
+		saveDisadvantage   []string
+		speed              int
+		speedModifier      string
+		maxHP              int
+		maxHPHalved        bool
+	}{
+		{0, false, false, []string{}, 30, SpeedNormal, 31, false},
+		{1, true, false, []string{}, 30, SpeedNormal, 31, false},
+		{2, true, false, []string{}, 15, SpeedHalved, 31, false},
+		{3, true, true, Abilities, 15, SpeedHalved, 31, false},
+		{4, true, true, Abilities, 15, SpeedHalved, 15, true},
+		{5, true, true, Abilities, 0, SpeedZero, 15, true},
+		{6, true, true, Abilities, 0, SpeedZero, 15, true},
+	}
+
+	for _, tt := range tests {
+		c := testCharacter()
+		maxHP := 31
+		c.MaxHP = &maxHP
+		c.Exhaustion = tt.level
+
+		got := c.conditionEffects()
+		if got.AbilityCheckDisadvantage != tt.checkDisadvantage || got.AttackDisadvantage != tt.attackDisadvantage {
+			t.Errorf("exhaustion %d: check disadvantage %t, attack disadvantage %t, want %t, %t",
+				tt.level, got.AbilityCheckDisadvantage, got.AttackDisadvantage, tt.checkDisadvantage, tt.attackDisadvantage)
+		}
+		if !reflect.DeepEqual(got.SaveDisadvantage, tt.saveDisadvantage) {
+			t.Errorf("exhaustion %d: save disadvantage %v, want %v", tt.level, got.SaveDisadvantage, tt.saveDisadvantage)
+		}
+		if got.Speed != tt.speed || got.SpeedModifier != tt.speedModifier {
+			t.Errorf("exhaustion %d: speed %d (%s), want %d (%s)", tt.level, got.Speed, got.SpeedModifier, tt.speed, tt.speedModifier)
+		}
+		if *got.MaxHP != tt.maxHP || got.MaxHPHalved != tt.maxHPHalved {
+			t.Errorf("exhaustion %d: max hit points %d (halved %t), want %d (halved %t)",
+				tt.level, *got.MaxHP, got.MaxHPHalved, tt.maxHP, tt.maxHPHalved)
+		}
+	}
+}
+
+func TestHPMax(t *testing.T) {
+	tests := []struct{ maxHP, exhaustion, want int }{
+		{30, 0, 30}, {30, 3, 30}, {30, 4, 15}, {31, 4, 15}, {31, 6, 15}, {1, 4, 1}, {0, 4, 0},
+	}
+	for _, tt := range tests {
+		c := &Character{MaxHP: &tt.maxHP, Exhaustion: tt.exhaustion}
+		if got := c.HPMax(); got != tt.want {
+			t.Errorf("HPMax() of %d at exhaustion %d = %d, want %d", tt.maxHP, tt.exhaustion, got, tt.want)
+		}
+	}
+}
+
+func TestSetExhaustion(t *testing.T) {
+	s := newTestService(t)
+	c, userID := createTestCharacter(t, s, nil)
+	set := func(level int) *Character {
+		t.Helper()
+		char, err := s.SetExhaustion(c.ID.String(), userID, &ExhaustionRequest{Level: &level})
+		if err != nil {
+			t.Fatalf("SetExhaustion(%d) returned %v", level, err)
+		}
+		return char
+	}
+
+	// Level 4 caps current hit points at the halved maximum, and they stay
+	// there when it drops again
+	if char := set(4); *char.CurrentHP != 15 || *char.MaxHP != 30 {
+		t.Errorf("exhaustion 4 left %d/%d hit points, want 15/30", *char.CurrentHP, *char.MaxHP)
+	}
+	if char := set(3); *char.CurrentHP != 15 {
+		t.Errorf("exhaustion 3 left %d hit points, want 15", *char.CurrentHP)
+	}
+
+	char := set(6)
+	if char.Status() != StatusDead || !char.DeathSaves.Dead {
+		t.Errorf("exhaustion 6 left the character %s", char.Status())
+	}
+}
+
+func TestConditionSources(t *testing.T) {
+	s := newTestService(t)
+	c, userID := createTestCharacter(t, s, nil)
+	id := c.ID.String()
+	dragon, lich := "Dragon", "Lich"
+	add := func(source *string, rounds int) *Character {
+		t.Helper()
+		char, err := s.AddCondition(id, userID, &AddConditionRequest{
+			Name: "frightened", Source: source, Duration: &Duration{Amount: rounds, Unit: "rounds"},
+		})
+		if err != nil {
+			t.Fatalf("AddCondition returned %v", err)
+		}
+		return char
+	}
+	sources := func(char *Character) map[string]int {
+		found := map[string]int{}
+		for _, condition := range char.Conditions {
+			source := "none"
+			if condition.Source != nil {
+				source = *condition.Source
+			}
+			found[source] = condition.Duration.Amount
+		}
+		return found
+	}
+
+	add(&dragon, 10)
+	add(&lich, 3)
+	add(nil, 1)
+	// The same source again replaces its condition, restarting the duration
+	char := add(&dragon, 5)
+	if got, want := sources(char), map[string]int{"Dragon": 5, "Lich": 3, "none": 1}; len(char.Conditions) != 3 || !reflect.DeepEqual(got, want) {
+		t.Errorf("conditions by source = %v, want %v", got, want)
+	}
+
+	// Removing one source leaves the others
+	char, err := s.RemoveCondition(id, userID, "frightened", &dragon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sources(char), map[string]int{"Lich": 3, "none": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("conditions by source = %v, want %v", got, want)
+	}
+	if _, err := s.RemoveCondition(id, userID, "frightened", &dragon); !errors.Is(err, ErrConditionNotFound) {
+		t.Errorf("removing an ended source returned %v, want ErrConditionNotFound", err)
+	}
+
+	// Without a source it ends from every source
+	if char, err = s.RemoveCondition(id, userID, "frightened", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(char.Conditions) != 0 {
+		t.Errorf("conditions = %+v, want none", char.Conditions)
+	}
+	if _, err := s.RemoveCondition(id, userID, "frightened", nil); !errors.Is(err, ErrConditionNotFound) {
+		t.Errorf("removing a missing condition returned %v, want ErrConditionNotFound", err)
+	}
+}
+
+func TestAddConditionUnknown(t *testing.T) {
+	s := newTestService(t)
+	c, userID := createTestCharacter(t, s, nil)
+	for _, name := range []string{"exhaustion", "cursed"} {
+		_, err := s.AddCondition(c.ID.String(), userID, &AddConditionRequest{Name: name})
+		var appErr *apperror.Error
+		if !errors.As(err, &appErr) {
+			t.Fatalf("AddCondition(%s) returned %v, want a field error", name, err)
+		}
+		if _, ok := appErr.Fields["name"]; !ok {
+			t.Errorf("AddCondition(%s) fields = %v, want name", name, appErr.Fields)
+		}
+	}
+}
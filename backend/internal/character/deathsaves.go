@@ -0,0 +1,214 @@
+package character
+
+import (
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/dice"
+)
+
+// Statuses of a character's life, derived from hit points and death saves
+const (
+	StatusConscious = "conscious"
+	StatusDying     = "dying"
+	StatusStable    = "stable"
+	StatusDead      = "dead"
+)
+
+// Death save outcomes
+const (
+	DeathSaveSuccess         = "success"
+	DeathSaveFailure         = "failure"
+	DeathSaveCriticalSuccess = "critical_success"
+	DeathSaveCriticalFailure = "critical_failure"
+)
+
+// Revision actions recorded for death saves
+const (
+	RevisionDeathSave = "death_save"
+	RevisionStabilize = "stabilize"
+	RevisionRevive    = "revive"
+)
+
+// DeathSavesToResolve is the number of successes that stabilize a dying
+// character, or failures that kill them
+const DeathSavesToResolve = 3
+
+var (
+	// ErrNotDying is returned when rolling a death save or stabilizing a
+	// character that isn't dying
+	ErrNotDying = apperror.Conflict("not_dying", "The character isn't dying")
+	// ErrCharacterDead is returned when changing the hit points of a dead
+	// character other than by reviving them
+	ErrCharacterDead = apperror.Conflict("character_dead", "The character is dead and must be revived first")
+	// ErrNotDead is returned when reviving a character that is alive
+	ErrNotDead = apperror.Conflict("not_dead", "The character isn't dead")
+)
+
+// DeathSaves tracks a character at 0 hit points. Successes and failures
+// count up while dying and are cleared once the character is stable or
+// regains hit points.
+type DeathSaves struct {
+	Successes int  `json:"successes"`
+	Failures  int  `json:"failures"`
+	Stable    bool `json:"stable"`
+	Dead      bool `json:"dead"`
+}
+
+// DeathSaveRequest represents a death saving throw. Roll records a d20
+// rolled at the table; without it the server rolls.
+type DeathSaveRequest struct {
+	Roll         *int `json:"roll" binding:"omitempty,min=1,max=20"`
+	Advantage    bool `json:"advantage"`
+	Disadvantage bool `json:"disadvantage"`
+}
+
+// ReviveRequest represents bringing a dead character back, with the hit
+// points they return with
+type ReviveRequest struct {
+	HP int `json:"hp" binding:"omitempty,min=1"`
+}
+
+// DeathSaveResult is the outcome of a death saving throw
+type DeathSaveResult struct {
+	Roll       int          `json:"roll"`
+	Rolled     *dice.Result `json:"rolled,omitempty"`
+	Outcome    string       `json:"outcome"`
+	DeathSaves DeathSaves   `json:"death_saves"`
+	CurrentHP  int          `json:"current_hp"`
+	Status     string       `json:"status"`
+}
+
+// Status reports whether a character is conscious, dying, stable or dead
+func (c *Character) Status() string {
+	switch {
+	case c.DeathSaves.Dead:
+		return StatusDead
+	case c.CurrentHP == nil || *c.CurrentHP > 0:
+		return StatusConscious
+	case c.DeathSaves.Stable:
+		return StatusStable
+	}
+	return StatusDying
+}
+
+// RollDeathSave makes a death saving throw for a dying character. A 10 or
+// higher succeeds; a 1 counts as two failures and a 20 brings the character
+// back with 1 hit point. Three successes stabilize them, three failures
+// kill them.
+func (s *Service) RollDeathSave(characterID, userID string, req *DeathSaveRequest) (*DeathSaveResult, error) {
+	// Check the character is dying before rolling, so a save that can't be
+	// made isn't rolled. The write checks again in case that changed.
+	current, _, err := s.authorize(characterID, userID, AccessCombat)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status() != StatusDying {
+		return nil, ErrNotDying
+	}
+
+	result := &DeathSaveResult{}
+	if req.Roll != nil {
+		result.Roll = *req.Roll
+	} else {
+		rolled, err := s.roller.RollNotation("1d20", dice.ModeFor(req.Advantage, req.Disadvantage))
+		if err != nil {
+			return nil, err
+		}
+		result.Roll = rolled.Total
+		result.Rolled = rolled
+	}
+
+	char, err := s.Apply(characterID, userID, AccessCombat, RevisionDeathSave, func(c *Character) error {
+		if c.Status() != StatusDying {
+			return ErrNotDying
+		}
+
+		saves := &c.DeathSaves
+		switch {
+		case result.Roll == 20:
+			result.Outcome = DeathSaveCriticalSuccess
+			hp := 1
+			c.CurrentHP = &hp
+			*saves = DeathSaves{}
+			return nil
+		case result.Roll == 1:
+			result.Outcome = DeathSaveCriticalFailure
+			saves.Failures += 2
+		case result.Roll >= 10:
+			result.Outcome = DeathSaveSuccess
+			saves.Successes++
+		default:
+			result.Outcome = DeathSaveFailure
+			saves.Failures++
+		}
+		saves.resolve()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.DeathSaves = char.DeathSaves
+	result.CurrentHP = *char.CurrentHP
+	result.Status = char.Status()
+	return result, nil
+}
+
+// Stabilize makes a dying character stable, as a successful Medicine check
+// or spare the dying does
+func (s *Service) Stabilize(characterID, userID string) (*Character, error) {
+	return s.Apply(characterID, userID, AccessCombat, RevisionStabilize, func(c *Character) error {
+		if c.Status() != StatusDying {
+			return ErrNotDying
+		}
+		c.DeathSaves = DeathSaves{Stable: true}
+		return nil
+	})
+}
+
+// Revive brings a dead character back with the given hit points, 1 by
+// default, clearing their death saves
+func (s *Service) Revive(characterID, userID string, req *ReviveRequest) (*Character, error) {
+	return s.Apply(characterID, userID, AccessCombat, RevisionRevive, func(c *Character) error {
+		if c.Status() != StatusDead {
+			return ErrNotDead
+		}
+		if c.MaxHP == nil {
+			return ErrMaxHPRequired
+		}
+		// A character killed by exhaustion comes back one level short of it
+		c.Exhaustion = min(c.Exhaustion, MaxExhaustion-1)
+		hp := min(max(req.HP, 1), c.HPMax())
+		c.CurrentHP = &hp
+		c.DeathSaves = DeathSaves{}
+		return nil
+	})
+}
+
+// resolve stabilizes or kills a dying character once enough saves have been
+// made
+func (d *DeathSaves) resolve() {
+	switch {
+	case d.Failures >= DeathSavesToResolve:
+		*d = DeathSaves{Failures: DeathSavesToResolve, Dead: true}
+	case d.Successes >= DeathSavesToResolve:
+		*d = DeathSaves{Stable: true}
+	}
+}
+
+// takeDamageAtZero applies damage to a character already at 0 hit points:
+// a failed death save, two for a critical hit, or death when the damage is
+// at least their hit point maximum
+func (c *Character) takeDamageAtZero(damage int, critical bool) {
+	if damage >= c.HPMax() {
+		c.DeathSaves = DeathSaves{Dead: true}
+		return
+	}
+
+	// A stable character who takes damage starts dying again
+	c.DeathSaves.Stable = false
+	c.DeathSaves.Failures++
+	if critical {
+		c.DeathSaves.Failures++
+	}
+	c.DeathSaves.resolve()
+}
@@ -0,0 +1,119 @@
+package character
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRollDeathSave(t *testing.T) {
+	tests := []struct {
+		name       string
+		rolls      []int
+		outcome    string
+		deathSaves DeathSaves
+		current    int
+		status     string
+	}{
+		{"success", []int{10}, DeathSaveSuccess, DeathSaves{Successes: 1}, 0, StatusDying},
+		{"failure", []int{9}, DeathSaveFailure, DeathSaves{Failures: 1}, 0, StatusDying},
+		{"a 1 fails twice", []int{1}, DeathSaveCriticalFailure, DeathSaves{Failures: 2}, 0, StatusDying},
+		{"a 20 regains 1 hit point", []int{5, 20}, DeathSaveCriticalSuccess, DeathSaves{}, 1, StatusConscious},
+		{"three successes stabilize", []int{12, 3, 15, 18}, DeathSaveSuccess, DeathSaves{Stable: true}, 0, StatusStable},
+		{"three failures kill", []int{12, 3, 1}, DeathSaveCriticalFailure, DeathSaves{Failures: 3, Dead: true}, 0, StatusDead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			c, userID := createTestCharacter(t, s, nil)
+			changeHP(t, s, c, userID, damageOp(30))
+
+			var result *DeathSaveResult
+			for _, roll := range tt.rolls {
+				var err error
+				result, err = s.RollDeathSave(c.ID.String(), userID, &DeathSaveRequest{Roll: &roll})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if result.Outcome != tt.outcome || result.DeathSaves != tt.deathSaves {
+				t.Errorf("last save was a %s leaving %+v, want a %s leaving %+v", result.Outcome, result.DeathSaves, tt.outcome, tt.deathSaves)
+			}
+			if result.CurrentHP != tt.current || result.Status != tt.status {
+				t.Errorf("character is %s at %d hit points, want %s at %d", result.Status, result.CurrentHP, tt.status, tt.current)
+			}
+		})
+	}
+}
+
+func TestRollDeathSaveRolls(t *testing.T) {
+	s := newTestService(t, 4, 15)
+	c, userID := createTestCharacter(t, s, nil)
+	changeHP(t, s, c, userID, damageOp(30))
+
+	result, err := s.RollDeathSave(c.ID.String(), userID, &DeathSaveRequest{Advantage: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Roll != 15 || result.Rolled == nil || result.Outcome != DeathSaveSuccess {
+		t.Errorf("rolled %d (%+v) for a %s, want a 15 rolled with advantage", result.Roll, result.Rolled, result.Outcome)
+	}
+}
+
+func TestRollDeathSaveNotDying(t *testing.T) {
+	// The service has no dice to roll, so rolling a save fails the test
+	s := newTestService(t)
+	conscious, userID := createTestCharacter(t, s, nil)
+	if _, err := s.RollDeathSave(conscious.ID.String(), userID, &DeathSaveRequest{}); !errors.Is(err, ErrNotDying) {
+		t.Errorf("a conscious character's death save returned %v, want ErrNotDying", err)
+	}
+
+	stable, userID := createTestCharacter(t, s, nil)
+	changeHP(t, s, stable, userID, damageOp(30))
+	if _, err := s.Stabilize(stable.ID.String(), userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RollDeathSave(stable.ID.String(), userID, &DeathSaveRequest{}); !errors.Is(err, ErrNotDying) {
+		t.Errorf("a stable character's death save returned %v, want ErrNotDying", err)
+	}
+	if _, err := s.Stabilize(stable.ID.String(), userID); !errors.Is(err, ErrNotDying) {
+		t.Errorf("stabilizing a stable character returned %v, want ErrNotDying", err)
+	}
+
+	// Taking damage while stable starts the dying again
+	hp := changeHP(t, s, stable, userID, damageOp(1))
+	if hp.Status != StatusDying || hp.DeathSaves != (DeathSaves{Failures: 1}) {
+		t.Errorf("damaged stable character is %s with %+v, want dying with one failure", hp.Status, hp.DeathSaves)
+	}
+}
+
+func TestRevive(t *testing.T) {
+	s := newTestService(t)
+	c, userID := createTestCharacter(t, s, nil)
+	if _, err := s.Revive(c.ID.String(), userID, &ReviveRequest{}); !errors.Is(err, ErrNotDead) {
+		t.Errorf("reviving a living character returned %v, want ErrNotDead", err)
+	}
+
+	exhaustion := MaxExhaustion
+	if _, err := s.SetExhaustion(c.ID.String(), userID, &ExhaustionRequest{Level: &exhaustion}); err != nil {
+		t.Fatal(err)
+	}
+	revived, err := s.Revive(c.ID.String(), userID, &ReviveRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *revived.CurrentHP != 1 || revived.Status() != StatusConscious || revived.Exhaustion != MaxExhaustion-1 {
+		t.Errorf("revived %s at %d hit points with exhaustion %d, want conscious at 1 with %d",
+			revived.Status(), *revived.CurrentHP, revived.Exhaustion, MaxExhaustion-1)
+	}
+
+	// Revived hit points are capped by the maximum, halved by exhaustion
+	changeHP(t, s, c, userID, damageOp(100))
+	revived, err = s.Revive(c.ID.String(), userID, &ReviveRequest{HP: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *revived.CurrentHP != 15 {
+		t.Errorf("revived at %d hit points, want 15", *revived.CurrentHP)
+	}
+}
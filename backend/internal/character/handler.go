@@ -1,9 +1,15 @@
 package character
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+
+	"character-sheet-backend/internal/apperror"
 )
 
 // Handler handles character HTTP requests
@@ -20,13 +26,13 @@ func NewHandler(service *Service) *Handler {
 func (h *Handler) GetCharacters(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(apperror.ErrNotAuthenticated)
 		return
 	}
 
 	characters, err := h.service.GetCharactersByUserID(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -38,20 +44,17 @@ func (h *Handler) GetCharacter(c *gin.Context) {
 	characterID := c.Param("id")
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(apperror.ErrNotAuthenticated)
 		return
 	}
 
 	character, err := h.service.GetCharacterByID(characterID, userID.(string))
 	if err != nil {
-		if err.Error() == "character not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Character not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	c.Header("ETag", etag(character.Version))
 	c.JSON(http.StatusOK, character)
 }
 
@@ -59,22 +62,23 @@ func (h *Handler) GetCharacter(c *gin.Context) {
 func (h *Handler) CreateCharacter(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(apperror.ErrNotAuthenticated)
 		return
 	}
 
 	var req CreateCharacterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.FromBinding(err))
 		return
 	}
 
 	character, err := h.service.CreateCharacter(userID.(string), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	c.Header("ETag", etag(character.Version))
 	c.JSON(http.StatusCreated, character)
 }
 
@@ -83,26 +87,29 @@ func (h *Handler) UpdateCharacter(c *gin.Context) {
 	characterID := c.Param("id")
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(apperror.ErrNotAuthenticated)
 		return
 	}
 
 	var req UpdateCharacterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.FromBinding(err))
 		return
 	}
 
-	character, err := h.service.UpdateCharacter(characterID, userID.(string), &req)
+	version, err := expectedVersion(c, req.Version)
 	if err != nil {
-		if err.Error() == "character not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Character not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	character, err := h.service.UpdateCharacter(characterID, userID.(string), version, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", etag(character.Version))
 	c.JSON(http.StatusOK, character)
 }
 
@@ -111,19 +118,538 @@ func (h *Handler) DeleteCharacter(c *gin.Context) {
 	characterID := c.Param("id")
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		c.Error(apperror.ErrNotAuthenticated)
 		return
 	}
 
-	err := h.service.DeleteCharacter(characterID, userID.(string))
-	if err != nil {
-		if err.Error() == "character not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Character not found"})
+	var queryVersion *int
+	if v := c.Query("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.Error(apperror.Field("version", "must be a number"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		queryVersion = &n
+	}
+
+	version, err := expectedVersion(c, queryVersion)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.service.DeleteCharacter(characterID, userID.(string), version)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Character deleted successfully"})
-} 
\ No newline at end of file
+}
+
+// etag formats a character version as a strong entity tag
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// expectedVersion reads the version a write is based on from the If-Match
+// header, falling back to a version sent with the request. One of the two is
+// required; "If-Match: *" matches any version.
+func expectedVersion(c *gin.Context, fallback *int) (int, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" {
+		if fallback == nil {
+			return 0, ErrVersionRequired
+		}
+		if *fallback < 1 {
+			return 0, apperror.Field("version", "must be at least 1")
+		}
+		return *fallback, nil
+	}
+
+	if ifMatch == "*" {
+		return AnyVersion, nil
+	}
+
+	// Only a single tag is meaningful since a character has one version
+	tag := strings.TrimSpace(strings.SplitN(ifMatch, ",", 2)[0])
+	tag = strings.TrimPrefix(tag, "W/")
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || version < 1 {
+		return 0, apperror.Field("If-Match", "must be an entity tag returned by this API")
+	}
+
+	return version, nil
+}
+
+// GetHistory lists a character's revisions
+func (h *Handler) GetHistory(c *gin.Context) {
+	characterID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	revisions, err := h.service.GetHistory(characterID, userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetRevision returns a single revision with the character as it was then
+func (h *Handler) GetRevision(c *gin.Context) {
+	characterID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	revision, err := revisionParam(c.Param("revision"), "revision")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rev, err := h.service.GetRevision(characterID, userID.(string), revision)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rev)
+}
+
+// DiffRevisions returns the field changes between the ?from= and ?to=
+// revisions
+func (h *Handler) DiffRevisions(c *gin.Context) {
+	characterID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	from, err := revisionParam(c.Query("from"), "from")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	to, err := revisionParam(c.Query("to"), "to")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	diff, err := h.service.DiffRevisions(characterID, userID.(string), from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RestoreRevision rolls a character back to an earlier revision. If-Match is
+// optional here since restoring deliberately replaces the current state.
+func (h *Handler) RestoreRevision(c *gin.Context) {
+	characterID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	revision, err := revisionParam(c.Param("revision"), "revision")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	version := AnyVersion
+	if c.GetHeader("If-Match") != "" {
+		if version, err = expectedVersion(c, nil); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	character, err := h.service.RestoreRevision(characterID, userID.(string), revision, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", etag(character.Version))
+	c.JSON(http.StatusOK, character)
+}
+
+// RollCheck rolls a skill check, saving throw, ability check or initiative
+// for a character
+func (h *Handler) RollCheck(c *gin.Context) {
+	characterID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	// The body is optional, e.g. for a plain initiative roll
+	var req CheckRollRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	roll, err := h.service.RollCheck(characterID, userID.(string), c.Param("kind"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roll)
+}
+
+// ChangeHitPoints applies damage, healing and temporary hit points
+func (h *Handler) ChangeHitPoints(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req HPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	hp, err := h.service.ChangeHitPoints(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hp)
+}
+
+// RollDeathSave makes a death saving throw for a dying character
+func (h *Handler) RollDeathSave(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	// The body is optional; without a recorded roll the server rolls
+	var req DeathSaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	result, err := h.service.RollDeathSave(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Stabilize makes a dying character stable
+func (h *Handler) Stabilize(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	character, err := h.service.Stabilize(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, character)
+}
+
+// Revive brings a dead character back
+func (h *Handler) Revive(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req ReviveRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	character, err := h.service.Revive(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, character)
+}
+
+// AddCondition applies a condition to a character
+func (h *Handler) AddCondition(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req AddConditionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	character, err := h.service.AddCondition(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, character)
+}
+
+// RemoveCondition ends a condition on a character, from every source unless
+// the source query parameter names one
+func (h *Handler) RemoveCondition(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var source *string
+	if value, ok := c.GetQuery("source"); ok {
+		source = &value
+	}
+
+	character, err := h.service.RemoveCondition(c.Param("id"), userID.(string), c.Param("condition"), source)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, character)
+}
+
+// SetExhaustion sets a character's exhaustion level
+func (h *Handler) SetExhaustion(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req ExhaustionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	character, err := h.service.SetExhaustion(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, character)
+}
+
+// ShortRest takes a short rest, spending the hit dice in the request
+func (h *Handler) ShortRest(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	// The body is optional; a rest without it spends no hit dice
+	var req ShortRestRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	result, err := h.service.ShortRest(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// LongRest takes a long rest
+func (h *Handler) LongRest(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	result, err := h.service.LongRest(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// AddResource adds a resource to a character
+func (h *Handler) AddResource(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req CreateResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	character, err := h.service.AddResource(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, character)
+}
+
+// UpdateResource changes a resource
+func (h *Handler) UpdateResource(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req UpdateResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	character, err := h.service.UpdateResource(c.Param("id"), userID.(string), c.Param("resourceId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, character)
+}
+
+// RemoveResource removes a resource
+func (h *Handler) RemoveResource(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	character, err := h.service.RemoveResource(c.Param("id"), userID.(string), c.Param("resourceId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, character)
+}
+
+// DecrementResource uses up some of a resource
+func (h *Handler) DecrementResource(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	// The body is optional; without it one use is spent
+	var req ResourceAmountRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	character, err := h.service.UseResource(c.Param("id"), userID.(string), c.Param("resourceId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, character)
+}
+
+// IncrementResource gives back some of a resource
+func (h *Handler) IncrementResource(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	// The body is optional; without it one use is regained
+	var req ResourceAmountRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	character, err := h.service.RestoreResource(c.Param("id"), userID.(string), c.Param("resourceId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, character)
+}
+
+// Recharge refills every resource of a recharge type
+func (h *Handler) Recharge(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req RechargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	result, err := h.service.Recharge(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// revisionParam parses a revision number from a path or query parameter
+func revisionParam(value, name string) (int, error) {
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision < 1 {
+		return 0, apperror.Field(name, "must be a revision number")
+	}
+	return revision, nil
+}
@@ -0,0 +1,129 @@
+package character
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// Revision actions
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionRestore = "restore"
+	// RevisionHitPoints records damage, healing and temporary hit points
+	RevisionHitPoints = "hit_points"
+)
+
+// ErrRevisionNotFound is returned when a character has no such revision
+var ErrRevisionNotFound = apperror.NotFound("revision_not_found", "Revision not found")
+
+// FieldChange describes one field that differs between two revisions
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Revision is an immutable record of a character write. Its number is the
+// character version the write produced.
+type Revision struct {
+	CharacterID  uuid.UUID     `json:"character_id" db:"character_id"`
+	Revision     int           `json:"revision" db:"revision"`
+	UserID       uuid.UUID     `json:"user_id" db:"user_id"`
+	Action       string        `json:"action" db:"action"`
+	RestoredFrom *int          `json:"restored_from,omitempty" db:"restored_from"`
+	Changes      []FieldChange `json:"changes" db:"changes"`
+	Snapshot     *Character    `json:"snapshot,omitempty" db:"snapshot"`
+	CreatedAt    time.Time     `json:"created_at" db:"created_at"`
+}
+
+// RevisionDiff lists the field changes between two revisions
+type RevisionDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// unversionedFields are bookkeeping fields left out of diffs and restores
+var unversionedFields = map[string]bool{
+	"id":         true,
+	"user_id":    true,
+	"version":    true,
+	"derived":    true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// NewRevision records a change from before to after made by a user. The
+// after character's UpdatedAt is used as the time of the change.
+func NewRevision(userID, action string, before, after *Character) *Revision {
+	return &Revision{
+		UserID:    uuid.MustParse(userID),
+		Action:    action,
+		Changes:   diffCharacters(before, after),
+		CreatedAt: after.UpdatedAt,
+	}
+}
+
+// diffCharacters returns the stored fields that differ between two
+// characters, ordered by field name
+func diffCharacters(before, after *Character) []FieldChange {
+	beforeFields := characterFields(before)
+	afterFields := characterFields(after)
+
+	changes := []FieldChange{}
+	for field, afterValue := range afterFields {
+		beforeValue := beforeFields[field]
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			changes = append(changes, FieldChange{Field: field, Before: beforeValue, After: afterValue})
+		}
+	}
+	for field, beforeValue := range beforeFields {
+		if _, ok := afterFields[field]; !ok {
+			changes = append(changes, FieldChange{Field: field, Before: beforeValue, After: nil})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// characterFields flattens a character to its JSON fields so that any field
+// added to Character is picked up by diffs automatically
+func characterFields(c *Character) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if c == nil {
+		return fields
+	}
+
+	data, err := json.Marshal(c.Clone())
+	if err != nil {
+		return fields
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fields
+	}
+
+	for field := range unversionedFields {
+		delete(fields, field)
+	}
+	return fields
+}
+
+// restoreFrom returns a copy of current with every versioned field taken
+// from snapshot
+func restoreFrom(current, snapshot *Character) *Character {
+	restored := snapshot.Clone()
+	restored.ID = current.ID
+	restored.UserID = current.UserID
+	restored.Version = current.Version
+	restored.CreatedAt = current.CreatedAt
+	restored.UpdatedAt = time.Now()
+	return restored
+}
@@ -0,0 +1,73 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// withoutKeys round-trips a character through JSON with some keys removed,
+// as a revision snapshot recorded before those fields existed reads back
+func withoutKeys(t *testing.T, c *Character, keys ...string) *Character {
+	t.Helper()
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]any{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		delete(fields, key)
+	}
+	if data, err = json.Marshal(fields); err != nil {
+		t.Fatal(err)
+	}
+	old := &Character{}
+	if err := json.Unmarshal(data, old); err != nil {
+		t.Fatal(err)
+	}
+	return old
+}
+
+func TestRestorePreSpeedRevision(t *testing.T) {
+	s := newTestService(t)
+	c, userID := createTestCharacter(t, s, func(req *CreateCharacterRequest) {
+		speed := 25
+		req.Speed = &speed
+	})
+	name := "Tordek Ironfist"
+	if _, err := s.UpdateCharacter(c.ID.String(), userID, c.Version, &UpdateCharacterRequest{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Revision 1 as stored before characters had a speed
+	store := s.store.(*MemoryStore)
+	first := store.revisions[c.ID.String()][0]
+	first.Snapshot = withoutKeys(t, first.Snapshot, "speed")
+	if first.Snapshot.Speed != DefaultSpeed {
+		t.Fatalf("pre-speed snapshot read back with speed %d, want %d", first.Snapshot.Speed, DefaultSpeed)
+	}
+
+	diff, err := s.DiffRevisions(c.ID.String(), userID, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, change := range diff.Changes {
+		if change.Field == "speed" && fmt.Sprint(change.Before) != fmt.Sprint(DefaultSpeed) {
+			t.Errorf("diff changes speed from %v to %v, want from %d", change.Before, change.After, DefaultSpeed)
+		}
+	}
+
+	restored, err := s.RestoreRevision(c.ID.String(), userID, 1, AnyVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Speed != DefaultSpeed || restored.Name != "Tordek" {
+		t.Errorf("restored %s with speed %d, want Tordek with %d", restored.Name, restored.Speed, DefaultSpeed)
+	}
+	if effects := ComputeDerived(restored).Effects; effects.Speed != DefaultSpeed || effects.SpeedModifier != SpeedNormal {
+		t.Errorf("restored effects have speed %d (%s)", effects.Speed, effects.SpeedModifier)
+	}
+}
@@ -0,0 +1,245 @@
+package character
+
+import (
+	"fmt"
+	"slices"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// Hit point operations
+const (
+	HPDamage = "damage"
+	HPHeal   = "heal"
+	HPTemp   = "temp"
+)
+
+// How a character's defenses changed the damage of an operation
+const (
+	DamageResisted   = "resistance"
+	DamageVulnerable = "vulnerability"
+	DamageImmune     = "immunity"
+)
+
+// DamageTypes are the SRD damage types
+var DamageTypes = []string{
+	"acid", "bludgeoning", "cold", "fire", "force", "lightning", "necrotic",
+	"piercing", "poison", "psychic", "radiant", "slashing", "thunder",
+}
+
+// ErrMaxHPRequired is returned when changing the hit points of a character
+// without maximum hit points
+var ErrMaxHPRequired = apperror.Conflict("max_hp_required", "Set the character's maximum hit points first")
+
+// Defenses are the damage types a character resists, is vulnerable to or is
+// immune to
+type Defenses struct {
+	Resistances     []string `json:"resistances"`
+	Vulnerabilities []string `json:"vulnerabilities"`
+	Immunities      []string `json:"immunities"`
+}
+
+// HPOperation is one change to a character's hit points. Damage may name a
+// damage type so the character's defenses apply to it, and be marked as a
+// critical hit, which counts as two death save failures at 0 hit points.
+type HPOperation struct {
+	Type       string `json:"type" binding:"required,oneof=damage heal temp"`
+	Amount     int    `json:"amount" binding:"min=0,max=10000"`
+	DamageType string `json:"damage_type" binding:"max=20"`
+	Critical   bool   `json:"critical"`
+}
+
+// HPRequest represents the request to change a character's hit points. The
+// operations are applied in order in one write, so a hit dealing two damage
+// types can be sent as one request.
+type HPRequest struct {
+	Operations []HPOperation `json:"operations" binding:"required,min=1,max=20,dive"`
+}
+
+// AppliedHP is the outcome of one hit point operation
+type AppliedHP struct {
+	HPOperation
+	// Modifiers are the defenses that changed the damage
+	Modifiers []string `json:"modifiers"`
+	// Effective is the amount after defenses, of which Absorbed came off
+	// temporary hit points
+	Effective int `json:"effective"`
+	Absorbed  int `json:"absorbed"`
+	// HPChange is how much current hit points changed
+	HPChange int `json:"hp_change"`
+}
+
+// HitPoints is a character's hit point state after a change
+type HitPoints struct {
+	CurrentHP  int          `json:"current_hp"`
+	MaxHP      int          `json:"max_hp"`
+	TempHP     int          `json:"temp_hp"`
+	DeathSaves DeathSaves   `json:"death_saves"`
+	Status     string       `json:"status"`
+	Applied    []*AppliedHP `json:"applied"`
+}
+
+// ChangeHitPoints applies damage, healing and temporary hit points to a
+// character in one locked write. Owners and users with combat access, such
+// as the campaign's DM, may do so.
+func (s *Service) ChangeHitPoints(characterID, userID string, req *HPRequest) (*HitPoints, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	var applied []*AppliedHP
+	char, err := s.Apply(characterID, userID, AccessCombat, RevisionHitPoints, func(c *Character) error {
+		if c.MaxHP == nil {
+			return ErrMaxHPRequired
+		}
+		if c.DeathSaves.Dead {
+			return ErrCharacterDead
+		}
+		applied = make([]*AppliedHP, 0, len(req.Operations))
+		for _, op := range req.Operations {
+			applied = append(applied, c.applyHP(op))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &HitPoints{
+		CurrentHP:  *char.CurrentHP,
+		MaxHP:      char.HPMax(),
+		TempHP:     char.TempHP,
+		DeathSaves: char.DeathSaves,
+		Status:     char.Status(),
+		Applied:    applied,
+	}, nil
+}
+
+// applyHP applies one operation. Healing is capped at maximum hit points, as
+// reduced by exhaustion, and damage at 0; temporary hit points absorb damage
+// first and don't stack, a character keeps the higher of the old and new
+// amount. Operations after one that killed the character have no effect.
+func (c *Character) applyHP(op HPOperation) *AppliedHP {
+	maxHP := c.HPMax()
+	current := maxHP
+	if c.CurrentHP != nil {
+		current = min(*c.CurrentHP, maxHP)
+	}
+	result := &AppliedHP{HPOperation: op, Modifiers: []string{}, Effective: op.Amount}
+	if c.DeathSaves.Dead {
+		result.Effective = 0
+		return result
+	}
+
+	switch op.Type {
+	case HPHeal:
+		healed := min(current+op.Amount, maxHP)
+		result.HPChange = healed - current
+		current = healed
+		if current > 0 {
+			c.DeathSaves = DeathSaves{}
+		}
+	case HPTemp:
+		c.TempHP = max(c.TempHP, op.Amount)
+	case HPDamage:
+		result.Modifiers, result.Effective = c.Defenses.apply(op.DamageType, op.Amount)
+		result.Absorbed = min(c.TempHP, result.Effective)
+		c.TempHP -= result.Absorbed
+		damage := result.Effective - result.Absorbed
+		switch {
+		case damage == 0:
+		case current == 0:
+			c.takeDamageAtZero(damage, op.Critical)
+		case damage >= current:
+			// Damage left over after dropping to 0 that reaches the hit
+			// point maximum kills outright
+			c.DeathSaves = DeathSaves{Dead: damage-current >= maxHP}
+		}
+		remaining := max(current-damage, 0)
+		result.HPChange = remaining - current
+		current = remaining
+	}
+
+	c.CurrentHP = &current
+	return result
+}
+
+// apply works out the damage a character with these defenses takes and
+// which defenses changed it. Immunity prevents it; otherwise resistance
+// halves it, rounding down, and then vulnerability doubles it.
+func (d Defenses) apply(damageType string, amount int) ([]string, int) {
+	modifiers := []string{}
+	if damageType == "" {
+		return modifiers, amount
+	}
+	if slices.Contains(d.Immunities, damageType) {
+		return append(modifiers, DamageImmune), 0
+	}
+	if slices.Contains(d.Resistances, damageType) {
+		modifiers = append(modifiers, DamageResisted)
+		amount /= 2
+	}
+	if slices.Contains(d.Vulnerabilities, damageType) {
+		modifiers = append(modifiers, DamageVulnerable)
+		amount *= 2
+	}
+	return modifiers, amount
+}
+
+// clone returns a copy of the defenses with every list non-nil
+func (d Defenses) clone() Defenses {
+	return Defenses{
+		Resistances:     append([]string{}, d.Resistances...),
+		Vulnerabilities: append([]string{}, d.Vulnerabilities...),
+		Immunities:      append([]string{}, d.Immunities...),
+	}
+}
+
+// validate checks the damage types of a hit point request
+func (r *HPRequest) validate() error {
+	fields := map[string]string{}
+	for i, op := range r.Operations {
+		path := fmt.Sprintf("operations[%d].damage_type", i)
+		switch {
+		case op.DamageType == "":
+		case op.Type != HPDamage:
+			fields[path] = "is only allowed on damage"
+		case !slices.Contains(DamageTypes, op.DamageType):
+			fields[path] = "must be a damage type"
+		}
+	}
+	if len(fields) > 0 {
+		return apperror.Validation("invalid_operations", "Some hit point operations are invalid", fields)
+	}
+	return nil
+}
+
+// checkDefenses validates a character's damage defenses, sorting them and
+// dropping duplicates
+func checkDefenses(d *Defenses) error {
+	fields := map[string]string{}
+	lists := []struct {
+		field string
+		types *[]string
+	}{
+		{"defenses.resistances", &d.Resistances},
+		{"defenses.vulnerabilities", &d.Vulnerabilities},
+		{"defenses.immunities", &d.Immunities},
+	}
+	for _, list := range lists {
+		if *list.types == nil {
+			*list.types = []string{}
+		}
+		for _, damageType := range *list.types {
+			if !slices.Contains(DamageTypes, damageType) {
+				fields[list.field] = fmt.Sprintf("must only list damage types, not %q", damageType)
+			}
+		}
+		slices.Sort(*list.types)
+		*list.types = slices.Compact(*list.types)
+	}
+	if len(fields) > 0 {
+		return apperror.Validation("invalid_defenses", "The character's damage defenses are invalid", fields)
+	}
+	return nil
+}
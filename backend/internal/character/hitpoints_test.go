@@ -0,0 +1,125 @@
+package character
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+func damageOp(amount int) HPOperation {
+	return HPOperation{Type: HPDamage, Amount: amount}
+}
+
+func TestChangeHitPoints(t *testing.T) {
+	tests := []struct {
+		name       string
+		ops        []HPOperation
+		current    int
+		temp       int
+		deathSaves DeathSaves
+		status     string
+	}{
+		{"damage", []HPOperation{damageOp(10)}, 20, 0, DeathSaves{}, StatusConscious},
+		{"healing stops at the maximum", []HPOperation{damageOp(10), {Type: HPHeal, Amount: 25}}, 30, 0, DeathSaves{}, StatusConscious},
+		{"temporary hit points absorb damage first", []HPOperation{{Type: HPTemp, Amount: 5}, damageOp(8)}, 27, 0, DeathSaves{}, StatusConscious},
+		{"temporary hit points don't stack", []HPOperation{{Type: HPTemp, Amount: 5}, {Type: HPTemp, Amount: 3}}, 30, 5, DeathSaves{}, StatusConscious},
+		{"resistance rounds down", []HPOperation{{Type: HPDamage, Amount: 11, DamageType: "fire"}}, 25, 0, DeathSaves{}, StatusConscious},
+		{"vulnerability doubles", []HPOperation{{Type: HPDamage, Amount: 4, DamageType: "cold"}}, 22, 0, DeathSaves{}, StatusConscious},
+		{"immunity prevents", []HPOperation{{Type: HPDamage, Amount: 50, DamageType: "poison"}}, 30, 0, DeathSaves{}, StatusConscious},
+		{"dropping to 0", []HPOperation{damageOp(35)}, 0, 0, DeathSaves{}, StatusDying},
+		{"massive damage kills", []HPOperation{damageOp(60)}, 0, 0, DeathSaves{Dead: true}, StatusDead},
+		{"damage at 0 fails a death save", []HPOperation{damageOp(30), damageOp(1)}, 0, 0, DeathSaves{Failures: 1}, StatusDying},
+		{"a critical hit at 0 fails two", []HPOperation{damageOp(30), {Type: HPDamage, Amount: 1, Critical: true}}, 0, 0, DeathSaves{Failures: 2}, StatusDying},
+		{"healing at 0 clears death saves", []HPOperation{damageOp(30), damageOp(1), {Type: HPHeal, Amount: 5}}, 5, 0, DeathSaves{}, StatusConscious},
+		{"nothing happens after death", []HPOperation{damageOp(60), {Type: HPHeal, Amount: 10}}, 0, 0, DeathSaves{Dead: true}, StatusDead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			c, userID := createTestCharacter(t, s, func(req *CreateCharacterRequest) {
+				req.Defenses = &Defenses{Resistances: []string{"fire"}, Vulnerabilities: []string{"cold"}, Immunities: []string{"poison"}}
+			})
+			hp := changeHP(t, s, c, userID, tt.ops...)
+			if hp.CurrentHP != tt.current || hp.TempHP != tt.temp {
+				t.Errorf("hit points = %d with %d temporary, want %d with %d", hp.CurrentHP, hp.TempHP, tt.current, tt.temp)
+			}
+			if hp.DeathSaves != tt.deathSaves || hp.Status != tt.status {
+				t.Errorf("death saves = %+v (%s), want %+v (%s)", hp.DeathSaves, hp.Status, tt.deathSaves, tt.status)
+			}
+			if len(hp.Applied) != len(tt.ops) {
+				t.Errorf("got %d applied operations, want %d", len(hp.Applied), len(tt.ops))
+			}
+
+			stored, err := s.GetCharacterByID(c.ID.String(), userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *stored.CurrentHP != tt.current || stored.Version != c.Version+1 {
+				t.Errorf("stored %d hit points at version %d, want %d at version %d", *stored.CurrentHP, stored.Version, tt.current, c.Version+1)
+			}
+		})
+	}
+}
+
+func TestChangeHitPointsApplied(t *testing.T) {
+	s := newTestService(t)
+	c, userID := createTestCharacter(t, s, func(req *CreateCharacterRequest) {
+		req.Defenses = &Defenses{Resistances: []string{"fire"}, Vulnerabilities: []string{"fire"}}
+	})
+	hp := changeHP(t, s, c, userID,
+		HPOperation{Type: HPTemp, Amount: 4},
+		HPOperation{Type: HPDamage, Amount: 7, DamageType: "fire"},
+	)
+
+	// Resistance halves 7 to 3 before vulnerability doubles it to 6, of
+	// which the 4 temporary hit points take the first 4
+	want := &AppliedHP{
+		HPOperation: HPOperation{Type: HPDamage, Amount: 7, DamageType: "fire"},
+		Modifiers:   []string{DamageResisted, DamageVulnerable},
+		Effective:   6,
+		Absorbed:    4,
+		HPChange:    -2,
+	}
+	if got := hp.Applied[1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("applied %+v, want %+v", got, want)
+	}
+	if hp.CurrentHP != 28 || hp.TempHP != 0 || hp.MaxHP != 30 {
+		t.Errorf("hit points = %d/%d with %d temporary, want 28/30 with 0", hp.CurrentHP, hp.MaxHP, hp.TempHP)
+	}
+}
+
+func TestChangeHitPointsRejected(t *testing.T) {
+	s := newTestService(t)
+	c, userID := createTestCharacter(t, s, nil)
+
+	invalid := []HPOperation{
+		{Type: HPHeal, Amount: 5, DamageType: "fire"},
+		{Type: HPDamage, Amount: 5, DamageType: "sonic"},
+	}
+	for _, op := range invalid {
+		_, err := s.ChangeHitPoints(c.ID.String(), userID, &HPRequest{Operations: []HPOperation{op}})
+		var appErr *apperror.Error
+		if !errors.As(err, &appErr) || appErr.Code != "invalid_operations" {
+			t.Errorf("%+v returned %v, want invalid_operations", op, err)
+		}
+	}
+
+	changeHP(t, s, c, userID, damageOp(60))
+	if _, err := s.ChangeHitPoints(c.ID.String(), userID, &HPRequest{Operations: []HPOperation{{Type: HPHeal, Amount: 5}}}); !errors.Is(err, ErrCharacterDead) {
+		t.Errorf("healing a dead character returned %v, want ErrCharacterDead", err)
+	}
+
+	noMax, otherUser := createTestCharacter(t, s, func(req *CreateCharacterRequest) {
+		req.MaxHP = nil
+		req.CurrentHP = nil
+	})
+	if _, err := s.ChangeHitPoints(noMax.ID.String(), otherUser, &HPRequest{Operations: []HPOperation{damageOp(1)}}); !errors.Is(err, ErrMaxHPRequired) {
+		t.Errorf("damaging a character without maximum hit points returned %v, want ErrMaxHPRequired", err)
+	}
+	if _, err := s.ChangeHitPoints(noMax.ID.String(), userID, &HPRequest{Operations: []HPOperation{damageOp(1)}}); !errors.Is(err, ErrCharacterNotFound) {
+		t.Errorf("damaging another user's character returned %v, want ErrCharacterNotFound", err)
+	}
+}
@@ -8,59 +8,117 @@ import (
 
 // Character represents a D&D character
 type Character struct {
-	ID           uuid.UUID  `json:"id" db:"id"`
-	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
-	Name         string     `json:"name" db:"name"`
-	Race         string     `json:"race" db:"race"`
-	Class        string     `json:"class" db:"class"`
-	Level        int        `json:"level" db:"level"`
-	Background   string     `json:"background" db:"background"`
-	
+	ID     uuid.UUID `json:"id" db:"id"`
+	UserID uuid.UUID `json:"user_id" db:"user_id"`
+	Name   string    `json:"name" db:"name"`
+	Race   string    `json:"race" db:"race"`
+	// Classes lists the classes the character has levels in, the one it
+	// started with first
+	Classes []ClassEntry `json:"classes" db:"classes"`
+	// Level is the character's total level, the sum of its class levels
+	Level      int    `json:"level" db:"level"`
+	Background string `json:"background" db:"background"`
+
 	// Ability scores
-	Strength     int        `json:"strength" db:"strength"`
-	Dexterity    int        `json:"dexterity" db:"dexterity"`
-	Constitution int        `json:"constitution" db:"constitution"`
-	Intelligence int        `json:"intelligence" db:"intelligence"`
-	Wisdom       int        `json:"wisdom" db:"wisdom"`
-	Charisma     int        `json:"charisma" db:"charisma"`
-	
+	Strength     int `json:"strength" db:"strength"`
+	Dexterity    int `json:"dexterity" db:"dexterity"`
+	Constitution int `json:"constitution" db:"constitution"`
+	Intelligence int `json:"intelligence" db:"intelligence"`
+	Wisdom       int `json:"wisdom" db:"wisdom"`
+	Charisma     int `json:"charisma" db:"charisma"`
+
 	// Combat stats
-	MaxHP        *int       `json:"max_hp" db:"max_hp"`
-	CurrentHP    *int       `json:"current_hp" db:"current_hp"`
-	ArmorClass   *int       `json:"armor_class" db:"armor_class"`
-	
+	MaxHP     *int `json:"max_hp" db:"max_hp"`
+	CurrentHP *int `json:"current_hp" db:"current_hp"`
+	// TempHP are temporary hit points, lost before current hit points
+	TempHP int `json:"temp_hp" db:"temp_hp"`
+	// Defenses change the damage taken of some damage types
+	Defenses Defenses `json:"defenses" db:"defenses"`
+	// DeathSaves track the character while at 0 hit points
+	DeathSaves DeathSaves `json:"death_saves" db:"death_saves"`
+	// Conditions and Exhaustion (0 to 6) affect the character until
+	// removed; their effects are part of the derived stats
+	Conditions []Condition `json:"conditions" db:"conditions"`
+	Exhaustion int         `json:"exhaustion" db:"exhaustion"`
+	// Speed is the character's walking speed in feet before conditions
+	Speed int `json:"speed" db:"speed"`
+	// ArmorClass overrides the AC computed from equipment when set
+	ArmorClass *int `json:"armor_class" db:"armor_class"`
+
+	// Equipment slots and bonuses the computed AC is based on
+	Armor     *Armor    `json:"armor" db:"armor"`
+	Shield    *Shield   `json:"shield" db:"shield"`
+	ACBonuses []ACBonus `json:"ac_bonuses" db:"ac_bonuses"`
+
+	// Spell slots used since they were last restored. SpellSlotsExpended has
+	// one entry per slot level, 1st level first.
+	SpellSlotsExpended []int `json:"spell_slots_expended" db:"spell_slots_expended"`
+	PactSlotsExpended  int   `json:"pact_slots_expended" db:"pact_slots_expended"`
+
+	// Feats taken instead of Ability Score Improvements
+	Feats []string `json:"feats" db:"feats"`
+
+	// Resources are limited-use features with their remaining uses
+	Resources []Resource `json:"resources" db:"resources"`
+
 	// Additional fields
-	Notes        *string    `json:"notes" db:"notes"`
-	
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	Notes *string `json:"notes" db:"notes"`
+
+	// Skill and saving throw training
+	Proficiencies   []Proficiency `json:"proficiencies" db:"-"`
+	JackOfAllTrades bool          `json:"jack_of_all_trades" db:"jack_of_all_trades"`
+
+	// Incremented on every write, used for optimistic concurrency
+	Version int `json:"version" db:"version"`
+
+	// Computed by the rules engine, never stored
+	Derived *DerivedStats `json:"derived,omitempty" db:"-"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // CreateCharacterRequest represents a character creation request
 type CreateCharacterRequest struct {
-	Name         string `json:"name" binding:"required"`
-	Race         string `json:"race" binding:"required"`
-	Class        string `json:"class" binding:"required"`
-	Level        int    `json:"level" binding:"required,min=1,max=20"`
-	Background   string `json:"background" binding:"required"`
-	Strength     int    `json:"strength" binding:"required,min=1,max=20"`
-	Dexterity    int    `json:"dexterity" binding:"required,min=1,max=20"`
-	Constitution int    `json:"constitution" binding:"required,min=1,max=20"`
-	Intelligence int    `json:"intelligence" binding:"required,min=1,max=20"`
-	Wisdom       int    `json:"wisdom" binding:"required,min=1,max=20"`
-	Charisma     int    `json:"charisma" binding:"required,min=1,max=20"`
-	MaxHP        *int   `json:"max_hp"`
-	CurrentHP    *int   `json:"current_hp"`
-	ArmorClass   *int   `json:"armor_class"`
-	Notes        *string `json:"notes"`
+	Name    string       `json:"name" binding:"required"`
+	Race    string       `json:"race" binding:"required"`
+	Classes []ClassEntry `json:"classes" binding:"max=20,dive"`
+	// Class, Subclass and Level describe a single-class character and may
+	// be sent instead of Classes
+	Class           string        `json:"class" binding:"max=100"`
+	Subclass        string        `json:"subclass" binding:"max=100"`
+	Level           int           `json:"level" binding:"omitempty,min=1,max=20"`
+	Background      string        `json:"background" binding:"required"`
+	Strength        int           `json:"strength" binding:"required,min=1,max=20"`
+	Dexterity       int           `json:"dexterity" binding:"required,min=1,max=20"`
+	Constitution    int           `json:"constitution" binding:"required,min=1,max=20"`
+	Intelligence    int           `json:"intelligence" binding:"required,min=1,max=20"`
+	Wisdom          int           `json:"wisdom" binding:"required,min=1,max=20"`
+	Charisma        int           `json:"charisma" binding:"required,min=1,max=20"`
+	MaxHP           *int          `json:"max_hp"`
+	CurrentHP       *int          `json:"current_hp"`
+	Defenses        *Defenses     `json:"defenses"`
+	Speed           *int          `json:"speed" binding:"omitempty,min=0,max=1000"`
+	ArmorClass      *int          `json:"armor_class"`
+	Armor           *Armor        `json:"armor"`
+	Shield          *Shield       `json:"shield"`
+	ACBonuses       []ACBonus     `json:"ac_bonuses" binding:"max=20,dive"`
+	Notes           *string       `json:"notes"`
+	Proficiencies   []Proficiency `json:"proficiencies"`
+	JackOfAllTrades bool          `json:"jack_of_all_trades"`
 }
 
 // UpdateCharacterRequest represents a character update request
 type UpdateCharacterRequest struct {
-	Name         *string `json:"name"`
-	Race         *string `json:"race"`
-	Class        *string `json:"class"`
-	Level        *int    `json:"level"`
+	Name *string `json:"name"`
+	Race *string `json:"race"`
+	// Classes replaces the full list of classes when provided
+	Classes *[]ClassEntry `json:"classes" binding:"omitempty,max=20,dive"`
+	// Class, Subclass and Level change a single-class character's only
+	// class entry
+	Class        *string `json:"class" binding:"omitempty,max=100"`
+	Subclass     *string `json:"subclass" binding:"omitempty,max=100"`
+	Level        *int    `json:"level" binding:"omitempty,min=1,max=20"`
 	Background   *string `json:"background"`
 	Strength     *int    `json:"strength"`
 	Dexterity    *int    `json:"dexterity"`
@@ -70,6 +128,21 @@ type UpdateCharacterRequest struct {
 	Charisma     *int    `json:"charisma"`
 	MaxHP        *int    `json:"max_hp"`
 	CurrentHP    *int    `json:"current_hp"`
+	TempHP       *int    `json:"temp_hp" binding:"omitempty,min=0"`
+	Speed        *int    `json:"speed" binding:"omitempty,min=0,max=1000"`
 	ArmorClass   *int    `json:"armor_class"`
-	Notes        *string `json:"notes"`
-} 
\ No newline at end of file
+	Armor        *Armor  `json:"armor"`
+	Shield       *Shield `json:"shield"`
+	// ACBonuses replaces the full list of AC bonuses when provided
+	ACBonuses *[]ACBonus `json:"ac_bonuses" binding:"omitempty,max=20,dive"`
+	Defenses  *Defenses  `json:"defenses"`
+	Notes     *string    `json:"notes"`
+	// Proficiencies replaces the full set of proficiencies when provided
+	Proficiencies   *[]Proficiency `json:"proficiencies"`
+	JackOfAllTrades *bool          `json:"jack_of_all_trades"`
+	// Clear resets the listed optional fields: taking off the armor or
+	// shield, or dropping the armor_class override
+	Clear []string `json:"clear" binding:"omitempty,dive,oneof=armor shield armor_class"`
+	// Version is the version being edited, if not sent in If-Match
+	Version *int `json:"version"`
+}
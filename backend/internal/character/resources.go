@@ -0,0 +1,473 @@
+package character
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// When a resource recharges
+const (
+	RechargeShort = "short"
+	RechargeLong  = "long"
+	RechargeDawn  = "dawn"
+	RechargeNone  = "none"
+)
+
+// Revision actions recorded for resources
+const (
+	RevisionAddResource     = "add_resource"
+	RevisionUpdateResource  = "update_resource"
+	RevisionRemoveResource  = "remove_resource"
+	RevisionUseResource     = "use_resource"
+	RevisionRestoreResource = "restore_resource"
+	RevisionRecharge        = "recharge"
+)
+
+var (
+	// ErrResourceNotFound is returned when a character has no resource with
+	// the given ID
+	ErrResourceNotFound = apperror.NotFound("resource_not_found", "Resource not found")
+	// ErrResourceExists is returned when a character already has a resource
+	// with the same name
+	ErrResourceExists = apperror.Conflict("resource_exists", "The character already has a resource with that name")
+	// ErrResourceExhausted is returned when using more of a resource than
+	// is left
+	ErrResourceExhausted = apperror.Conflict("resource_exhausted", "Not enough of the resource is left")
+)
+
+// Resource is a limited-use feature of a character, such as Bardic
+// Inspiration or Channel Divinity. Max is a formula evaluated against the
+// character, like "proficiency" or "1 + CHA mod", and never below Minimum.
+type Resource struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Max      string    `json:"max"`
+	Minimum  int       `json:"minimum"`
+	Current  int       `json:"current"`
+	Recharge string    `json:"recharge"`
+}
+
+// CreateResourceRequest represents adding a resource to a character. The
+// resource starts full unless Current is given.
+type CreateResourceRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Max      string `json:"max" binding:"required,max=100"`
+	Minimum  int    `json:"minimum" binding:"min=0,max=1000"`
+	Current  *int   `json:"current" binding:"omitempty,min=0"`
+	Recharge string `json:"recharge" binding:"required,oneof=short long dawn none"`
+}
+
+// UpdateResourceRequest represents a change to a resource
+type UpdateResourceRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Max      *string `json:"max" binding:"omitempty,min=1,max=100"`
+	Minimum  *int    `json:"minimum" binding:"omitempty,min=0,max=1000"`
+	Current  *int    `json:"current" binding:"omitempty,min=0"`
+	Recharge *string `json:"recharge" binding:"omitempty,oneof=short long dawn none"`
+}
+
+// ResourceAmountRequest represents using or restoring a resource, one use
+// unless Amount is given
+type ResourceAmountRequest struct {
+	Amount int `json:"amount" binding:"omitempty,min=1,max=1000"`
+}
+
+// RechargeRequest represents recharging every resource of a recharge type
+type RechargeRequest struct {
+	Recharge string `json:"recharge" binding:"required,oneof=short long dawn"`
+}
+
+// ResourceRecharged is how much of a resource a recharge restored
+type ResourceRecharged struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Restored int       `json:"restored"`
+}
+
+// RechargeResult summarizes a recharge
+type RechargeResult struct {
+	Recharged []ResourceRecharged `json:"recharged"`
+	Character *Character          `json:"character"`
+}
+
+// ResourceMax evaluates the maximum of one of the character's resources
+func (c *Character) ResourceMax(r *Resource) int {
+	// Formulas were checked when saved, so any class term evaluates, to 0
+	// for a class the character has since left
+	value, err := evalFormula(r.Max, c, nil)
+	if err != nil {
+		return r.Minimum
+	}
+	return max(value, r.Minimum)
+}
+
+// AddResource adds a resource to a character the user owns
+func (s *Service) AddResource(characterID, userID string, req *CreateResourceRequest) (*Character, error) {
+	return s.Apply(characterID, userID, AccessOwner, RevisionAddResource, func(c *Character) error {
+		if strings.TrimSpace(req.Name) == "" {
+			return apperror.Field("name", "is required")
+		}
+		if c.resourceNamed(req.Name, uuid.Nil) {
+			return ErrResourceExists
+		}
+		if err := s.checkFormula(req.Max, c); err != nil {
+			return err
+		}
+
+		r := Resource{
+			ID:       uuid.New(),
+			Name:     strings.TrimSpace(req.Name),
+			Max:      strings.TrimSpace(req.Max),
+			Minimum:  req.Minimum,
+			Recharge: req.Recharge,
+		}
+		r.Current = c.ResourceMax(&r)
+		if req.Current != nil {
+			r.Current = min(*req.Current, r.Current)
+		}
+		c.Resources = append(c.Resources, r)
+		return nil
+	})
+}
+
+// UpdateResource changes a resource of a character the user owns. Current
+// is capped at the resource's maximum.
+func (s *Service) UpdateResource(characterID, userID, resourceID string, req *UpdateResourceRequest) (*Character, error) {
+	return s.Apply(characterID, userID, AccessOwner, RevisionUpdateResource, func(c *Character) error {
+		r, err := c.resource(resourceID)
+		if err != nil {
+			return err
+		}
+		if req.Name != nil {
+			if strings.TrimSpace(*req.Name) == "" {
+				return apperror.Field("name", "must not be blank")
+			}
+			if c.resourceNamed(*req.Name, r.ID) {
+				return ErrResourceExists
+			}
+			r.Name = strings.TrimSpace(*req.Name)
+		}
+		if req.Max != nil {
+			if err := s.checkFormula(*req.Max, c); err != nil {
+				return err
+			}
+			r.Max = strings.TrimSpace(*req.Max)
+		}
+		if req.Minimum != nil {
+			r.Minimum = *req.Minimum
+		}
+		if req.Current != nil {
+			r.Current = *req.Current
+		}
+		if req.Recharge != nil {
+			r.Recharge = *req.Recharge
+		}
+		r.Current = min(r.Current, c.ResourceMax(r))
+		return nil
+	})
+}
+
+// RemoveResource removes a resource from a character the user owns
+func (s *Service) RemoveResource(characterID, userID, resourceID string) (*Character, error) {
+	return s.Apply(characterID, userID, AccessOwner, RevisionRemoveResource, func(c *Character) error {
+		r, err := c.resource(resourceID)
+		if err != nil {
+			return err
+		}
+		remaining := make([]Resource, 0, len(c.Resources)-1)
+		for _, other := range c.Resources {
+			if other.ID != r.ID {
+				remaining = append(remaining, other)
+			}
+		}
+		c.Resources = remaining
+		return nil
+	})
+}
+
+// UseResource decrements a resource, failing when not enough is left
+func (s *Service) UseResource(characterID, userID, resourceID string, req *ResourceAmountRequest) (*Character, error) {
+	amount := max(req.Amount, 1)
+	return s.Apply(characterID, userID, AccessOwner, RevisionUseResource, func(c *Character) error {
+		r, err := c.resource(resourceID)
+		if err != nil {
+			return err
+		}
+		current := min(r.Current, c.ResourceMax(r))
+		if amount > current {
+			return ErrResourceExhausted
+		}
+		r.Current = current - amount
+		return nil
+	})
+}
+
+// RestoreResource increments a resource, up to its maximum
+func (s *Service) RestoreResource(characterID, userID, resourceID string, req *ResourceAmountRequest) (*Character, error) {
+	amount := max(req.Amount, 1)
+	return s.Apply(characterID, userID, AccessOwner, RevisionRestoreResource, func(c *Character) error {
+		r, err := c.resource(resourceID)
+		if err != nil {
+			return err
+		}
+		r.Current = min(r.Current+amount, c.ResourceMax(r))
+		return nil
+	})
+}
+
+// Recharge refills every resource of a character that recharges the given
+// way
+func (s *Service) Recharge(characterID, userID string, req *RechargeRequest) (*RechargeResult, error) {
+	var recharged []ResourceRecharged
+	char, err := s.Apply(characterID, userID, AccessOwner, RevisionRecharge, func(c *Character) error {
+		recharged = c.rechargeResources(req.Recharge)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RechargeResult{Recharged: recharged, Character: char}, nil
+}
+
+// rechargeResources refills the resources that recharge one of the given
+// ways and reports what each regained
+func (c *Character) rechargeResources(recharges ...string) []ResourceRecharged {
+	recharged := []ResourceRecharged{}
+	for i := range c.Resources {
+		r := &c.Resources[i]
+		matches := false
+		for _, recharge := range recharges {
+			matches = matches || r.Recharge == recharge
+		}
+		if !matches {
+			continue
+		}
+		full := c.ResourceMax(r)
+		recharged = append(recharged, ResourceRecharged{ID: r.ID, Name: r.Name, Restored: max(full-r.Current, 0)})
+		r.Current = full
+	}
+	return recharged
+}
+
+// resource finds one of the character's resources by ID
+func (c *Character) resource(resourceID string) (*Resource, error) {
+	id, err := uuid.Parse(resourceID)
+	if err != nil {
+		return nil, ErrResourceNotFound
+	}
+	for i := range c.Resources {
+		if c.Resources[i].ID == id {
+			return &c.Resources[i], nil
+		}
+	}
+	return nil, ErrResourceNotFound
+}
+
+// resourceNamed reports whether a resource other than except has the name,
+// ignoring case
+func (c *Character) resourceNamed(name string, except uuid.UUID) bool {
+	name = strings.TrimSpace(name)
+	for _, r := range c.Resources {
+		if r.ID != except && strings.EqualFold(r.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// abilityNames maps the names and abbreviations formulas may use to the
+// abilities
+var abilityNames = map[string]string{
+	"str": Strength, "strength": Strength,
+	"dex": Dexterity, "dexterity": Dexterity,
+	"con": Constitution, "constitution": Constitution,
+	"int": Intelligence, "intelligence": Intelligence,
+	"wis": Wisdom, "wisdom": Wisdom,
+	"cha": Charisma, "charisma": Charisma,
+}
+
+// checkFormula checks a resource maximum formula being saved. Its class
+// terms must name one of the character's classes or a class in the SRD or
+// the character's homebrew packs.
+func (s *Service) checkFormula(formula string, c *Character) error {
+	var lookupErr error
+	knownClass := func(class string) bool {
+		if c.ClassLevel(class) > 0 {
+			return true
+		}
+		if s.classes == nil || lookupErr != nil {
+			return false
+		}
+		rules, err := s.classes.Class(c.ID.String(), class)
+		lookupErr = err
+		return rules != nil
+	}
+
+	_, err := evalFormula(formula, c, knownClass)
+	if lookupErr != nil {
+		return lookupErr
+	}
+	if err != nil {
+		return apperror.Field("max", err.Error())
+	}
+	return nil
+}
+
+// evalFormula evaluates a resource maximum formula for a character.
+// Formulas add, subtract, multiply and divide (rounding down) whole numbers
+// and these terms:
+//
+//   - proficiency (or prof, pb): the proficiency bonus
+//   - level: the character's total level
+//   - <class> level, such as "monk level": the levels in that class, for
+//     classes knownClass accepts or any class when it is nil
+//   - an ability with mod or score, such as "CHA mod"; an ability alone is
+//     its modifier
+func evalFormula(formula string, c *Character, knownClass func(class string) bool) (int, error) {
+	p := &formulaParser{tokens: tokenize(formula), c: c, knownClass: knownClass}
+	if len(p.tokens) == 0 {
+		return 0, errors.New("must not be empty")
+	}
+	value, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(p.tokens) {
+		return 0, fmt.Errorf("has an unexpected %q", p.tokens[p.pos])
+	}
+	return value, nil
+}
+
+// tokenize splits a formula into lowercase words, numbers and operators
+func tokenize(formula string) []string {
+	formula = strings.ToLower(formula)
+	for _, op := range []string{"+", "-", "*", "/", "(", ")"} {
+		formula = strings.ReplaceAll(formula, op, " "+op+" ")
+	}
+	return strings.Fields(formula)
+}
+
+// formulaParser evaluates formula tokens by recursive descent
+type formulaParser struct {
+	tokens     []string
+	pos        int
+	c          *Character
+	knownClass func(class string) bool
+}
+
+func (p *formulaParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *formulaParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+// expr is a sum of terms
+func (p *formulaParser) expr() (int, error) {
+	value, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for p.peek() == "+" || p.peek() == "-" {
+		op := p.next()
+		right, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == "+" {
+			value += right
+		} else {
+			value -= right
+		}
+	}
+	return value, nil
+}
+
+// term is a product of factors
+func (p *formulaParser) term() (int, error) {
+	value, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+	for p.peek() == "*" || p.peek() == "/" {
+		op := p.next()
+		right, err := p.factor()
+		if err != nil {
+			return 0, err
+		}
+		if op == "*" {
+			value *= right
+			continue
+		}
+		if right == 0 {
+			return 0, errors.New("divides by zero")
+		}
+		value = floorDiv(value, right)
+	}
+	return value, nil
+}
+
+// factor is a number, a named value or a parenthesized expression
+func (p *formulaParser) factor() (int, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return 0, errors.New("ends too early")
+	case token == "(":
+		value, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		if p.next() != ")" {
+			return 0, errors.New("is missing a closing parenthesis")
+		}
+		return value, nil
+	case token == "-":
+		value, err := p.factor()
+		return -value, err
+	case token == "proficiency" || token == "prof" || token == "pb":
+		return ProficiencyBonus(p.c.Level), nil
+	case token == "level":
+		return p.c.Level, nil
+	}
+
+	if n, err := strconv.Atoi(token); err == nil {
+		return n, nil
+	}
+	if ability, ok := abilityNames[token]; ok {
+		switch p.peek() {
+		case "score":
+			p.next()
+			return p.c.Score(ability), nil
+		case "mod", "modifier":
+			p.next()
+		}
+		return AbilityModifier(p.c.Score(ability)), nil
+	}
+	if p.peek() == "level" && (p.knownClass == nil || p.knownClass(token)) {
+		p.next()
+		return p.c.ClassLevel(token), nil
+	}
+	return 0, fmt.Errorf("has an unknown term %q", token)
+}
+
+// floorDiv divides rounding down, as the rules do
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
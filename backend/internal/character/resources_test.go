@@ -0,0 +1,139 @@
+package character
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/content"
+	"character-sheet-backend/internal/dice"
+)
+
+// classRules knows the classes it maps by lower case name
+type classRules map[string]*content.Class
+
+func (r classRules) Class(characterID, class string) (*content.Class, error) {
+	return r[strings.ToLower(class)], nil
+}
+
+func TestEvalFormula(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    int
+	}{
+		{"3", 3},
+		{"proficiency", 3},
+		{"prof + 1", 4},
+		{"level", 5},
+		{"rogue level", 5},
+		{"ROGUE LEVEL", 5},
+		{"monk level", 0},
+		{"dex", 4},
+		{"STR mod", -1},
+		{"cha modifier", 0},
+		{"WIS score", 13},
+		{"1 + level / 2", 3},
+		{"(level + 1) / 2", 3},
+		{"-3 / 2", -2},
+		{"2 * (pb + 1) - con", 6},
+	}
+	for _, tt := range tests {
+		got, err := evalFormula(tt.formula, testCharacter(), nil)
+		if err != nil {
+			t.Errorf("evalFormula(%q) returned %v", tt.formula, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("evalFormula(%q) = %d, want %d", tt.formula, got, tt.want)
+		}
+	}
+}
+
+func TestEvalFormulaInvalid(t *testing.T) {
+	tests := []struct {
+		formula string
+		err     string
+	}{
+		{"", "must not be empty"},
+		{"1 +", "ends too early"},
+		{"(1 + 2", "is missing a closing parenthesis"},
+		{"level / (prof - 3)", "divides by zero"},
+		{"banana", `has an unknown term "banana"`},
+		{"2 3", `has an unexpected "3"`},
+	}
+	for _, tt := range tests {
+		_, err := evalFormula(tt.formula, testCharacter(), nil)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("evalFormula(%q) returned %v, want %q", tt.formula, err, tt.err)
+		}
+	}
+}
+
+func TestAddResourceClassLevels(t *testing.T) {
+	rules := classRules{"monk": {Index: "monk", Name: "Monk", HitDie: 8}}
+	s := NewService(NewMemoryStore(), nil, dice.NewSeededRoller(1), rules)
+	c, userID := createTestCharacter(t, s, nil)
+
+	tests := []struct {
+		formula string
+		known   bool
+		max     int
+	}{
+		{"fighter level", true, 5},
+		// Known to the class rules but not one of the character's classes
+		{"monk level + 1", true, 1},
+		{"banana level", false, 0},
+		{"fighters level", false, 0},
+	}
+	for _, tt := range tests {
+		char, err := s.AddResource(c.ID.String(), userID, &CreateResourceRequest{Name: tt.formula, Max: tt.formula, Recharge: RechargeLong})
+		if !tt.known {
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || !strings.HasPrefix(appErr.Fields["max"], "has an unknown term") {
+				t.Errorf("%q returned %v, want an unknown term", tt.formula, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q returned %v", tt.formula, err)
+			continue
+		}
+		r := char.Resources[len(char.Resources)-1]
+		if got := char.Derived.ResourceMax[r.ID.String()]; got != tt.max || r.Current != tt.max {
+			t.Errorf("%q has maximum %d and %d uses, want %d", tt.formula, got, r.Current, tt.max)
+		}
+	}
+
+	// Without class rules only the character's own classes are known
+	s = newTestService(t)
+	c, userID = createTestCharacter(t, s, nil)
+	if _, err := s.AddResource(c.ID.String(), userID, &CreateResourceRequest{Name: "Ki", Max: "monk level", Recharge: RechargeShort}); !errors.Is(err, apperror.ErrValidation) {
+		t.Errorf("a class the character doesn't have returned %v, want a validation error", err)
+	}
+}
+
+func TestUpdateResourceFormula(t *testing.T) {
+	s := newTestService(t)
+	c, userID := createTestCharacter(t, s, nil)
+	char, err := s.AddResource(c.ID.String(), userID, &CreateResourceRequest{Name: "Second Wind", Max: "fighter level", Recharge: RechargeShort})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := char.Resources[0].ID.String()
+
+	badMax := "rogue level"
+	if _, err := s.UpdateResource(c.ID.String(), userID, id, &UpdateResourceRequest{Max: &badMax}); !errors.Is(err, apperror.ErrValidation) {
+		t.Errorf("updating to an unknown class returned %v, want a validation error", err)
+	}
+
+	// Lowering the maximum caps the uses left
+	newMax := "con"
+	char, err = s.UpdateResource(c.ID.String(), userID, id, &UpdateResourceRequest{Max: &newMax})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := char.Resources[0]; r.Max != "con" || r.Current != 2 {
+		t.Errorf("updated resource = %+v, want max con with 2 uses", r)
+	}
+}
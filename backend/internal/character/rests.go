@@ -0,0 +1,312 @@
+package character
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/dice"
+)
+
+// Kinds of rest
+const (
+	RestShort = "short"
+	RestLong  = "long"
+)
+
+// Revision actions recorded for rests
+const (
+	RevisionShortRest = "short_rest"
+	RevisionLongRest  = "long_rest"
+)
+
+var (
+	// ErrCannotRest is returned when a dying or dead character rests
+	ErrCannotRest = apperror.Conflict("cannot_rest", "A dying or dead character can't rest")
+	// ErrLongRestAtZero is returned when a character at 0 hit points takes a
+	// long rest, which needs at least 1 hit point to start
+	ErrLongRestAtZero = apperror.Conflict("long_rest_at_zero_hp", "A character needs at least 1 hit point to benefit from a long rest")
+)
+
+// HitDicePool is a character's hit dice of one size
+type HitDicePool struct {
+	Die       int `json:"die"`
+	Total     int `json:"total"`
+	Remaining int `json:"remaining"`
+}
+
+// HitDiceSpend names how many hit dice of a class to spend. Class may be
+// left out for a single-class character. Rolls records dice rolled at the
+// table; without them the server rolls.
+type HitDiceSpend struct {
+	Class string `json:"class" binding:"max=100"`
+	Count int    `json:"count" binding:"required,min=1,max=20"`
+	Rolls []int  `json:"rolls" binding:"omitempty,max=20,dive,min=1,max=12"`
+}
+
+// ShortRestRequest represents a short rest and the hit dice spent on it
+type ShortRestRequest struct {
+	HitDice []HitDiceSpend `json:"hit_dice" binding:"max=20,dive"`
+}
+
+// HitDieRoll is one hit die spent during a short rest
+type HitDieRoll struct {
+	Class    string `json:"class"`
+	Die      int    `json:"die"`
+	Roll     int    `json:"roll"`
+	Modifier int    `json:"modifier"`
+	// HP is how much the die healed, after the hit point maximum capped it
+	HP int `json:"hp"`
+}
+
+// HitDiceRegained is how many hit dice of a class a long rest restored
+type HitDiceRegained struct {
+	Class string `json:"class"`
+	Die   int    `json:"die"`
+	Count int    `json:"count"`
+}
+
+// RestResult summarizes what a rest restored
+type RestResult struct {
+	Rest               string            `json:"rest"`
+	HitDiceRolled      []HitDieRoll      `json:"hit_dice_rolled"`
+	HitDiceRegained    []HitDiceRegained `json:"hit_dice_regained"`
+	HPRestored         int               `json:"hp_restored"`
+	TempHPLost         int               `json:"temp_hp_lost"`
+	SpellSlotsRestored []int             `json:"spell_slots_restored"`
+	PactSlotsRestored  int               `json:"pact_slots_restored"`
+	ExhaustionRemoved  int               `json:"exhaustion_removed"`
+	// ResourcesRecharged are the resources the rest refilled
+	ResourcesRecharged []ResourceRecharged `json:"resources_recharged"`
+	Character          *Character          `json:"character"`
+}
+
+// HitDice groups a character's hit dice by size, largest first
+func (c *Character) HitDice() []HitDicePool {
+	pools := []HitDicePool{}
+	for _, entry := range c.Classes {
+		i := slices.IndexFunc(pools, func(pool HitDicePool) bool { return pool.Die == entry.HitDie })
+		if i < 0 {
+			pools = append(pools, HitDicePool{Die: entry.HitDie})
+			i = len(pools) - 1
+		}
+		pools[i].Total += entry.Levels
+		pools[i].Remaining += entry.Levels - min(entry.HitDiceSpent, entry.Levels)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Die > pools[j].Die })
+	return pools
+}
+
+// ShortRest spends hit dice to heal, each healing its roll plus the
+// character's Constitution modifier, and restores pact magic slots and
+// short rest resources. The rest is applied in one locked write.
+func (s *Service) ShortRest(characterID, userID string, req *ShortRestRequest) (*RestResult, error) {
+	result := &RestResult{Rest: RestShort, HitDiceRolled: []HitDieRoll{}, HitDiceRegained: []HitDiceRegained{}, SpellSlotsRestored: []int{}}
+	char, err := s.Apply(characterID, userID, AccessOwner, RevisionShortRest, func(c *Character) error {
+		if status := c.Status(); status == StatusDying || status == StatusDead {
+			return ErrCannotRest
+		}
+		if len(req.HitDice) > 0 && c.MaxHP == nil {
+			return ErrMaxHPRequired
+		}
+
+		spent, err := c.spendHitDice(req.HitDice)
+		if err != nil {
+			return err
+		}
+		if err := s.rollHitDice(c, spent, result); err != nil {
+			return err
+		}
+
+		result.PactSlotsRestored = c.PactSlotsExpended
+		c.PactSlotsExpended = 0
+		result.ResourcesRecharged = c.rechargeResources(RechargeShort)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Character = char
+	return result, nil
+}
+
+// LongRest restores a character's hit points, spell slots, short and long
+// rest resources and half their hit dice, ends their temporary hit points and
+// lowers their exhaustion by one level, all in one locked write
+func (s *Service) LongRest(characterID, userID string) (*RestResult, error) {
+	result := &RestResult{Rest: RestLong, HitDiceRolled: []HitDieRoll{}, HitDiceRegained: []HitDiceRegained{}}
+	char, err := s.Apply(characterID, userID, AccessOwner, RevisionLongRest, func(c *Character) error {
+		if status := c.Status(); status == StatusDying || status == StatusDead {
+			return ErrCannotRest
+		}
+		if c.CurrentHP != nil && *c.CurrentHP <= 0 {
+			return ErrLongRestAtZero
+		}
+
+		// Exhaustion drops first so the restored hit points reach a maximum
+		// it no longer halves
+		if c.Exhaustion > 0 {
+			result.ExhaustionRemoved = 1
+			c.setExhaustion(c.Exhaustion - 1)
+		}
+		if c.MaxHP != nil {
+			current := c.HPMax()
+			if c.CurrentHP != nil {
+				current = min(*c.CurrentHP, current)
+			}
+			result.HPRestored = c.HPMax() - current
+			hp := c.HPMax()
+			c.CurrentHP = &hp
+		}
+		result.TempHPLost = c.TempHP
+		c.TempHP = 0
+		c.DeathSaves = DeathSaves{}
+
+		result.HitDiceRegained = c.regainHitDice()
+		result.SpellSlotsRestored = append([]int{}, c.SpellSlotsExpended...)
+		result.PactSlotsRestored = c.PactSlotsExpended
+		c.SpellSlotsExpended = []int{}
+		c.PactSlotsExpended = 0
+		// Features that recharge on a short rest recharge on a long one too
+		result.ResourcesRecharged = c.rechargeResources(RechargeShort, RechargeLong)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Character = char
+	return result, nil
+}
+
+// spentDie is one hit die spent from a class entry, with its roll when
+// recorded at the table
+type spentDie struct {
+	entry *ClassEntry
+	roll  int
+}
+
+// spendHitDice marks the requested hit dice as spent and returns them in
+// order
+func (c *Character) spendHitDice(spends []HitDiceSpend) ([]spentDie, error) {
+	fields := map[string]string{}
+	var spent []spentDie
+	for i, spend := range spends {
+		path := fmt.Sprintf("hit_dice[%d]", i)
+		entry, err := c.hitDiceEntry(spend.Class)
+		if err != nil {
+			fields[path+".class"] = err.Error()
+			continue
+		}
+		if remaining := entry.Levels - entry.HitDiceSpent; spend.Count > remaining {
+			fields[path+".count"] = fmt.Sprintf("must be at most the %d %s hit dice remaining", max(remaining, 0), entry.Class)
+			continue
+		}
+		if spend.Rolls != nil {
+			if len(spend.Rolls) != spend.Count {
+				fields[path+".rolls"] = "must have one roll per hit die"
+				continue
+			}
+			for _, roll := range spend.Rolls {
+				if roll > entry.HitDie {
+					fields[path+".rolls"] = fmt.Sprintf("must be rolls of a d%d", entry.HitDie)
+				}
+			}
+		}
+
+		entry.HitDiceSpent += spend.Count
+		for n := range spend.Count {
+			die := spentDie{entry: entry}
+			if spend.Rolls != nil {
+				die.roll = spend.Rolls[n]
+			}
+			spent = append(spent, die)
+		}
+	}
+	if len(fields) > 0 {
+		return nil, apperror.Validation("invalid_hit_dice", "Some hit dice can't be spent", fields)
+	}
+	return spent, nil
+}
+
+// rollHitDice rolls the spent hit dice, or takes the recorded rolls, and
+// heals the character by each in turn. Characters without maximum hit
+// points can only rest without spending any.
+func (s *Service) rollHitDice(c *Character, spent []spentDie, result *RestResult) error {
+	if len(spent) == 0 {
+		return nil
+	}
+
+	con := AbilityModifier(c.Constitution)
+	maxHP := c.HPMax()
+	current := maxHP
+	if c.CurrentHP != nil {
+		current = min(*c.CurrentHP, maxHP)
+	}
+	for _, die := range spent {
+		roll := HitDieRoll{Class: die.entry.Class, Die: die.entry.HitDie, Roll: die.roll, Modifier: con}
+		if roll.Roll == 0 {
+			rolled, err := s.roller.RollNotation(fmt.Sprintf("1d%d", roll.Die), dice.Normal)
+			if err != nil {
+				return err
+			}
+			roll.Roll = rolled.Total
+		}
+
+		healed := min(current+max(roll.Roll+con, 0), maxHP)
+		roll.HP = healed - current
+		current = healed
+		result.HPRestored += roll.HP
+		result.HitDiceRolled = append(result.HitDiceRolled, roll)
+	}
+
+	c.CurrentHP = &current
+	if current > 0 {
+		c.DeathSaves = DeathSaves{}
+	}
+	return nil
+}
+
+// regainHitDice restores spent hit dice up to half the character's total,
+// at least one, largest dice first
+func (c *Character) regainHitDice() []HitDiceRegained {
+	budget := max(c.Level/2, 1)
+	order := make([]*ClassEntry, 0, len(c.Classes))
+	for i := range c.Classes {
+		order = append(order, &c.Classes[i])
+	}
+	sort.SliceStable(order, func(i, j int) bool { return order[i].HitDie > order[j].HitDie })
+
+	regained := []HitDiceRegained{}
+	for _, entry := range order {
+		count := min(min(entry.HitDiceSpent, entry.Levels), budget)
+		entry.HitDiceSpent = min(entry.HitDiceSpent, entry.Levels) - count
+		if count == 0 {
+			continue
+		}
+		budget -= count
+		regained = append(regained, HitDiceRegained{Class: entry.Class, Die: entry.HitDie, Count: count})
+	}
+	return regained
+}
+
+// hitDiceEntry finds the class entry whose hit dice a spend names
+func (c *Character) hitDiceEntry(class string) (*ClassEntry, error) {
+	class = strings.TrimSpace(class)
+	if class == "" {
+		if len(c.Classes) != 1 {
+			return nil, errors.New("is required for a multiclass character")
+		}
+		return &c.Classes[0], nil
+	}
+	for i := range c.Classes {
+		if strings.EqualFold(c.Classes[i].Class, class) {
+			return &c.Classes[i], nil
+		}
+	}
+	return nil, errors.New("must be one of the character's classes")
+}
@@ -0,0 +1,221 @@
+package character
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// multiclass makes a test character a fighter 3 / wizard 2
+func multiclass(req *CreateCharacterRequest) {
+	req.Classes = []ClassEntry{
+		{Class: "Fighter", Levels: 3, HitDie: 10},
+		{Class: "Wizard", Levels: 2, HitDie: 6},
+	}
+}
+
+func TestHitDice(t *testing.T) {
+	c := &Character{Classes: []ClassEntry{
+		{Class: "Wizard", Levels: 1, HitDie: 6},
+		{Class: "Fighter", Levels: 3, HitDie: 10, HitDiceSpent: 2},
+		{Class: "Paladin", Levels: 2, HitDie: 10, HitDiceSpent: 5},
+	}}
+	want := []HitDicePool{{Die: 10, Total: 5, Remaining: 1}, {Die: 6, Total: 1, Remaining: 1}}
+	if got := c.HitDice(); !reflect.DeepEqual(got, want) {
+		t.Errorf("HitDice() = %+v, want %+v", got, want)
+	}
+}
+
+func TestShortRest(t *testing.T) {
+	s := newTestService(t, 7)
+	c, userID := createTestCharacter(t, s, nil)
+	changeHP(t, s, c, userID, damageOp(20))
+
+	// Two dice rolled at the table and one by the server, each adding the
+	// +2 Constitution modifier, healing 5 + 12 + 9 but only up to 30
+	result, err := s.ShortRest(c.ID.String(), userID, &ShortRestRequest{HitDice: []HitDiceSpend{
+		{Count: 2, Rolls: []int{3, 10}},
+		{Class: "fighter", Count: 1},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []HitDieRoll{
+		{Class: "Fighter", Die: 10, Roll: 3, Modifier: 2, HP: 5},
+		{Class: "Fighter", Die: 10, Roll: 10, Modifier: 2, HP: 12},
+		{Class: "Fighter", Die: 10, Roll: 7, Modifier: 2, HP: 3},
+	}
+	if !reflect.DeepEqual(result.HitDiceRolled, want) {
+		t.Errorf("rolled %+v, want %+v", result.HitDiceRolled, want)
+	}
+	if result.HPRestored != 20 || *result.Character.CurrentHP != 30 {
+		t.Errorf("restored %d to %d hit points, want 20 to 30", result.HPRestored, *result.Character.CurrentHP)
+	}
+	if got := result.Character.Classes[0].HitDiceSpent; got != 3 {
+		t.Errorf("spent %d hit dice, want 3", got)
+	}
+	if result.Character.Version != c.Version+2 {
+		t.Errorf("short rest left version %d, want one write", result.Character.Version)
+	}
+
+	// A character without maximum hit points can rest without spending hit
+	// dice, which still recharges their short rest resources
+	noMax, userID := createTestCharacter(t, s, func(req *CreateCharacterRequest) {
+		req.MaxHP = nil
+		req.CurrentHP = nil
+	})
+	char, err := s.AddResource(noMax.ID.String(), userID, &CreateResourceRequest{Name: "Second Wind", Max: "1", Recharge: RechargeShort})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UseResource(noMax.ID.String(), userID, char.Resources[0].ID.String(), &ResourceAmountRequest{Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+	result, err = s.ShortRest(noMax.ID.String(), userID, &ShortRestRequest{})
+	if err != nil {
+		t.Fatalf("resting without maximum hit points returned %v", err)
+	}
+	if got := result.Character.Resources[0].Current; got != 1 || len(result.ResourcesRecharged) != 1 {
+		t.Errorf("resting left %d uses, recharging %+v, want 1", got, result.ResourcesRecharged)
+	}
+	if result.Character.MaxHP != nil || result.Character.CurrentHP != nil || result.HPRestored != 0 {
+		t.Errorf("resting without hit dice changed hit points to %v/%v", result.Character.CurrentHP, result.Character.MaxHP)
+	}
+}
+
+func TestShortRestRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(req *CreateCharacterRequest)
+		spend  []HitDiceSpend
+		field  string
+	}{
+		{"more dice than remain", nil, []HitDiceSpend{{Count: 6}}, "hit_dice[0].count"},
+		{"more dice than remain across spends", nil, []HitDiceSpend{{Count: 3}, {Count: 3}}, "hit_dice[1].count"},
+		{"a roll per die", nil, []HitDiceSpend{{Count: 2, Rolls: []int{4}}}, "hit_dice[0].rolls"},
+		{"rolls of the die", nil, []HitDiceSpend{{Count: 1, Rolls: []int{12}}}, "hit_dice[0].rolls"},
+		{"another class", nil, []HitDiceSpend{{Class: "Wizard", Count: 1}}, "hit_dice[0].class"},
+		{"class for a multiclass character", multiclass, []HitDiceSpend{{Count: 1}}, "hit_dice[0].class"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, 5, 5, 5)
+			c, userID := createTestCharacter(t, s, tt.modify)
+			_, err := s.ShortRest(c.ID.String(), userID, &ShortRestRequest{HitDice: tt.spend})
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Code != "invalid_hit_dice" {
+				t.Fatalf("ShortRest returned %v, want invalid_hit_dice", err)
+			}
+			if _, ok := appErr.Fields[tt.field]; !ok {
+				t.Errorf("fields = %v, want %s", appErr.Fields, tt.field)
+			}
+
+			// Nothing is spent when the rest is rejected
+			stored, err := s.GetCharacterByID(c.ID.String(), userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Version != c.Version {
+				t.Errorf("a rejected rest wrote version %d", stored.Version)
+			}
+		})
+	}
+}
+
+func TestRestAtZeroHitPoints(t *testing.T) {
+	s := newTestService(t)
+	c, userID := createTestCharacter(t, s, nil)
+	changeHP(t, s, c, userID, damageOp(30))
+
+	if _, err := s.ShortRest(c.ID.String(), userID, &ShortRestRequest{}); !errors.Is(err, ErrCannotRest) {
+		t.Errorf("a dying character's short rest returned %v, want ErrCannotRest", err)
+	}
+	if _, err := s.LongRest(c.ID.String(), userID); !errors.Is(err, ErrCannotRest) {
+		t.Errorf("a dying character's long rest returned %v, want ErrCannotRest", err)
+	}
+
+	if _, err := s.Stabilize(c.ID.String(), userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LongRest(c.ID.String(), userID); !errors.Is(err, ErrLongRestAtZero) {
+		t.Errorf("a stable character's long rest returned %v, want ErrLongRestAtZero", err)
+	}
+
+	// A stable character can spend hit dice to wake up
+	result, err := s.ShortRest(c.ID.String(), userID, &ShortRestRequest{HitDice: []HitDiceSpend{{Count: 1, Rolls: []int{4}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *result.Character.CurrentHP != 6 || result.Character.Status() != StatusConscious || result.Character.DeathSaves != (DeathSaves{}) {
+		t.Errorf("woke up %s at %d hit points with %+v", result.Character.Status(), *result.Character.CurrentHP, result.Character.DeathSaves)
+	}
+}
+
+func TestLongRest(t *testing.T) {
+	s := newTestService(t)
+	c, userID := createTestCharacter(t, s, nil)
+	changeHP(t, s, c, userID, damageOp(12), HPOperation{Type: HPTemp, Amount: 5})
+	if _, err := s.ShortRest(c.ID.String(), userID, &ShortRestRequest{HitDice: []HitDiceSpend{{Count: 4, Rolls: []int{1, 1, 1, 1}}}}); err != nil {
+		t.Fatal(err)
+	}
+	exhaustion := 4
+	if _, err := s.SetExhaustion(c.ID.String(), userID, &ExhaustionRequest{Level: &exhaustion}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.LongRest(c.ID.String(), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rested := result.Character
+	// Exhaustion drops to 3 first, so hit points are restored to the full
+	// maximum rather than the halved one
+	if rested.Exhaustion != 3 || result.ExhaustionRemoved != 1 {
+		t.Errorf("exhaustion = %d, want 3", rested.Exhaustion)
+	}
+	if *rested.CurrentHP != 30 || result.HPRestored != 15 {
+		t.Errorf("restored %d to %d hit points, want 15 to 30", result.HPRestored, *rested.CurrentHP)
+	}
+	if rested.TempHP != 0 || result.TempHPLost != 5 {
+		t.Errorf("temporary hit points = %d, lost %d, want 0 losing 5", rested.TempHP, result.TempHPLost)
+	}
+	// A level 5 character regains 2 of their 4 spent hit dice
+	want := []HitDiceRegained{{Class: "Fighter", Die: 10, Count: 2}}
+	if !reflect.DeepEqual(result.HitDiceRegained, want) || rested.Classes[0].HitDiceSpent != 2 {
+		t.Errorf("regained %+v leaving %d spent, want %+v leaving 2", result.HitDiceRegained, rested.Classes[0].HitDiceSpent, want)
+	}
+}
+
+func TestLongRestRegainsLargestHitDiceFirst(t *testing.T) {
+	s := newTestService(t)
+	c, userID := createTestCharacter(t, s, func(req *CreateCharacterRequest) {
+		multiclass(req)
+		req.Classes[0].HitDiceSpent = 1
+		req.Classes[1].HitDiceSpent = 2
+	})
+
+	tests := []struct {
+		regained []HitDiceRegained
+		spent    []int
+	}{
+		{[]HitDiceRegained{{Class: "Fighter", Die: 10, Count: 1}, {Class: "Wizard", Die: 6, Count: 1}}, []int{0, 1}},
+		{[]HitDiceRegained{{Class: "Wizard", Die: 6, Count: 1}}, []int{0, 0}},
+		{[]HitDiceRegained{}, []int{0, 0}},
+	}
+	for i, tt := range tests {
+		result, err := s.LongRest(c.ID.String(), userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.HitDiceRegained, tt.regained) {
+			t.Errorf("long rest %d regained %+v, want %+v", i+1, result.HitDiceRegained, tt.regained)
+		}
+		spent := []int{result.Character.Classes[0].HitDiceSpent, result.Character.Classes[1].HitDiceSpent}
+		if !reflect.DeepEqual(spent, tt.spent) {
+			t.Errorf("long rest %d left %v spent, want %v", i+1, spent, tt.spent)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package character
+
+import (
+	"fmt"
+	"strings"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/dice"
+)
+
+// Kinds of d20 rolls made from a character's stats
+const (
+	RollSkill      = "skill"
+	RollSave       = "save"
+	RollAbility    = "ability"
+	RollInitiative = "initiative"
+)
+
+// CheckRollRequest represents a request to roll a check for a character
+type CheckRollRequest struct {
+	// Name is the skill or ability rolled; initiative doesn't need one
+	Name         string `json:"name"`
+	Advantage    bool   `json:"advantage"`
+	Disadvantage bool   `json:"disadvantage"`
+	// Extra is added to the roll, e.g. "1d4" for Bless or "-2"
+	Extra string `json:"extra" binding:"max=100"`
+}
+
+// CheckRoll is the outcome of a character's d20 roll
+type CheckRoll struct {
+	Kind string `json:"kind"`
+	Name string `json:"name,omitempty"`
+	// Modifier is the bonus taken from the character's stats
+	Modifier int `json:"modifier"`
+	// Natural is the kept d20 face
+	Natural int `json:"natural"`
+	*dice.Result
+}
+
+// RollCheck rolls a skill check, saving throw, ability check or initiative
+// using the bonuses of a character the user may read
+func (s *Service) RollCheck(characterID, userID, kind string, req *CheckRollRequest) (*CheckRoll, error) {
+	char, err := s.GetCharacterByID(characterID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	modifier, err := checkModifier(char, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	notation := "1d20"
+	if modifier != 0 {
+		notation += fmt.Sprintf("%+d", modifier)
+	}
+	if extra := strings.TrimSpace(req.Extra); extra != "" {
+		if extra[0] != '+' && extra[0] != '-' {
+			extra = "+" + extra
+		}
+		notation += extra
+	}
+
+	result, err := s.roller.RollNotation(notation, dice.ModeFor(req.Advantage, req.Disadvantage))
+	if err != nil {
+		return nil, err
+	}
+
+	natural, _ := result.Natural()
+	if kind == RollInitiative {
+		name = ""
+	}
+	return &CheckRoll{Kind: kind, Name: name, Modifier: modifier, Natural: natural, Result: result}, nil
+}
+
+// checkModifier looks up the bonus a character adds to a kind of roll
+func checkModifier(char *Character, kind, name string) (int, error) {
+	derived := char.Derived
+	switch kind {
+	case RollSkill:
+		bonus, ok := derived.Skills[name]
+		if !ok {
+			return 0, apperror.Field("name", "must be a skill such as stealth or sleight_of_hand")
+		}
+		return bonus, nil
+	case RollSave:
+		bonus, ok := derived.SavingThrows[name]
+		if !ok {
+			return 0, apperror.Field("name", "must be an ability such as dexterity")
+		}
+		return bonus, nil
+	case RollAbility:
+		if !isAbility(name) {
+			return 0, apperror.Field("name", "must be an ability such as dexterity")
+		}
+		bonus := derived.Modifiers.ModifierFor(name)
+		if char.JackOfAllTrades {
+			// Raw ability checks never include proficiency, so Jack of All
+			// Trades always applies
+			bonus += derived.ProficiencyBonus / 2
+		}
+		return bonus, nil
+	case RollInitiative:
+		return derived.Initiative, nil
+	default:
+		return 0, apperror.NotFound("unknown_roll", "Unknown roll type; use skill, save, ability or initiative")
+	}
+}
@@ -0,0 +1,117 @@
+package character
+
+// AbilityModifier returns the modifier for an ability score (rounded down)
+func AbilityModifier(score int) int {
+	diff := score - 10
+	if diff < 0 {
+		return (diff - 1) / 2
+	}
+	return diff / 2
+}
+
+// ProficiencyBonus returns the proficiency bonus for a character level
+func ProficiencyBonus(level int) int {
+	if level < 1 {
+		level = 1
+	}
+	return 2 + (level-1)/4
+}
+
+// AbilityModifiers holds the modifier for each ability score
+type AbilityModifiers struct {
+	Strength     int `json:"strength"`
+	Dexterity    int `json:"dexterity"`
+	Constitution int `json:"constitution"`
+	Intelligence int `json:"intelligence"`
+	Wisdom       int `json:"wisdom"`
+	Charisma     int `json:"charisma"`
+}
+
+// DerivedStats holds values computed from a character's stored fields
+type DerivedStats struct {
+	Modifiers            AbilityModifiers `json:"modifiers"`
+	ProficiencyBonus     int              `json:"proficiency_bonus"`
+	Initiative           int              `json:"initiative"`
+	PassivePerception    int              `json:"passive_perception"`
+	PassiveInvestigation int              `json:"passive_investigation"`
+	PassiveInsight       int              `json:"passive_insight"`
+	Skills               map[string]int   `json:"skills"`
+	SavingThrows         map[string]int   `json:"saving_throws"`
+	ArmorClass           *ArmorClass      `json:"armor_class"`
+	// Status is conscious, dying, stable or dead
+	Status string `json:"status"`
+	// Effects are the combined effects of conditions and exhaustion
+	Effects *ConditionEffects `json:"effects"`
+	// HitDice are the character's hit dice pools, largest die first
+	HitDice []HitDicePool `json:"hit_dice"`
+	// ResourceMax is the evaluated maximum of each resource, by ID
+	ResourceMax map[string]int `json:"resource_max"`
+}
+
+// ComputeDerived calculates the derived stats for a character
+func ComputeDerived(c *Character) *DerivedStats {
+	mods := AbilityModifiers{
+		Strength:     AbilityModifier(c.Strength),
+		Dexterity:    AbilityModifier(c.Dexterity),
+		Constitution: AbilityModifier(c.Constitution),
+		Intelligence: AbilityModifier(c.Intelligence),
+		Wisdom:       AbilityModifier(c.Wisdom),
+		Charisma:     AbilityModifier(c.Charisma),
+	}
+
+	profBonus := ProficiencyBonus(c.Level)
+
+	skillLevels := make(map[string]string)
+	saveLevels := make(map[string]string)
+	for _, p := range c.Proficiencies {
+		switch p.Kind {
+		case ProficiencyKindSkill:
+			skillLevels[p.Name] = p.Level
+		case ProficiencyKindSave:
+			saveLevels[p.Name] = p.Level
+		}
+	}
+
+	// Jack of All Trades adds half proficiency to any skill the character
+	// isn't otherwise proficient in
+	skills := make(map[string]int, len(Skills))
+	for skill, ability := range Skills {
+		level, ok := skillLevels[skill]
+		if !ok && c.JackOfAllTrades {
+			level = ProficiencyHalf
+		}
+		skills[skill] = mods.ModifierFor(ability) + proficiencyContribution(level, profBonus)
+	}
+
+	saves := make(map[string]int, len(Abilities))
+	for _, ability := range Abilities {
+		saves[ability] = mods.ModifierFor(ability) + proficiencyContribution(saveLevels[ability], profBonus)
+	}
+
+	initiative := mods.Dexterity
+	if c.JackOfAllTrades {
+		// Initiative is a Dexterity check, so it benefits too
+		initiative += profBonus / 2
+	}
+
+	resourceMax := make(map[string]int, len(c.Resources))
+	for i := range c.Resources {
+		resourceMax[c.Resources[i].ID.String()] = c.ResourceMax(&c.Resources[i])
+	}
+
+	return &DerivedStats{
+		Modifiers:            mods,
+		ProficiencyBonus:     profBonus,
+		Initiative:           initiative,
+		PassivePerception:    10 + skills["perception"],
+		PassiveInvestigation: 10 + skills["investigation"],
+		PassiveInsight:       10 + skills["insight"],
+		Skills:               skills,
+		SavingThrows:         saves,
+		ArmorClass:           ComputeArmorClass(c, mods),
+		Status:               c.Status(),
+		Effects:              c.conditionEffects(),
+		HitDice:              c.HitDice(),
+		ResourceMax:          resourceMax,
+	}
+}
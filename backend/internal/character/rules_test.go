@@ -0,0 +1,117 @@
+package character
+
+import "testing"
+
+func TestAbilityModifier(t *testing.T) {
+	tests := []struct{ score, want int }{
+		{1, -5}, {3, -4}, {8, -1}, {9, -1}, {10, 0}, {11, 0}, {12, 1}, {15, 2}, {20, 5}, {30, 10},
+	}
+	for _, tt := range tests {
+		if got := AbilityModifier(tt.score); got != tt.want {
+			t.Errorf("AbilityModifier(%d) = %d, want %d", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestProficiencyBonus(t *testing.T) {
+	tests := []struct{ level, want int }{
+		{0, 2}, {1, 2}, {4, 2}, {5, 3}, {8, 3}, {9, 4}, {13, 5}, {17, 6}, {20, 6},
+	}
+	for _, tt := range tests {
+		if got := ProficiencyBonus(tt.level); got != tt.want {
+			t.Errorf("ProficiencyBonus(%d) = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+}
+
+// testCharacter is a level 5 rogue with a spread of ability scores
+func testCharacter() *Character {
+	return &Character{
+		Name:         "Vex",
+		Race:         "Halfling",
+		Classes:      []ClassEntry{{Class: "Rogue", Levels: 5, HitDie: 8}},
+		Level:        5,
+		Strength:     8,
+		Dexterity:    18,
+		Constitution: 14,
+		Intelligence: 12,
+		Wisdom:       13,
+		Charisma:     10,
+		Speed:        DefaultSpeed,
+	}
+}
+
+func TestComputeDerived(t *testing.T) {
+	c := testCharacter()
+	c.Proficiencies = []Proficiency{
+		{Kind: ProficiencyKindSkill, Name: "stealth", Level: ProficiencyExpertise},
+		{Kind: ProficiencyKindSkill, Name: "perception", Level: ProficiencyProficient},
+		{Kind: ProficiencyKindSave, Name: Dexterity, Level: ProficiencyProficient},
+	}
+
+	d := ComputeDerived(c)
+	if want := (AbilityModifiers{Strength: -1, Dexterity: 4, Constitution: 2, Intelligence: 1, Wisdom: 1, Charisma: 0}); d.Modifiers != want {
+		t.Errorf("modifiers = %+v, want %+v", d.Modifiers, want)
+	}
+	if d.ProficiencyBonus != 3 {
+		t.Errorf("proficiency bonus = %d, want 3", d.ProficiencyBonus)
+	}
+
+	skills := map[string]int{"stealth": 10, "perception": 4, "athletics": -1, "insight": 1, "investigation": 1}
+	for skill, want := range skills {
+		if got := d.Skills[skill]; got != want {
+			t.Errorf("%s = %d, want %d", skill, got, want)
+		}
+	}
+	if len(d.Skills) != len(Skills) {
+		t.Errorf("got %d skills, want %d", len(d.Skills), len(Skills))
+	}
+	saves := map[string]int{Strength: -1, Dexterity: 7, Constitution: 2, Wisdom: 1}
+	for ability, want := range saves {
+		if got := d.SavingThrows[ability]; got != want {
+			t.Errorf("%s save = %d, want %d", ability, got, want)
+		}
+	}
+
+	if d.Initiative != 4 {
+		t.Errorf("initiative = %d, want 4", d.Initiative)
+	}
+	if d.PassivePerception != 14 || d.PassiveInvestigation != 11 || d.PassiveInsight != 11 {
+		t.Errorf("passives = %d/%d/%d, want 14/11/11", d.PassivePerception, d.PassiveInvestigation, d.PassiveInsight)
+	}
+	if d.ArmorClass.Total != 14 || d.ArmorClass.Overridden {
+		t.Errorf("armor class = %+v, want an unarmored 14", d.ArmorClass)
+	}
+	if d.Status != StatusConscious {
+		t.Errorf("status = %q, want %q", d.Status, StatusConscious)
+	}
+	if len(d.HitDice) != 1 || d.HitDice[0] != (HitDicePool{Die: 8, Total: 5, Remaining: 5}) {
+		t.Errorf("hit dice = %+v", d.HitDice)
+	}
+}
+
+func TestComputeDerivedJackOfAllTrades(t *testing.T) {
+	c := testCharacter()
+	c.Classes = []ClassEntry{{Class: "Bard", Levels: 5, HitDie: 8}}
+	c.JackOfAllTrades = true
+	c.Proficiencies = []Proficiency{
+		{Kind: ProficiencyKindSkill, Name: "performance", Level: ProficiencyProficient},
+		{Kind: ProficiencyKindSave, Name: Charisma, Level: ProficiencyProficient},
+	}
+
+	d := ComputeDerived(c)
+	// Half of a +3 proficiency bonus rounds down to +1
+	if got := d.Skills["athletics"]; got != 0 {
+		t.Errorf("athletics = %d, want 0", got)
+	}
+	if got := d.Skills["performance"]; got != 3 {
+		t.Errorf("performance = %d, want 3", got)
+	}
+	if d.Initiative != 5 {
+		t.Errorf("initiative = %d, want 5", d.Initiative)
+	}
+	// Saving throws aren't ability checks
+	if got := d.SavingThrows[Strength]; got != -1 {
+		t.Errorf("Strength save = %d, want -1", got)
+	}
+}
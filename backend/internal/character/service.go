@@ -1,144 +1,153 @@
 package character
 
 import (
-	"database/sql"
-	"fmt"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/dice"
 )
 
 // Service handles character operations
 type Service struct {
-	db *sql.DB
+	store   CharacterStore
+	policy  AccessPolicy
+	roller  *dice.Roller
+	classes ClassRules
 }
 
-// NewService creates a new character service
-func NewService(db *sql.DB) *Service {
-	return &Service{db: db}
+// NewService creates a new character service. policy grants access to
+// characters of other users and may be nil, limiting users to their own.
+// classes looks up hit dice and multiclass prerequisites and may be nil,
+// in which case every class entry must give its hit die.
+func NewService(store CharacterStore, policy AccessPolicy, roller *dice.Roller, classes ClassRules) *Service {
+	return &Service{store: store, policy: policy, roller: roller, classes: classes}
 }
 
 // GetCharactersByUserID retrieves all characters for a user
 func (s *Service) GetCharactersByUserID(userID string) ([]*Character, error) {
-	query := `
-		SELECT id, user_id, name, race, class, level, background,
-		       strength, dexterity, constitution, intelligence, wisdom, charisma,
-		       max_hp, current_hp, armor_class, notes, created_at, updated_at
-		FROM characters
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-	`
-
-	rows, err := s.db.Query(query, userID)
+	characters, err := s.store.ListByUser(userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query characters: %w", err)
-	}
-	defer rows.Close()
-
-	var characters []*Character
-	for rows.Next() {
-		char := &Character{}
-		err := rows.Scan(
-			&char.ID, &char.UserID, &char.Name, &char.Race, &char.Class,
-			&char.Level, &char.Background, &char.Strength, &char.Dexterity,
-			&char.Constitution, &char.Intelligence, &char.Wisdom, &char.Charisma,
-			&char.MaxHP, &char.CurrentHP, &char.ArmorClass, &char.Notes,
-			&char.CreatedAt, &char.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan character: %w", err)
-		}
-		characters = append(characters, char)
+		return nil, err
+	}
+
+	for _, char := range characters {
+		char.Derived = ComputeDerived(char)
 	}
 
 	return characters, nil
 }
 
-// GetCharacterByID retrieves a character by ID and user ID
+// GetCharacterByID retrieves a character the user owns or may read
 func (s *Service) GetCharacterByID(characterID, userID string) (*Character, error) {
-	char := &Character{}
-	query := `
-		SELECT id, user_id, name, race, class, level, background,
-		       strength, dexterity, constitution, intelligence, wisdom, charisma,
-		       max_hp, current_hp, armor_class, notes, created_at, updated_at
-		FROM characters
-		WHERE id = $1 AND user_id = $2
-	`
-
-	err := s.db.QueryRow(query, characterID, userID).Scan(
-		&char.ID, &char.UserID, &char.Name, &char.Race, &char.Class,
-		&char.Level, &char.Background, &char.Strength, &char.Dexterity,
-		&char.Constitution, &char.Intelligence, &char.Wisdom, &char.Charisma,
-		&char.MaxHP, &char.CurrentHP, &char.ArmorClass, &char.Notes,
-		&char.CreatedAt, &char.UpdatedAt,
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("character not found")
-	}
+	char, _, err := s.authorize(characterID, userID, AccessRead)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get character: %w", err)
+		return nil, err
 	}
 
+	char.Derived = ComputeDerived(char)
 	return char, nil
 }
 
 // CreateCharacter creates a new character
 func (s *Service) CreateCharacter(userID string, req *CreateCharacterRequest) (*Character, error) {
+	if err := ValidateProficiencies(req.Proficiencies); err != nil {
+		return nil, err
+	}
+
 	character := &Character{
-		ID:           uuid.New(),
-		UserID:       uuid.MustParse(userID),
-		Name:         req.Name,
-		Race:         req.Race,
-		Class:        req.Class,
-		Level:        req.Level,
-		Background:   req.Background,
-		Strength:     req.Strength,
-		Dexterity:    req.Dexterity,
-		Constitution: req.Constitution,
-		Intelligence: req.Intelligence,
-		Wisdom:       req.Wisdom,
-		Charisma:     req.Charisma,
-		MaxHP:        req.MaxHP,
-		CurrentHP:    req.CurrentHP,
-		ArmorClass:   req.ArmorClass,
-		Notes:        req.Notes,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}
-
-	query := `
-		INSERT INTO characters (
-			id, user_id, name, race, class, level, background,
-			strength, dexterity, constitution, intelligence, wisdom, charisma,
-			max_hp, current_hp, armor_class, notes, created_at, updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
-		)
-	`
-
-	_, err := s.db.Exec(query,
-		character.ID, character.UserID, character.Name, character.Race, character.Class,
-		character.Level, character.Background, character.Strength, character.Dexterity,
-		character.Constitution, character.Intelligence, character.Wisdom, character.Charisma,
-		character.MaxHP, character.CurrentHP, character.ArmorClass, character.Notes,
-		character.CreatedAt, character.UpdatedAt,
-	)
+		ID:              uuid.New(),
+		UserID:          uuid.MustParse(userID),
+		Name:            req.Name,
+		Race:            req.Race,
+		Classes:         req.Classes,
+		Background:      req.Background,
+		Strength:        req.Strength,
+		Dexterity:       req.Dexterity,
+		Constitution:    req.Constitution,
+		Intelligence:    req.Intelligence,
+		Wisdom:          req.Wisdom,
+		Charisma:        req.Charisma,
+		MaxHP:           req.MaxHP,
+		CurrentHP:       req.CurrentHP,
+		ArmorClass:      req.ArmorClass,
+		Armor:           req.Armor,
+		Shield:          req.Shield,
+		ACBonuses:       req.ACBonuses,
+		Notes:           req.Notes,
+		Proficiencies:   req.Proficiencies,
+		JackOfAllTrades: req.JackOfAllTrades,
+		Version:         1,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	if len(character.Classes) == 0 && req.Class != "" {
+		character.Classes = []ClassEntry{{Class: req.Class, Subclass: req.Subclass, Levels: max(req.Level, 1)}}
+	}
+	if err := s.CheckClasses(character, true); err != nil {
+		return nil, err
+	}
+	if character.Proficiencies == nil {
+		character.Proficiencies = []Proficiency{}
+	}
+	if character.ACBonuses == nil {
+		character.ACBonuses = []ACBonus{}
+	}
+	if req.Defenses != nil {
+		character.Defenses = *req.Defenses
+	}
+	if err := checkDefenses(&character.Defenses); err != nil {
+		return nil, err
+	}
+	character.Speed = DefaultSpeed
+	if req.Speed != nil {
+		character.Speed = *req.Speed
+	}
+	character.Feats = []string{}
+	character.SpellSlotsExpended = []int{}
+	character.Conditions = []Condition{}
+	character.Resources = []Resource{}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to create character: %w", err)
+	rev := &Revision{
+		UserID:    character.UserID,
+		Action:    RevisionCreate,
+		Changes:   []FieldChange{},
+		CreatedAt: character.CreatedAt,
+	}
+
+	if err := s.store.Create(character, rev); err != nil {
+		return nil, err
 	}
 
+	character.Derived = ComputeDerived(character)
 	return character, nil
 }
 
-// UpdateCharacter updates an existing character
-func (s *Service) UpdateCharacter(characterID, userID string, req *UpdateCharacterRequest) (*Character, error) {
-	// First, get the existing character to ensure it belongs to the user
-	existing, err := s.GetCharacterByID(characterID, userID)
+// UpdateCharacter updates an existing character, provided it is still at
+// expectedVersion (or expectedVersion is AnyVersion). Users with combat
+// access to someone else's character may only change its hit points.
+func (s *Service) UpdateCharacter(characterID, userID string, expectedVersion int, req *UpdateCharacterRequest) (*Character, error) {
+	if req.Proficiencies != nil {
+		if err := ValidateProficiencies(*req.Proficiencies); err != nil {
+			return nil, err
+		}
+	}
+	if err := req.validateClear(); err != nil {
+		return nil, err
+	}
+
+	existing, access, err := s.authorize(characterID, userID, AccessCombat)
 	if err != nil {
 		return nil, err
 	}
+	if access < AccessOwner && !req.combatOnly() {
+		return nil, ErrCombatFieldsOnly
+	}
+	if expectedVersion != AnyVersion && existing.Version != expectedVersion {
+		return nil, s.conflict(existing)
+	}
+	before := existing.Clone()
 
 	// Update fields if provided
 	if req.Name != nil {
@@ -147,11 +156,9 @@ func (s *Service) UpdateCharacter(characterID, userID string, req *UpdateCharact
 	if req.Race != nil {
 		existing.Race = *req.Race
 	}
-	if req.Class != nil {
-		existing.Class = *req.Class
-	}
-	if req.Level != nil {
-		existing.Level = *req.Level
+	classesChanged, err := req.applyClasses(existing)
+	if err != nil {
+		return nil, err
 	}
 	if req.Background != nil {
 		existing.Background = *req.Background
@@ -179,55 +186,222 @@ func (s *Service) UpdateCharacter(characterID, userID string, req *UpdateCharact
 	}
 	if req.CurrentHP != nil {
 		existing.CurrentHP = req.CurrentHP
+		// Setting hit points above 0 by hand also clears the death saves,
+		// including death
+		if *req.CurrentHP > 0 {
+			existing.DeathSaves = DeathSaves{}
+		}
+	}
+	if req.TempHP != nil {
+		existing.TempHP = *req.TempHP
+	}
+	if req.Speed != nil {
+		existing.Speed = *req.Speed
 	}
 	if req.ArmorClass != nil {
 		existing.ArmorClass = req.ArmorClass
 	}
+	if req.Armor != nil {
+		existing.Armor = req.Armor
+	}
+	if req.Shield != nil {
+		existing.Shield = req.Shield
+	}
+	if req.ACBonuses != nil {
+		existing.ACBonuses = *req.ACBonuses
+	}
+	if req.Defenses != nil {
+		existing.Defenses = *req.Defenses
+		if err := checkDefenses(&existing.Defenses); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range req.Clear {
+		switch field {
+		case "armor":
+			existing.Armor = nil
+		case "shield":
+			existing.Shield = nil
+		case "armor_class":
+			existing.ArmorClass = nil
+		}
+	}
 	if req.Notes != nil {
 		existing.Notes = req.Notes
 	}
+	if req.Proficiencies != nil {
+		existing.Proficiencies = *req.Proficiencies
+	}
+	if req.JackOfAllTrades != nil {
+		existing.JackOfAllTrades = *req.JackOfAllTrades
+	}
 
+	if err := s.CheckClasses(existing, classesChanged); err != nil {
+		return nil, err
+	}
 	existing.UpdatedAt = time.Now()
 
-	query := `
-		UPDATE characters SET
-			name = $3, race = $4, class = $5, level = $6, background = $7,
-			strength = $8, dexterity = $9, constitution = $10, intelligence = $11,
-			wisdom = $12, charisma = $13, max_hp = $14, current_hp = $15,
-			armor_class = $16, notes = $17, updated_at = $18
-		WHERE id = $1 AND user_id = $2
-	`
-
-	_, err = s.db.Exec(query,
-		characterID, userID, existing.Name, existing.Race, existing.Class,
-		existing.Level, existing.Background, existing.Strength, existing.Dexterity,
-		existing.Constitution, existing.Intelligence, existing.Wisdom, existing.Charisma,
-		existing.MaxHP, existing.CurrentHP, existing.ArmorClass, existing.Notes,
-		existing.UpdatedAt,
-	)
+	rev := &Revision{
+		UserID:    uuid.MustParse(userID),
+		Action:    RevisionUpdate,
+		Changes:   diffCharacters(before, existing),
+		CreatedAt: existing.UpdatedAt,
+	}
+
+	return s.save(existing, before.Version, rev)
+}
 
+// GetHistory lists a character's revisions, newest first
+func (s *Service) GetHistory(characterID, userID string) ([]*Revision, error) {
+	if _, err := s.GetCharacterByID(characterID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListRevisions(characterID)
+}
+
+// GetRevision returns a single revision with the character as it was then
+func (s *Service) GetRevision(characterID, userID string, revision int) (*Revision, error) {
+	if _, err := s.GetCharacterByID(characterID, userID); err != nil {
+		return nil, err
+	}
+
+	rev, err := s.store.GetRevision(characterID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	rev.Snapshot.Derived = ComputeDerived(rev.Snapshot)
+	return rev, nil
+}
+
+// DiffRevisions compares the character at two revisions
+func (s *Service) DiffRevisions(characterID, userID string, from, to int) (*RevisionDiff, error) {
+	if _, err := s.GetCharacterByID(characterID, userID); err != nil {
+		return nil, err
+	}
+
+	fromRev, err := s.store.GetRevision(characterID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := s.store.GetRevision(characterID, to)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update character: %w", err)
+		return nil, err
 	}
 
-	return existing, nil
+	return &RevisionDiff{
+		From:    from,
+		To:      to,
+		Changes: diffCharacters(fromRev.Snapshot, toRev.Snapshot),
+	}, nil
 }
 
-// DeleteCharacter deletes a character
-func (s *Service) DeleteCharacter(characterID, userID string) error {
-	result, err := s.db.Exec("DELETE FROM characters WHERE id = $1 AND user_id = $2", characterID, userID)
+// RestoreRevision rolls a character back to the state recorded in an
+// earlier revision. The restore is itself recorded as a new revision.
+func (s *Service) RestoreRevision(characterID, userID string, revision, expectedVersion int) (*Character, error) {
+	current, _, err := s.authorize(characterID, userID, AccessOwner)
 	if err != nil {
-		return fmt.Errorf("failed to delete character: %w", err)
+		return nil, err
+	}
+	if expectedVersion != AnyVersion && current.Version != expectedVersion {
+		return nil, s.conflict(current)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	rev, err := s.store.GetRevision(characterID, revision)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, err
+	}
+
+	restored := restoreFrom(current, rev.Snapshot)
+	if err := s.CheckClasses(restored, false); err != nil {
+		return nil, err
 	}
+	restoredFrom := revision
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("character not found")
+	return s.save(restored, current.Version, &Revision{
+		UserID:       uuid.MustParse(userID),
+		Action:       RevisionRestore,
+		RestoredFrom: &restoredFrom,
+		Changes:      diffCharacters(current, restored),
+		CreatedAt:    restored.UpdatedAt,
+	})
+}
+
+// save writes a character at expectedVersion with its revision, reporting
+// conflicts with the character's current state
+func (s *Service) save(character *Character, expectedVersion int, rev *Revision) (*Character, error) {
+	if err := s.store.Update(character, expectedVersion, rev); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return nil, s.currentConflict(character.ID.String())
+		}
+		return nil, err
 	}
 
-	return nil
-} 
\ No newline at end of file
+	character.Derived = ComputeDerived(character)
+	return character, nil
+}
+
+// Apply changes a character the user has at least the wanted access to in
+// one locked write and records it as a revision with the given action.
+// There is no version check: packages tracking play state such as spell
+// slots use it for small changes that must not race with each other.
+func (s *Service) Apply(characterID, userID string, want Access, action string, change func(c *Character) error) (*Character, error) {
+	if _, _, err := s.authorize(characterID, userID, want); err != nil {
+		return nil, err
+	}
+
+	char, err := s.store.Apply(characterID, func(c *Character) (*Revision, error) {
+		before := c.Clone()
+		if err := change(c); err != nil {
+			return nil, err
+		}
+		c.UpdatedAt = time.Now()
+
+		return NewRevision(userID, action, before, c), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	char.Derived = ComputeDerived(char)
+	return char, nil
+}
+
+// DeleteCharacter deletes a character, provided it is still at
+// expectedVersion (or expectedVersion is AnyVersion)
+func (s *Service) DeleteCharacter(characterID, userID string, expectedVersion int) error {
+	if _, _, err := s.authorize(characterID, userID, AccessOwner); err != nil {
+		return err
+	}
+
+	err := s.store.Delete(characterID, userID, expectedVersion)
+	if errors.Is(err, ErrVersionConflict) {
+		return s.currentConflict(characterID)
+	}
+	return err
+}
+
+// conflict builds a version conflict error carrying the current character
+// so the client can merge and retry
+func (s *Service) conflict(current *Character) error {
+	current.Derived = ComputeDerived(current)
+	return ErrVersionConflict.WithDetails(current)
+}
+
+// currentConflict loads the character after a failed versioned write and
+// reports the conflict with its current state
+func (s *Service) currentConflict(characterID string) error {
+	current, err := s.store.Get(characterID)
+	if err != nil {
+		return err
+	}
+	return s.conflict(current)
+}
+
+// isValidID reports whether id is a well-formed UUID, so malformed IDs are
+// treated as missing rather than reaching the database
+func isValidID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
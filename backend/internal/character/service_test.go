@@ -0,0 +1,73 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/dice"
+)
+
+// faces is an RNG that rolls the given faces in order. Rolling more dice
+// than a test gave faces for fails it.
+type faces struct {
+	t     *testing.T
+	rolls []int
+}
+
+func (f *faces) Intn(n int) int {
+	if len(f.rolls) == 0 {
+		f.t.Fatalf("rolled a d%d with no faces left", n)
+	}
+	face := f.rolls[0]
+	f.rolls = f.rolls[1:]
+	return face - 1
+}
+
+// newTestService creates a service over an in-memory store whose dice roll
+// the given faces
+func newTestService(t *testing.T, rolls ...int) *Service {
+	t.Helper()
+	return NewService(NewMemoryStore(), nil, dice.NewRoller(&faces{t: t, rolls: rolls}), nil)
+}
+
+// createTestCharacter stores a level 5 fighter with 30 hit points and a +2
+// Constitution modifier, after any changes to the request, and returns it
+// with the ID of the user owning it
+func createTestCharacter(t *testing.T, s *Service, modify func(req *CreateCharacterRequest)) (*Character, string) {
+	t.Helper()
+	userID := uuid.NewString()
+	maxHP := 30
+	req := &CreateCharacterRequest{
+		Name:         "Tordek",
+		Race:         "Dwarf",
+		Classes:      []ClassEntry{{Class: "Fighter", Levels: 5, HitDie: 10}},
+		Background:   "Soldier",
+		Strength:     16,
+		Dexterity:    12,
+		Constitution: 14,
+		Intelligence: 10,
+		Wisdom:       13,
+		Charisma:     8,
+		MaxHP:        &maxHP,
+		CurrentHP:    &maxHP,
+	}
+	if modify != nil {
+		modify(req)
+	}
+	c, err := s.CreateCharacter(userID, req)
+	if err != nil {
+		t.Fatalf("CreateCharacter returned %v", err)
+	}
+	return c, userID
+}
+
+// changeHP applies hit point operations, failing the test on an error
+func changeHP(t *testing.T, s *Service, c *Character, userID string, ops ...HPOperation) *HitPoints {
+	t.Helper()
+	hp, err := s.ChangeHitPoints(c.ID.String(), userID, &HPRequest{Operations: ops})
+	if err != nil {
+		t.Fatalf("ChangeHitPoints returned %v", err)
+	}
+	return hp
+}
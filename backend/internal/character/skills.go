@@ -0,0 +1,140 @@
+package character
+
+import (
+	"fmt"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// Ability names as used in skill and saving throw definitions
+const (
+	Strength     = "strength"
+	Dexterity    = "dexterity"
+	Constitution = "constitution"
+	Intelligence = "intelligence"
+	Wisdom       = "wisdom"
+	Charisma     = "charisma"
+)
+
+// Abilities lists the six abilities in sheet order
+var Abilities = []string{Strength, Dexterity, Constitution, Intelligence, Wisdom, Charisma}
+
+// Skills maps each skill to the ability it is based on
+var Skills = map[string]string{
+	"acrobatics":      Dexterity,
+	"animal_handling": Wisdom,
+	"arcana":          Intelligence,
+	"athletics":       Strength,
+	"deception":       Charisma,
+	"history":         Intelligence,
+	"insight":         Wisdom,
+	"intimidation":    Charisma,
+	"investigation":   Intelligence,
+	"medicine":        Wisdom,
+	"nature":          Intelligence,
+	"perception":      Wisdom,
+	"performance":     Charisma,
+	"persuasion":      Charisma,
+	"religion":        Intelligence,
+	"sleight_of_hand": Dexterity,
+	"stealth":         Dexterity,
+	"survival":        Wisdom,
+}
+
+// Proficiency kinds
+const (
+	ProficiencyKindSkill = "skill"
+	ProficiencyKindSave  = "save"
+)
+
+// Proficiency levels
+const (
+	ProficiencyHalf       = "half"
+	ProficiencyProficient = "proficient"
+	ProficiencyExpertise  = "expertise"
+)
+
+// Proficiency records a character's training in a skill or saving throw
+type Proficiency struct {
+	Kind  string `json:"kind" db:"kind"`
+	Name  string `json:"name" db:"name"`
+	Level string `json:"level" db:"level"`
+}
+
+// ValidateProficiencies checks that every record names a known skill or save
+// and that no skill or save is listed twice
+func ValidateProficiencies(profs []Proficiency) error {
+	seen := make(map[string]bool)
+	for _, p := range profs {
+		switch p.Kind {
+		case ProficiencyKindSkill:
+			if _, ok := Skills[p.Name]; !ok {
+				return invalidProficiency(fmt.Sprintf("unknown skill: %s", p.Name))
+			}
+		case ProficiencyKindSave:
+			if !isAbility(p.Name) {
+				return invalidProficiency(fmt.Sprintf("unknown saving throw: %s", p.Name))
+			}
+		default:
+			return invalidProficiency(fmt.Sprintf("unknown proficiency kind: %s", p.Kind))
+		}
+
+		switch p.Level {
+		case ProficiencyHalf, ProficiencyProficient, ProficiencyExpertise:
+		default:
+			return invalidProficiency(fmt.Sprintf("unknown proficiency level: %s", p.Level))
+		}
+
+		key := p.Kind + ":" + p.Name
+		if seen[key] {
+			return invalidProficiency(fmt.Sprintf("duplicate %s proficiency: %s", p.Kind, p.Name))
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func invalidProficiency(message string) error {
+	return apperror.Validation("invalid_proficiency", message, map[string]string{"proficiencies": message})
+}
+
+// proficiencyContribution returns the bonus a proficiency level adds
+func proficiencyContribution(level string, profBonus int) int {
+	switch level {
+	case ProficiencyHalf:
+		return profBonus / 2
+	case ProficiencyProficient:
+		return profBonus
+	case ProficiencyExpertise:
+		return profBonus * 2
+	}
+	return 0
+}
+
+func isAbility(name string) bool {
+	for _, a := range Abilities {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ModifierFor returns the modifier for the named ability
+func (m AbilityModifiers) ModifierFor(ability string) int {
+	switch ability {
+	case Strength:
+		return m.Strength
+	case Dexterity:
+		return m.Dexterity
+	case Constitution:
+		return m.Constitution
+	case Intelligence:
+		return m.Intelligence
+	case Wisdom:
+		return m.Wisdom
+	case Charisma:
+		return m.Charisma
+	}
+	return 0
+}
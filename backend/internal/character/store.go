@@ -0,0 +1,94 @@
+package character
+
+import "character-sheet-backend/internal/apperror"
+
+var (
+	// ErrCharacterNotFound is returned when a character doesn't exist or
+	// belongs to another user
+	ErrCharacterNotFound = apperror.NotFound("character_not_found", "Character not found")
+	// ErrVersionConflict is returned when a write names a version that is no
+	// longer current
+	ErrVersionConflict = apperror.PreconditionFailed("version_conflict", "Character was modified by another request")
+	// ErrVersionRequired is returned when a write doesn't say which version it
+	// is based on
+	ErrVersionRequired = apperror.PreconditionRequired("version_required", "An If-Match header or version field is required")
+)
+
+// AnyVersion skips the version check on writes (If-Match: *)
+const AnyVersion = 0
+
+// CharacterStore persists characters and their proficiencies
+type CharacterStore interface {
+	// ListByUser returns a user's characters, newest first
+	ListByUser(userID string) ([]*Character, error)
+	// Get returns a character regardless of who owns it; callers check access
+	Get(characterID string) (*Character, error)
+	// Create stores a new character along with its first revision
+	Create(character *Character, rev *Revision) error
+	// Update saves every stored field of an existing character, replacing its
+	// proficiencies, and records rev in the same write. It fails with
+	// ErrVersionConflict unless the stored version equals expectedVersion,
+	// and bumps character.Version on success.
+	Update(character *Character, expectedVersion int, rev *Revision) error
+	// Delete removes a character owned by the given user, failing with
+	// ErrVersionConflict unless the stored version equals expectedVersion
+	Delete(characterID, userID string, expectedVersion int) error
+	// Apply loads a character, lets change modify it and saves the result
+	// with the revision change returns, holding a lock on the character
+	// throughout so concurrent calls take turns. The version is bumped as
+	// for any other write but not checked.
+	Apply(characterID string, change func(character *Character) (*Revision, error)) (*Character, error)
+
+	// ListRevisions returns a character's revisions, newest first, without
+	// snapshots
+	ListRevisions(characterID string) ([]*Revision, error)
+	// GetRevision returns a single revision including its snapshot
+	GetRevision(characterID string, revision int) (*Revision, error)
+}
+
+// Clone returns a deep copy so stored characters can't be modified through
+// values handed to callers
+func (c *Character) Clone() *Character {
+	cp := *c
+	cp.MaxHP = cloneInt(c.MaxHP)
+	cp.CurrentHP = cloneInt(c.CurrentHP)
+	cp.ArmorClass = cloneInt(c.ArmorClass)
+	if c.Armor != nil {
+		armor := *c.Armor
+		cp.Armor = &armor
+	}
+	if c.Shield != nil {
+		shield := *c.Shield
+		cp.Shield = &shield
+	}
+	cp.Classes = append([]ClassEntry{}, c.Classes...)
+	cp.ACBonuses = append([]ACBonus{}, c.ACBonuses...)
+	cp.Defenses = c.Defenses.clone()
+	cp.Conditions = cloneConditions(c.Conditions)
+	cp.Feats = append([]string{}, c.Feats...)
+	cp.Resources = append([]Resource{}, c.Resources...)
+	cp.SpellSlotsExpended = append([]int{}, c.SpellSlotsExpended...)
+	if c.Notes != nil {
+		notes := *c.Notes
+		cp.Notes = &notes
+	}
+	cp.Proficiencies = append([]Proficiency{}, c.Proficiencies...)
+	cp.Derived = nil
+	return &cp
+}
+
+func cloneInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	n := *v
+	return &n
+}
+
+// stamp fills in the fields of a revision that are only known once the
+// character has been written
+func (r *Revision) stamp(character *Character) {
+	r.CharacterID = character.ID
+	r.Revision = character.Version
+	r.Snapshot = character.Clone()
+}
@@ -0,0 +1,169 @@
+package character
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a CharacterStore kept in process memory, for tests and
+// demos that run without a database
+type MemoryStore struct {
+	mu         sync.RWMutex
+	characters map[string]*Character
+	revisions  map[string][]*Revision
+}
+
+// NewMemoryStore creates an empty in-memory character store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		characters: make(map[string]*Character),
+		revisions:  make(map[string][]*Revision),
+	}
+}
+
+// ListByUser returns a user's characters, newest first
+func (s *MemoryStore) ListByUser(userID string) ([]*Character, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var characters []*Character
+	for _, char := range s.characters {
+		if char.UserID.String() == userID {
+			characters = append(characters, char.Clone())
+		}
+	}
+
+	sort.Slice(characters, func(i, j int) bool {
+		return characters[i].CreatedAt.After(characters[j].CreatedAt)
+	})
+
+	return characters, nil
+}
+
+// Get returns a character regardless of who owns it
+func (s *MemoryStore) Get(characterID string) (*Character, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	char, ok := s.characters[characterID]
+	if !ok {
+		return nil, ErrCharacterNotFound
+	}
+
+	return char.Clone(), nil
+}
+
+// Create stores a new character along with its first revision
+func (s *MemoryStore) Create(character *Character, rev *Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.characters[character.ID.String()] = character.Clone()
+	s.addRevision(character, rev)
+	return nil
+}
+
+// Update saves every stored field of an existing character
+func (s *MemoryStore) Update(character *Character, expectedVersion int, rev *Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.characters[character.ID.String()]
+	if !ok || existing.UserID != character.UserID {
+		return ErrCharacterNotFound
+	}
+	if expectedVersion != AnyVersion && existing.Version != expectedVersion {
+		return ErrVersionConflict
+	}
+
+	character.Version = existing.Version + 1
+	s.characters[character.ID.String()] = character.Clone()
+	s.addRevision(character, rev)
+	return nil
+}
+
+// Apply changes a character under the store lock
+func (s *MemoryStore) Apply(characterID string, change func(character *Character) (*Revision, error)) (*Character, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.characters[characterID]
+	if !ok {
+		return nil, ErrCharacterNotFound
+	}
+
+	char := existing.Clone()
+	rev, err := change(char)
+	if err != nil {
+		return nil, err
+	}
+
+	char.Version = existing.Version + 1
+	s.characters[characterID] = char.Clone()
+	s.addRevision(char, rev)
+	return char, nil
+}
+
+// Delete removes a character owned by the given user
+func (s *MemoryStore) Delete(characterID, userID string, expectedVersion int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	char, ok := s.characters[characterID]
+	if !ok || char.UserID.String() != userID {
+		return ErrCharacterNotFound
+	}
+	if expectedVersion != AnyVersion && char.Version != expectedVersion {
+		return ErrVersionConflict
+	}
+
+	delete(s.characters, characterID)
+	delete(s.revisions, characterID)
+	return nil
+}
+
+// ListRevisions returns a character's revisions, newest first
+func (s *MemoryStore) ListRevisions(characterID string) ([]*Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored := s.revisions[characterID]
+	revisions := make([]*Revision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		rev := *stored[i]
+		rev.Snapshot = nil
+		revisions = append(revisions, &rev)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision returns a single revision including its snapshot
+func (s *MemoryStore) GetRevision(characterID string, revision int) (*Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, stored := range s.revisions[characterID] {
+		if stored.Revision == revision {
+			rev := *stored
+			rev.Snapshot = stored.Snapshot.Clone()
+			return &rev, nil
+		}
+	}
+
+	return nil, ErrRevisionNotFound
+}
+
+// addRevision records a revision; the caller must hold the write lock
+func (s *MemoryStore) addRevision(character *Character, rev *Revision) {
+	if rev == nil {
+		return
+	}
+	rev.stamp(character)
+
+	cp := *rev
+	cp.Changes = append([]FieldChange{}, rev.Changes...)
+	cp.Snapshot = rev.Snapshot.Clone()
+	id := character.ID.String()
+	s.revisions[id] = append(s.revisions[id], &cp)
+}
@@ -0,0 +1,626 @@
+package character
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// characterColumns is the column list shared by every character SELECT
+const characterColumns = `
+	id, user_id, name, race, classes, level, background,
+	strength, dexterity, constitution, intelligence, wisdom, charisma,
+	max_hp, current_hp, armor_class, armor, shield, ac_bonuses,
+	spell_slots_expended, pact_slots_expended, feats, notes, jack_of_all_trades,
+	temp_hp, defenses, death_saves, conditions, exhaustion, speed, resources,
+	version, created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// PostgresStore is a CharacterStore backed by PostgreSQL
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a character store using the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func scanCharacter(row rowScanner) (*Character, error) {
+	char := &Character{}
+	var cols jsonColumns
+	err := row.Scan(
+		&char.ID, &char.UserID, &char.Name, &char.Race, &cols.classes,
+		&char.Level, &char.Background, &char.Strength, &char.Dexterity,
+		&char.Constitution, &char.Intelligence, &char.Wisdom, &char.Charisma,
+		&char.MaxHP, &char.CurrentHP, &char.ArmorClass, &cols.armor, &cols.shield, &cols.bonuses,
+		&cols.slots, &char.PactSlotsExpended, &cols.feats, &char.Notes, &char.JackOfAllTrades,
+		&char.TempHP, &cols.defenses, &cols.deathSaves, &cols.conditions, &char.Exhaustion, &char.Speed,
+		&cols.resources, &char.Version, &char.CreatedAt, &char.UpdatedAt,
+	)
+	if err != nil {
+		return char, err
+	}
+
+	return char, cols.decode(char)
+}
+
+// jsonColumns holds the encoded JSONB columns of a character
+type jsonColumns struct {
+	classes, armor, shield, bonuses, slots, feats, defenses, deathSaves, conditions, resources []byte
+}
+
+// encodeJSONColumns encodes the JSONB columns of a character, leaving empty
+// equipment slots NULL
+func encodeJSONColumns(c *Character) (cols jsonColumns, err error) {
+	classes := c.Classes
+	if classes == nil {
+		classes = []ClassEntry{}
+	}
+	if cols.classes, err = json.Marshal(classes); err != nil {
+		return cols, fmt.Errorf("failed to encode classes: %w", err)
+	}
+	if c.Armor != nil {
+		if cols.armor, err = json.Marshal(c.Armor); err != nil {
+			return cols, fmt.Errorf("failed to encode armor: %w", err)
+		}
+	}
+	if c.Shield != nil {
+		if cols.shield, err = json.Marshal(c.Shield); err != nil {
+			return cols, fmt.Errorf("failed to encode shield: %w", err)
+		}
+	}
+	list := c.ACBonuses
+	if list == nil {
+		list = []ACBonus{}
+	}
+	if cols.bonuses, err = json.Marshal(list); err != nil {
+		return cols, fmt.Errorf("failed to encode AC bonuses: %w", err)
+	}
+	expended := c.SpellSlotsExpended
+	if expended == nil {
+		expended = []int{}
+	}
+	if cols.slots, err = json.Marshal(expended); err != nil {
+		return cols, fmt.Errorf("failed to encode expended spell slots: %w", err)
+	}
+	feats := c.Feats
+	if feats == nil {
+		feats = []string{}
+	}
+	if cols.feats, err = json.Marshal(feats); err != nil {
+		return cols, fmt.Errorf("failed to encode feats: %w", err)
+	}
+	if cols.defenses, err = json.Marshal(c.Defenses.clone()); err != nil {
+		return cols, fmt.Errorf("failed to encode defenses: %w", err)
+	}
+	if cols.deathSaves, err = json.Marshal(c.DeathSaves); err != nil {
+		return cols, fmt.Errorf("failed to encode death saves: %w", err)
+	}
+	if cols.conditions, err = json.Marshal(cloneConditions(c.Conditions)); err != nil {
+		return cols, fmt.Errorf("failed to encode conditions: %w", err)
+	}
+	resources := c.Resources
+	if resources == nil {
+		resources = []Resource{}
+	}
+	if cols.resources, err = json.Marshal(resources); err != nil {
+		return cols, fmt.Errorf("failed to encode resources: %w", err)
+	}
+	return cols, nil
+}
+
+// decode fills in a character's fields from its JSONB columns
+func (cols jsonColumns) decode(char *Character) error {
+	char.Classes = []ClassEntry{}
+	if err := json.Unmarshal(cols.classes, &char.Classes); err != nil {
+		return fmt.Errorf("failed to decode classes: %w", err)
+	}
+	if cols.armor != nil {
+		if err := json.Unmarshal(cols.armor, &char.Armor); err != nil {
+			return fmt.Errorf("failed to decode armor: %w", err)
+		}
+	}
+	if cols.shield != nil {
+		if err := json.Unmarshal(cols.shield, &char.Shield); err != nil {
+			return fmt.Errorf("failed to decode shield: %w", err)
+		}
+	}
+	char.ACBonuses = []ACBonus{}
+	if cols.bonuses != nil {
+		if err := json.Unmarshal(cols.bonuses, &char.ACBonuses); err != nil {
+			return fmt.Errorf("failed to decode AC bonuses: %w", err)
+		}
+	}
+	if err := json.Unmarshal(cols.slots, &char.SpellSlotsExpended); err != nil {
+		return fmt.Errorf("failed to decode expended spell slots: %w", err)
+	}
+	char.Feats = []string{}
+	if err := json.Unmarshal(cols.feats, &char.Feats); err != nil {
+		return fmt.Errorf("failed to decode feats: %w", err)
+	}
+	if err := json.Unmarshal(cols.defenses, &char.Defenses); err != nil {
+		return fmt.Errorf("failed to decode defenses: %w", err)
+	}
+	char.Defenses = char.Defenses.clone()
+	if err := json.Unmarshal(cols.deathSaves, &char.DeathSaves); err != nil {
+		return fmt.Errorf("failed to decode death saves: %w", err)
+	}
+	char.Conditions = []Condition{}
+	if err := json.Unmarshal(cols.conditions, &char.Conditions); err != nil {
+		return fmt.Errorf("failed to decode conditions: %w", err)
+	}
+	char.Resources = []Resource{}
+	if err := json.Unmarshal(cols.resources, &char.Resources); err != nil {
+		return fmt.Errorf("failed to decode resources: %w", err)
+	}
+	return nil
+}
+
+// ListByUser returns a user's characters, newest first
+func (s *PostgresStore) ListByUser(userID string) ([]*Character, error) {
+	query := `SELECT ` + characterColumns + `
+		FROM characters
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query characters: %w", err)
+	}
+	defer rows.Close()
+
+	var characters []*Character
+	for rows.Next() {
+		char, err := scanCharacter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan character: %w", err)
+		}
+		characters = append(characters, char)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate characters: %w", err)
+	}
+
+	if err := s.loadProficienciesForUser(userID, characters); err != nil {
+		return nil, err
+	}
+
+	return characters, nil
+}
+
+// Get returns a character regardless of who owns it
+func (s *PostgresStore) Get(characterID string) (*Character, error) {
+	query := `SELECT ` + characterColumns + `
+		FROM characters
+		WHERE id = $1
+	`
+
+	char, err := scanCharacter(s.db.QueryRow(query, characterID))
+	if err == sql.ErrNoRows {
+		return nil, ErrCharacterNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get character: %w", err)
+	}
+
+	char.Proficiencies, err = getProficiencies(s.db, characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return char, nil
+}
+
+// Create stores a new character along with its first revision
+func (s *PostgresStore) Create(character *Character, rev *Revision) error {
+	query := `
+		INSERT INTO characters (
+			id, user_id, name, race, classes, level, background,
+			strength, dexterity, constitution, intelligence, wisdom, charisma,
+			max_hp, current_hp, armor_class, armor, shield, ac_bonuses,
+			spell_slots_expended, pact_slots_expended, feats, notes, jack_of_all_trades,
+			temp_hp, defenses, death_saves, conditions, exhaustion, speed, resources,
+			version, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
+			$17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30,
+			$31, $32, $33, $34
+		)
+	`
+
+	cols, err := encodeJSONColumns(character)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(query,
+		character.ID, character.UserID, character.Name, character.Race, cols.classes,
+		character.Level, character.Background, character.Strength,
+		character.Dexterity, character.Constitution, character.Intelligence, character.Wisdom,
+		character.Charisma, character.MaxHP, character.CurrentHP, character.ArmorClass,
+		cols.armor, cols.shield, cols.bonuses, cols.slots, character.PactSlotsExpended, cols.feats,
+		character.Notes, character.JackOfAllTrades, character.TempHP, cols.defenses, cols.deathSaves,
+		cols.conditions, character.Exhaustion, character.Speed, cols.resources,
+		character.Version, character.CreatedAt, character.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create character: %w", err)
+	}
+
+	if err := replaceProficiencies(tx, character.ID.String(), character.Proficiencies); err != nil {
+		return err
+	}
+
+	if err := insertRevision(tx, character, rev); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit character: %w", err)
+	}
+
+	return nil
+}
+
+// Update saves every stored field of an existing character
+func (s *PostgresStore) Update(character *Character, expectedVersion int, rev *Revision) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.update(tx, character, expectedVersion, rev); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit character update: %w", err)
+	}
+
+	return nil
+}
+
+// Apply changes a character while holding a row lock on it
+func (s *PostgresStore) Apply(characterID string, change func(character *Character) (*Revision, error)) (*Character, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	char, err := s.ApplyTx(tx, characterID, change)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit character update: %w", err)
+	}
+
+	return char, nil
+}
+
+// ApplyTx is Apply within tx, for changes that write other rows in the same
+// transaction. The row lock is held until tx ends.
+func (s *PostgresStore) ApplyTx(tx *sql.Tx, characterID string, change func(character *Character) (*Revision, error)) (*Character, error) {
+	query := `SELECT ` + characterColumns + `
+		FROM characters
+		WHERE id = $1
+		FOR UPDATE
+	`
+	char, err := scanCharacter(tx.QueryRow(query, characterID))
+	if err == sql.ErrNoRows {
+		return nil, ErrCharacterNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get character: %w", err)
+	}
+	char.Proficiencies, err = getProficiencies(tx, characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	rev, err := change(char)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.update(tx, char, char.Version, rev); err != nil {
+		return nil, err
+	}
+
+	return char, nil
+}
+
+// update writes a character and its revision in tx, bumping its version
+func (s *PostgresStore) update(tx *sql.Tx, character *Character, expectedVersion int, rev *Revision) error {
+	query := `
+		UPDATE characters SET
+			name = $4, race = $5, classes = $6, level = $7, background = $8,
+			strength = $9, dexterity = $10, constitution = $11, intelligence = $12,
+			wisdom = $13, charisma = $14, max_hp = $15, current_hp = $16,
+			armor_class = $17, armor = $18, shield = $19, ac_bonuses = $20,
+			spell_slots_expended = $21, pact_slots_expended = $22, feats = $23,
+			notes = $24, jack_of_all_trades = $25, temp_hp = $26, defenses = $27,
+			death_saves = $28, conditions = $29, exhaustion = $30, speed = $31,
+			resources = $32, updated_at = $33, version = version + 1
+		WHERE id = $1 AND user_id = $2 AND ($3 = 0 OR version = $3)
+		RETURNING version
+	`
+
+	cols, err := encodeJSONColumns(character)
+	if err != nil {
+		return err
+	}
+
+	var newVersion int
+	err = tx.QueryRow(query,
+		character.ID, character.UserID, expectedVersion, character.Name, character.Race,
+		cols.classes, character.Level, character.Background,
+		character.Strength, character.Dexterity, character.Constitution, character.Intelligence,
+		character.Wisdom, character.Charisma, character.MaxHP, character.CurrentHP,
+		character.ArmorClass, cols.armor, cols.shield, cols.bonuses, cols.slots, character.PactSlotsExpended,
+		cols.feats, character.Notes, character.JackOfAllTrades, character.TempHP, cols.defenses,
+		cols.deathSaves, cols.conditions, character.Exhaustion, character.Speed, cols.resources,
+		character.UpdatedAt,
+	).Scan(&newVersion)
+	if err == sql.ErrNoRows {
+		return s.missOrConflict(tx, character.ID.String(), character.UserID.String())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update character: %w", err)
+	}
+
+	if err := replaceProficiencies(tx, character.ID.String(), character.Proficiencies); err != nil {
+		return err
+	}
+
+	character.Version = newVersion
+	return insertRevision(tx, character, rev)
+}
+
+// Delete removes a character owned by the given user
+func (s *PostgresStore) Delete(characterID, userID string, expectedVersion int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"DELETE FROM characters WHERE id = $1 AND user_id = $2 AND ($3 = 0 OR version = $3)",
+		characterID, userID, expectedVersion,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete character: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return s.missOrConflict(tx, characterID, userID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit character delete: %w", err)
+	}
+
+	return nil
+}
+
+// missOrConflict explains why a versioned write matched no rows: either the
+// character doesn't exist for this user or its version has moved on
+func (s *PostgresStore) missOrConflict(tx *sql.Tx, characterID, userID string) error {
+	var exists bool
+	err := tx.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM characters WHERE id = $1 AND user_id = $2)",
+		characterID, userID,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check character: %w", err)
+	}
+	if !exists {
+		return ErrCharacterNotFound
+	}
+	return ErrVersionConflict
+}
+
+// getProficiencies loads the proficiency records for a character
+func getProficiencies(q queryer, characterID string) ([]Proficiency, error) {
+	rows, err := q.Query(
+		"SELECT kind, name, level FROM character_proficiencies WHERE character_id = $1 ORDER BY kind, name",
+		characterID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query proficiencies: %w", err)
+	}
+	defer rows.Close()
+
+	profs := []Proficiency{}
+	for rows.Next() {
+		var p Proficiency
+		if err := rows.Scan(&p.Kind, &p.Name, &p.Level); err != nil {
+			return nil, fmt.Errorf("failed to scan proficiency: %w", err)
+		}
+		profs = append(profs, p)
+	}
+
+	return profs, rows.Err()
+}
+
+// loadProficienciesForUser fills in proficiencies for a list of characters
+// belonging to the same user with a single query
+func (s *PostgresStore) loadProficienciesForUser(userID string, characters []*Character) error {
+	byID := make(map[uuid.UUID]*Character, len(characters))
+	for _, char := range characters {
+		char.Proficiencies = []Proficiency{}
+		byID[char.ID] = char
+	}
+
+	query := `
+		SELECT p.character_id, p.kind, p.name, p.level
+		FROM character_proficiencies p
+		JOIN characters c ON c.id = p.character_id
+		WHERE c.user_id = $1
+		ORDER BY p.kind, p.name
+	`
+
+	rows, err := s.db.Query(query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to query proficiencies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var characterID uuid.UUID
+		var p Proficiency
+		if err := rows.Scan(&characterID, &p.Kind, &p.Name, &p.Level); err != nil {
+			return fmt.Errorf("failed to scan proficiency: %w", err)
+		}
+		if char, ok := byID[characterID]; ok {
+			char.Proficiencies = append(char.Proficiencies, p)
+		}
+	}
+
+	return rows.Err()
+}
+
+// replaceProficiencies swaps a character's proficiencies for the given set
+func replaceProficiencies(tx *sql.Tx, characterID string, profs []Proficiency) error {
+	if _, err := tx.Exec("DELETE FROM character_proficiencies WHERE character_id = $1", characterID); err != nil {
+		return fmt.Errorf("failed to clear proficiencies: %w", err)
+	}
+
+	for _, p := range profs {
+		_, err := tx.Exec(
+			"INSERT INTO character_proficiencies (character_id, kind, name, level) VALUES ($1, $2, $3, $4)",
+			characterID, p.Kind, p.Name, p.Level,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save proficiency: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListRevisions returns a character's revisions, newest first
+func (s *PostgresStore) ListRevisions(characterID string) ([]*Revision, error) {
+	query := `
+		SELECT character_id, revision, user_id, action, restored_from, changes, created_at
+		FROM character_revisions
+		WHERE character_id = $1
+		ORDER BY revision DESC
+	`
+
+	rows, err := s.db.Query(query, characterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*Revision{}
+	for rows.Next() {
+		rev := &Revision{}
+		var changes []byte
+		err := rows.Scan(
+			&rev.CharacterID, &rev.Revision, &rev.UserID, &rev.Action,
+			&rev.RestoredFrom, &changes, &rev.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		if err := json.Unmarshal(changes, &rev.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode revision changes: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+// GetRevision returns a single revision including its snapshot
+func (s *PostgresStore) GetRevision(characterID string, revision int) (*Revision, error) {
+	query := `
+		SELECT character_id, revision, user_id, action, restored_from, changes, snapshot, created_at
+		FROM character_revisions
+		WHERE character_id = $1 AND revision = $2
+	`
+
+	rev := &Revision{}
+	var changes, snapshot []byte
+	err := s.db.QueryRow(query, characterID, revision).Scan(
+		&rev.CharacterID, &rev.Revision, &rev.UserID, &rev.Action,
+		&rev.RestoredFrom, &changes, &snapshot, &rev.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	if err := json.Unmarshal(changes, &rev.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode revision changes: %w", err)
+	}
+	if err := json.Unmarshal(snapshot, &rev.Snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode revision snapshot: %w", err)
+	}
+
+	return rev, nil
+}
+
+// insertRevision records a revision in the transaction writing the character
+func insertRevision(tx *sql.Tx, character *Character, rev *Revision) error {
+	if rev == nil {
+		return nil
+	}
+	rev.stamp(character)
+
+	changes, err := json.Marshal(rev.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision changes: %w", err)
+	}
+	snapshot, err := json.Marshal(rev.Snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision snapshot: %w", err)
+	}
+
+	query := `
+		INSERT INTO character_revisions (
+			character_id, revision, user_id, action, restored_from, changes, snapshot, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = tx.Exec(query,
+		rev.CharacterID, rev.Revision, rev.UserID, rev.Action,
+		rev.RestoredFrom, changes, snapshot, rev.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package content
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//go:embed data/*.json
+var data embed.FS
+
+// abilities are the ability names used as keys throughout the data files
+var abilities = map[string]bool{
+	"strength": true, "dexterity": true, "constitution": true,
+	"intelligence": true, "wisdom": true, "charisma": true,
+}
+
+// collection holds one kind of content in file order, indexed by both its
+// index and its lower case name
+type collection[T any] struct {
+	items []*T
+	byKey map[string]*T
+}
+
+func newCollection[T any](file string, key func(*T) (index, name string)) (*collection[T], error) {
+	raw, err := data.ReadFile("data/" + file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	var items []*T
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	c := &collection[T]{items: items, byKey: make(map[string]*T, 2*len(items))}
+	for _, item := range items {
+		index, name := key(item)
+		if index == "" || name == "" {
+			return nil, fmt.Errorf("%s: entry without index or name", file)
+		}
+		if _, ok := c.byKey[index]; ok {
+			return nil, fmt.Errorf("%s: duplicate index %q", file, index)
+		}
+		c.byKey[index] = item
+		c.byKey[strings.ToLower(name)] = item
+	}
+	return c, nil
+}
+
+// get finds an entry by index or by name, ignoring case
+func (c *collection[T]) get(key string) (*T, bool) {
+	item, ok := c.byKey[strings.ToLower(strings.TrimSpace(key))]
+	return item, ok
+}
+
+// Catalog is the SRD 5.1 content shipped with the server. It is read-only
+// once loaded and safe for concurrent use.
+type Catalog struct {
+	races       *collection[Race]
+	subraces    *collection[Subrace]
+	classes     *collection[Class]
+	subclasses  *collection[Subclass]
+	backgrounds *collection[Background]
+	spells      *collection[Spell]
+	equipment   *collection[Equipment]
+	conditions  *collection[Condition]
+}
+
+// Load reads the embedded data files and checks that their cross
+// references resolve
+func Load() (*Catalog, error) {
+	var (
+		cat Catalog
+		err error
+	)
+	if cat.races, err = newCollection("races.json", func(r *Race) (string, string) { return r.Index, r.Name }); err != nil {
+		return nil, err
+	}
+	if cat.subraces, err = newCollection("subraces.json", func(s *Subrace) (string, string) { return s.Index, s.Name }); err != nil {
+		return nil, err
+	}
+	if cat.classes, err = newCollection("classes.json", func(c *Class) (string, string) { return c.Index, c.Name }); err != nil {
+		return nil, err
+	}
+	if cat.subclasses, err = newCollection("subclasses.json", func(s *Subclass) (string, string) { return s.Index, s.Name }); err != nil {
+		return nil, err
+	}
+	if cat.backgrounds, err = newCollection("backgrounds.json", func(b *Background) (string, string) { return b.Index, b.Name }); err != nil {
+		return nil, err
+	}
+	if cat.spells, err = newCollection("spells.json", func(s *Spell) (string, string) { return s.Index, s.Name }); err != nil {
+		return nil, err
+	}
+	if cat.equipment, err = newCollection("equipment.json", func(e *Equipment) (string, string) { return e.Index, e.Name }); err != nil {
+		return nil, err
+	}
+	if cat.conditions, err = newCollection("conditions.json", func(c *Condition) (string, string) { return c.Index, c.Name }); err != nil {
+		return nil, err
+	}
+
+	if err := cat.validate(); err != nil {
+		return nil, err
+	}
+	return &cat, nil
+}
+
+// validate checks references between the data files, so a typo in one
+// fails at startup rather than as a missing entry at request time
+func (c *Catalog) validate() error {
+	for _, race := range c.races.items {
+		if err := checkAbilities(race.Index, race.AbilityBonuses); err != nil {
+			return err
+		}
+		for _, index := range race.Subraces {
+			sub, ok := c.subraces.byKey[index]
+			if !ok || sub.Race != race.Index {
+				return fmt.Errorf("race %s: unknown subrace %q", race.Index, index)
+			}
+		}
+	}
+	for _, sub := range c.subraces.items {
+		if _, ok := c.races.byKey[sub.Race]; !ok {
+			return fmt.Errorf("subrace %s: unknown race %q", sub.Index, sub.Race)
+		}
+		if err := checkAbilities(sub.Index, sub.AbilityBonuses); err != nil {
+			return err
+		}
+	}
+
+	for _, class := range c.classes.items {
+		if err := checkAbilities(class.Index, class.Multiclass.Prerequisites); err != nil {
+			return err
+		}
+		named := [][]string{class.SavingThrows, class.PrimaryAbilities}
+		if class.SpellcastingAbility != "" {
+			named = append(named, []string{class.SpellcastingAbility})
+		}
+		for _, list := range named {
+			for _, ability := range list {
+				if !abilities[ability] {
+					return fmt.Errorf("class %s: unknown ability %q", class.Index, ability)
+				}
+			}
+		}
+		for _, index := range class.Subclasses {
+			sub, ok := c.subclasses.byKey[index]
+			if !ok || sub.Class != class.Index {
+				return fmt.Errorf("class %s: unknown subclass %q", class.Index, index)
+			}
+		}
+	}
+	for _, sub := range c.subclasses.items {
+		if _, ok := c.classes.byKey[sub.Class]; !ok {
+			return fmt.Errorf("subclass %s: unknown class %q", sub.Index, sub.Class)
+		}
+	}
+
+	for _, spell := range c.spells.items {
+		if spell.Level < 0 || spell.Level > 9 {
+			return fmt.Errorf("spell %s: level %d out of range", spell.Index, spell.Level)
+		}
+		for _, class := range spell.Classes {
+			if _, ok := c.classes.byKey[class]; !ok {
+				return fmt.Errorf("spell %s: unknown class %q", spell.Index, class)
+			}
+		}
+	}
+
+	for _, item := range c.equipment.items {
+		if (item.Weapon != nil) != (item.Category == CategoryWeapon) ||
+			(item.Armor != nil) != (item.Category == CategoryArmor) {
+			return fmt.Errorf("equipment %s: stats don't match category %q", item.Index, item.Category)
+		}
+	}
+
+	return nil
+}
+
+func checkAbilities(owner string, scores map[string]int) error {
+	for ability := range scores {
+		if !abilities[ability] {
+			return fmt.Errorf("%s: unknown ability %q", owner, ability)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+// Package content serves the rules content every character is built from:
+// races, classes, backgrounds, spells, equipment and conditions.
+//
+// The data files under data/ include material taken from the System
+// Reference Document 5.1 ("SRD 5.1") by Wizards of the Coast LLC, available
+// at https://dnd.wizards.com/resources/systems-reference-document and
+// licensed under the Creative Commons Attribution 4.0 International License.
+package content
@@ -0,0 +1,91 @@
+package content
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// Handler serves the content catalog over HTTP
+type Handler struct {
+	catalog *Catalog
+}
+
+// NewHandler creates a new content handler
+func NewHandler(catalog *Catalog) *Handler {
+	return &Handler{catalog: catalog}
+}
+
+// ListRaces lists races
+func (h *Handler) ListRaces(c *gin.Context) { list(c, h.catalog.Races) }
+
+// GetRace returns a race
+func (h *Handler) GetRace(c *gin.Context) { get(c, h.catalog.Race) }
+
+// ListSubraces lists subraces, optionally of one race
+func (h *Handler) ListSubraces(c *gin.Context) { list(c, h.catalog.Subraces) }
+
+// GetSubrace returns a subrace
+func (h *Handler) GetSubrace(c *gin.Context) { get(c, h.catalog.Subrace) }
+
+// ListClasses lists classes
+func (h *Handler) ListClasses(c *gin.Context) { list(c, h.catalog.Classes) }
+
+// GetClass returns a class with its features by level
+func (h *Handler) GetClass(c *gin.Context) { get(c, h.catalog.Class) }
+
+// ListSubclasses lists subclasses, optionally of one class
+func (h *Handler) ListSubclasses(c *gin.Context) { list(c, h.catalog.Subclasses) }
+
+// GetSubclass returns a subclass
+func (h *Handler) GetSubclass(c *gin.Context) { get(c, h.catalog.Subclass) }
+
+// ListBackgrounds lists backgrounds
+func (h *Handler) ListBackgrounds(c *gin.Context) { list(c, h.catalog.Backgrounds) }
+
+// GetBackground returns a background
+func (h *Handler) GetBackground(c *gin.Context) { get(c, h.catalog.Background) }
+
+// ListSpells lists spells
+func (h *Handler) ListSpells(c *gin.Context) { list(c, h.catalog.Spells) }
+
+// GetSpell returns a spell
+func (h *Handler) GetSpell(c *gin.Context) { get(c, h.catalog.Spell) }
+
+// ListEquipment lists equipment
+func (h *Handler) ListEquipment(c *gin.Context) { list(c, h.catalog.Equipment) }
+
+// GetEquipment returns a piece of equipment
+func (h *Handler) GetEquipment(c *gin.Context) { get(c, h.catalog.Item) }
+
+// ListConditions lists conditions
+func (h *Handler) ListConditions(c *gin.Context) { list(c, h.catalog.Conditions) }
+
+// GetCondition returns a condition
+func (h *Handler) GetCondition(c *gin.Context) { get(c, h.catalog.Condition) }
+
+// list binds a filter from the query string and responds with the page
+// listing returns for it
+func list[F, T any](c *gin.Context, listing func(*F) *Page[T]) {
+	var filter F
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, listing(&filter))
+}
+
+// get responds with the entry lookup finds for the :index parameter, which
+// may also be the entry's name
+func get[T any](c *gin.Context, lookup func(key string) (*T, error)) {
+	entry, err := lookup(c.Param("index"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
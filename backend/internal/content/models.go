@@ -0,0 +1,178 @@
+package content
+
+// Equipment categories
+const (
+	CategoryWeapon          = "weapon"
+	CategoryArmor           = "armor"
+	CategoryAdventuringGear = "adventuring_gear"
+	CategoryTool            = "tool"
+	CategoryMount           = "mount"
+)
+
+// Trait is a named racial or background feature
+type Trait struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Feature is a class or subclass feature gained at a class level
+type Feature struct {
+	Level int    `json:"level"`
+	Name  string `json:"name"`
+}
+
+// Choice lets a player pick Count entries from a list
+type Choice struct {
+	Count int      `json:"count"`
+	From  []string `json:"from"`
+}
+
+// Race is a playable race. Ability bonuses are keyed by ability name.
+type Race struct {
+	Index          string         `json:"index"`
+	Name           string         `json:"name"`
+	Size           string         `json:"size"`
+	Speed          int            `json:"speed"`
+	Darkvision     int            `json:"darkvision,omitempty"`
+	AbilityBonuses map[string]int `json:"ability_bonuses"`
+	// AbilityBonusChoice is for races like the half-elf that raise
+	// abilities of the player's choosing by one
+	AbilityBonusChoice *Choice  `json:"ability_bonus_choice,omitempty"`
+	Languages          []string `json:"languages"`
+	LanguageChoices    int      `json:"language_choices,omitempty"`
+	Traits             []Trait  `json:"traits"`
+	Subraces           []string `json:"subraces"`
+}
+
+// Subrace refines a race with further bonuses and traits
+type Subrace struct {
+	Index          string         `json:"index"`
+	Name           string         `json:"name"`
+	Race           string         `json:"race"`
+	AbilityBonuses map[string]int `json:"ability_bonuses"`
+	Traits         []Trait        `json:"traits"`
+}
+
+// Multiclass lists what a character needs to take a level in a class as a
+// second or later class, and the proficiencies they gain from it
+type Multiclass struct {
+	// Prerequisites are minimum ability scores. With AnyPrerequisite set
+	// meeting one of them is enough (the fighter's Strength or Dexterity).
+	Prerequisites   map[string]int `json:"prerequisites"`
+	AnyPrerequisite bool           `json:"any_prerequisite,omitempty"`
+	Proficiencies   []string       `json:"proficiencies"`
+}
+
+// Class is a playable class with its level progression
+type Class struct {
+	Index               string     `json:"index"`
+	Name                string     `json:"name"`
+	HitDie              int        `json:"hit_die"`
+	PrimaryAbilities    []string   `json:"primary_abilities"`
+	SavingThrows        []string   `json:"saving_throws"`
+	SpellcastingAbility string     `json:"spellcasting_ability,omitempty"`
+	ArmorProficiencies  []string   `json:"armor_proficiencies"`
+	WeaponProficiencies []string   `json:"weapon_proficiencies"`
+	ToolProficiencies   []string   `json:"tool_proficiencies"`
+	SkillChoices        Choice     `json:"skill_choices"`
+	Multiclass          Multiclass `json:"multiclass"`
+	// SubclassLevel is the class level the subclass is chosen at
+	SubclassLevel int `json:"subclass_level"`
+	// ASILevels are the class levels granting an Ability Score Improvement
+	ASILevels  []int     `json:"asi_levels"`
+	Features   []Feature `json:"features"`
+	Subclasses []string  `json:"subclasses"`
+}
+
+// Subclass is a class specialization such as a domain or archetype
+type Subclass struct {
+	Index    string    `json:"index"`
+	Name     string    `json:"name"`
+	Class    string    `json:"class"`
+	Features []Feature `json:"features"`
+}
+
+// Background is a character background
+type Background struct {
+	Index              string   `json:"index"`
+	Name               string   `json:"name"`
+	SkillProficiencies []string `json:"skill_proficiencies"`
+	ToolProficiencies  []string `json:"tool_proficiencies"`
+	LanguageChoices    int      `json:"language_choices,omitempty"`
+	Equipment          []string `json:"equipment"`
+	Feature            Trait    `json:"feature"`
+}
+
+// Spell is a spell from the SRD spell lists. Level 0 is a cantrip.
+type Spell struct {
+	Index         string   `json:"index"`
+	Name          string   `json:"name"`
+	Level         int      `json:"level"`
+	School        string   `json:"school"`
+	CastingTime   string   `json:"casting_time"`
+	Range         string   `json:"range"`
+	Components    []string `json:"components"`
+	Duration      string   `json:"duration"`
+	Concentration bool     `json:"concentration"`
+	Ritual        bool     `json:"ritual"`
+	Classes       []string `json:"classes"`
+}
+
+// Damage is a weapon's damage roll
+type Damage struct {
+	Dice string `json:"dice"`
+	Type string `json:"type"`
+}
+
+// Range is a ranged or thrown weapon's normal and long range in feet
+type Range struct {
+	Normal int `json:"normal"`
+	Long   int `json:"long"`
+}
+
+// WeaponStats describe an item in the weapon category
+type WeaponStats struct {
+	// Category is simple or martial, Range melee or ranged
+	Category   string   `json:"category"`
+	Range      string   `json:"range"`
+	Damage     *Damage  `json:"damage,omitempty"`
+	Versatile  string   `json:"versatile,omitempty"`
+	Properties []string `json:"properties"`
+	Distance   *Range   `json:"distance,omitempty"`
+}
+
+// ArmorStats describe an item in the armor category. Type is light,
+// medium, heavy or shield, matching the character's armor types.
+type ArmorStats struct {
+	Type                string `json:"type"`
+	BaseAC              int    `json:"base_ac"`
+	StrengthMinimum     int    `json:"strength_minimum,omitempty"`
+	StealthDisadvantage bool   `json:"stealth_disadvantage"`
+}
+
+// Equipment is a piece of mundane gear. CostCP is in copper pieces, like
+// item and purse amounts.
+type Equipment struct {
+	Index    string       `json:"index"`
+	Name     string       `json:"name"`
+	Category string       `json:"category"`
+	CostCP   int          `json:"cost_cp"`
+	Weight   float64      `json:"weight"`
+	Weapon   *WeaponStats `json:"weapon,omitempty"`
+	Armor    *ArmorStats  `json:"armor,omitempty"`
+}
+
+// Condition is one of the SRD conditions and its rules
+type Condition struct {
+	Index       string   `json:"index"`
+	Name        string   `json:"name"`
+	Description []string `json:"description"`
+}
+
+// Page is one page of a content listing
+type Page[T any] struct {
+	Results []*T `json:"results"`
+	Total   int  `json:"total"`
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+}
@@ -0,0 +1,242 @@
+package content
+
+import (
+	"strings"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// DefaultLimit is the page size when a listing doesn't ask for one
+const DefaultLimit = 100
+
+// Errors for lookups of content that doesn't exist
+var (
+	ErrRaceNotFound       = apperror.NotFound("race_not_found", "Race not found")
+	ErrSubraceNotFound    = apperror.NotFound("subrace_not_found", "Subrace not found")
+	ErrClassNotFound      = apperror.NotFound("class_not_found", "Class not found")
+	ErrSubclassNotFound   = apperror.NotFound("subclass_not_found", "Subclass not found")
+	ErrBackgroundNotFound = apperror.NotFound("background_not_found", "Background not found")
+	ErrSpellNotFound      = apperror.NotFound("spell_not_found", "Spell not found")
+	ErrEquipmentNotFound  = apperror.NotFound("equipment_not_found", "Equipment not found")
+	ErrConditionNotFound  = apperror.NotFound("condition_not_found", "Condition not found")
+)
+
+// Filter is shared by every listing. Search matches names case-insensitively.
+type Filter struct {
+	Search string `form:"search" binding:"max=100"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// SubraceFilter narrows subraces to one race
+type SubraceFilter struct {
+	Filter
+	Race string `form:"race"`
+}
+
+// SubclassFilter narrows subclasses to one class
+type SubclassFilter struct {
+	Filter
+	Class string `form:"class"`
+}
+
+// SpellFilter narrows the spell list
+type SpellFilter struct {
+	Filter
+	Level         *int   `form:"level" binding:"omitempty,min=0,max=9"`
+	School        string `form:"school"`
+	Class         string `form:"class"`
+	Ritual        *bool  `form:"ritual"`
+	Concentration *bool  `form:"concentration"`
+}
+
+// EquipmentFilter narrows the equipment list. Property matches weapon
+// properties such as finesse or thrown.
+type EquipmentFilter struct {
+	Filter
+	Category       string `form:"category" binding:"omitempty,oneof=weapon armor adventuring_gear tool mount"`
+	WeaponCategory string `form:"weapon_category" binding:"omitempty,oneof=simple martial"`
+	ArmorType      string `form:"armor_type" binding:"omitempty,oneof=light medium heavy shield"`
+	Property       string `form:"property"`
+}
+
+// Races lists races
+func (c *Catalog) Races(f *Filter) *Page[Race] {
+	return page(c.races.items, f, func(r *Race) string { return r.Name }, nil)
+}
+
+// Race finds a race by index or name
+func (c *Catalog) Race(key string) (*Race, error) {
+	return find(c.races, key, ErrRaceNotFound)
+}
+
+// Subraces lists subraces
+func (c *Catalog) Subraces(f *SubraceFilter) *Page[Subrace] {
+	race := c.raceIndex(f.Race)
+	return page(c.subraces.items, &f.Filter, func(s *Subrace) string { return s.Name }, func(s *Subrace) bool {
+		return f.Race == "" || s.Race == race
+	})
+}
+
+// Subrace finds a subrace by index or name
+func (c *Catalog) Subrace(key string) (*Subrace, error) {
+	return find(c.subraces, key, ErrSubraceNotFound)
+}
+
+// Classes lists classes
+func (c *Catalog) Classes(f *Filter) *Page[Class] {
+	return page(c.classes.items, f, func(cl *Class) string { return cl.Name }, nil)
+}
+
+// Class finds a class by index or name
+func (c *Catalog) Class(key string) (*Class, error) {
+	return find(c.classes, key, ErrClassNotFound)
+}
+
+// Subclasses lists subclasses
+func (c *Catalog) Subclasses(f *SubclassFilter) *Page[Subclass] {
+	class := c.classIndex(f.Class)
+	return page(c.subclasses.items, &f.Filter, func(s *Subclass) string { return s.Name }, func(s *Subclass) bool {
+		return f.Class == "" || s.Class == class
+	})
+}
+
+// Subclass finds a subclass by index or name
+func (c *Catalog) Subclass(key string) (*Subclass, error) {
+	return find(c.subclasses, key, ErrSubclassNotFound)
+}
+
+// Backgrounds lists backgrounds
+func (c *Catalog) Backgrounds(f *Filter) *Page[Background] {
+	return page(c.backgrounds.items, f, func(b *Background) string { return b.Name }, nil)
+}
+
+// Background finds a background by index or name
+func (c *Catalog) Background(key string) (*Background, error) {
+	return find(c.backgrounds, key, ErrBackgroundNotFound)
+}
+
+// Spells lists spells by level, then name
+func (c *Catalog) Spells(f *SpellFilter) *Page[Spell] {
+	class := c.classIndex(f.Class)
+	return page(c.spells.items, &f.Filter, func(s *Spell) string { return s.Name }, func(s *Spell) bool {
+		if f.Level != nil && s.Level != *f.Level {
+			return false
+		}
+		if f.School != "" && !strings.EqualFold(s.School, f.School) {
+			return false
+		}
+		if f.Ritual != nil && s.Ritual != *f.Ritual {
+			return false
+		}
+		if f.Concentration != nil && s.Concentration != *f.Concentration {
+			return false
+		}
+		if f.Class != "" {
+			for _, index := range s.Classes {
+				if index == class {
+					return true
+				}
+			}
+			return false
+		}
+		return true
+	})
+}
+
+// Spell finds a spell by index or name
+func (c *Catalog) Spell(key string) (*Spell, error) {
+	return find(c.spells, key, ErrSpellNotFound)
+}
+
+// Equipment lists equipment
+func (c *Catalog) Equipment(f *EquipmentFilter) *Page[Equipment] {
+	return page(c.equipment.items, &f.Filter, func(e *Equipment) string { return e.Name }, func(e *Equipment) bool {
+		if f.Category != "" && e.Category != f.Category {
+			return false
+		}
+		if f.WeaponCategory != "" && (e.Weapon == nil || e.Weapon.Category != f.WeaponCategory) {
+			return false
+		}
+		if f.ArmorType != "" && (e.Armor == nil || e.Armor.Type != f.ArmorType) {
+			return false
+		}
+		if f.Property != "" {
+			if e.Weapon == nil {
+				return false
+			}
+			for _, property := range e.Weapon.Properties {
+				if strings.EqualFold(property, f.Property) {
+					return true
+				}
+			}
+			return false
+		}
+		return true
+	})
+}
+
+// Item finds a piece of equipment by index or name
+func (c *Catalog) Item(key string) (*Equipment, error) {
+	return find(c.equipment, key, ErrEquipmentNotFound)
+}
+
+// Conditions lists conditions
+func (c *Catalog) Conditions(f *Filter) *Page[Condition] {
+	return page(c.conditions.items, f, func(co *Condition) string { return co.Name }, nil)
+}
+
+// Condition finds a condition by index or name
+func (c *Catalog) Condition(key string) (*Condition, error) {
+	return find(c.conditions, key, ErrConditionNotFound)
+}
+
+// raceIndex resolves a race name to its index. Unknown keys are returned
+// as they are, so they match nothing.
+func (c *Catalog) raceIndex(key string) string {
+	if race, ok := c.races.get(key); ok {
+		return race.Index
+	}
+	return key
+}
+
+// classIndex resolves a class name to its index. Unknown keys are returned
+// as they are, so they match nothing.
+func (c *Catalog) classIndex(key string) string {
+	if class, ok := c.classes.get(key); ok {
+		return class.Index
+	}
+	return key
+}
+
+func find[T any](c *collection[T], key string, notFound error) (*T, error) {
+	item, ok := c.get(key)
+	if !ok {
+		return nil, notFound
+	}
+	return item, nil
+}
+
+// page filters items, then applies the search and the page window
+func page[T any](items []*T, f *Filter, name func(*T) string, keep func(*T) bool) *Page[T] {
+	search := strings.ToLower(strings.TrimSpace(f.Search))
+	limit := f.Limit
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	p := &Page[T]{Results: []*T{}, Limit: limit, Offset: f.Offset}
+	for _, item := range items {
+		if keep != nil && !keep(item) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(name(item)), search) {
+			continue
+		}
+		if p.Total >= f.Offset && len(p.Results) < limit {
+			p.Results = append(p.Results, item)
+		}
+		p.Total++
+	}
+	return p
+}
@@ -0,0 +1,169 @@
+package content
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func loadCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	catalog, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned %v", err)
+	}
+	return catalog
+}
+
+// names lists a page's results by name, or by whatever name returns
+func names[T any](p *Page[T], name func(*T) string) []string {
+	list := []string{}
+	for _, item := range p.Results {
+		list = append(list, name(item))
+	}
+	return list
+}
+
+func TestLookup(t *testing.T) {
+	c := loadCatalog(t)
+	race := byIndex(c.Race, func(r *Race) string { return r.Index })
+	class := byIndex(c.Class, func(cl *Class) string { return cl.Index })
+	spell := byIndex(c.Spell, func(s *Spell) string { return s.Index })
+	item := byIndex(c.Item, func(e *Equipment) string { return e.Index })
+	subrace := byIndex(c.Subrace, func(s *Subrace) string { return s.Index })
+	subclass := byIndex(c.Subclass, func(s *Subclass) string { return s.Index })
+	background := byIndex(c.Background, func(b *Background) string { return b.Index })
+	condition := byIndex(c.Condition, func(co *Condition) string { return co.Index })
+
+	tests := []struct {
+		name   string
+		lookup func(string) (string, error)
+		key    string
+		want   string
+		err    error
+	}{
+		{"race by index", race, "half-elf", "half-elf", nil},
+		{"race by name", race, "Half-Elf", "half-elf", nil},
+		{"race by name in any case", race, "  HALF-ELF ", "half-elf", nil},
+		{"class by index", class, "wizard", "wizard", nil},
+		{"class by name", class, "Wizard", "wizard", nil},
+		{"spell by index", spell, "magic-missile", "magic-missile", nil},
+		{"spell by name", spell, "magic missile", "magic-missile", nil},
+		{"item by name", item, "crossbow, LIGHT", "crossbow-light", nil},
+		{"subrace by name", subrace, "High Elf", "high-elf", nil},
+		{"subclass by name", subclass, "Champion", "champion", nil},
+		{"background by index", background, "acolyte", "acolyte", nil},
+		{"condition by name", condition, "Prone", "prone", nil},
+		{"unknown race", race, "orc", "", ErrRaceNotFound},
+		{"unknown class", class, "gunslinger", "", ErrClassNotFound},
+		{"unknown spell", spell, "magic-missle", "", ErrSpellNotFound},
+		{"unknown item", item, "", "", ErrEquipmentNotFound},
+		{"a class index as a spell", spell, "wizard", "", ErrSpellNotFound},
+	}
+	for _, tt := range tests {
+		got, err := tt.lookup(tt.key)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("%s: %q found %q, %v, want %q, %v", tt.name, tt.key, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+// byIndex turns a lookup into one returning the index of the entry found
+func byIndex[T any](find func(key string) (*T, error), index func(*T) string) func(key string) (string, error) {
+	return func(key string) (string, error) {
+		entry, err := find(key)
+		if err != nil {
+			return "", err
+		}
+		return index(entry), nil
+	}
+}
+
+func TestListPaging(t *testing.T) {
+	c := loadCatalog(t)
+	all := names(c.Races(&Filter{}), func(r *Race) string { return r.Index })
+	if len(all) != 9 {
+		t.Fatalf("listed %d races, want 9", len(all))
+	}
+
+	p := c.Races(&Filter{Limit: 2, Offset: 3})
+	if got := names(p, func(r *Race) string { return r.Index }); !reflect.DeepEqual(got, all[3:5]) {
+		t.Errorf("page = %v, want %v", got, all[3:5])
+	}
+	if p.Total != 9 || p.Limit != 2 || p.Offset != 3 {
+		t.Errorf("page total %d, limit %d, offset %d, want 9, 2, 3", p.Total, p.Limit, p.Offset)
+	}
+	if p := c.Spells(&SpellFilter{}); len(p.Results) != DefaultLimit || p.Limit != DefaultLimit || p.Total <= DefaultLimit {
+		t.Errorf("default spell page has %d of %d, limit %d", len(p.Results), p.Total, p.Limit)
+	}
+	if p := c.Races(&Filter{Offset: 20}); len(p.Results) != 0 || p.Total != 9 {
+		t.Errorf("a page past the end has %d of %d", len(p.Results), p.Total)
+	}
+}
+
+func TestSearchAndFilters(t *testing.T) {
+	c := loadCatalog(t)
+	level3, cantrip := 3, 0
+	yes, no := true, false
+	spellNames := func(f *SpellFilter) []string {
+		f.Limit = 500
+		return names(c.Spells(f), func(s *Spell) string { return s.Name })
+	}
+	itemNames := func(f *EquipmentFilter) []string {
+		f.Limit = 500
+		return names(c.Equipment(f), func(e *Equipment) string { return e.Name })
+	}
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"search ignores case", itemNames(&EquipmentFilter{Filter: Filter{Search: " SWORD "}}), []string{"Shortsword", "Longsword", "Greatsword"}},
+		{"weapon property", itemNames(&EquipmentFilter{Property: "Finesse"}), []string{"Dagger", "Dart", "Rapier", "Scimitar", "Shortsword", "Whip"}},
+		{"armor type", itemNames(&EquipmentFilter{ArmorType: "heavy"}), []string{"Ring mail", "Chain mail", "Splint armor", "Plate armor"}},
+		{"weapon category and search", itemNames(&EquipmentFilter{WeaponCategory: "martial", Filter: Filter{Search: "sword"}}), []string{"Longsword", "Greatsword", "Shortsword"}},
+		{"level, school and class", spellNames(&SpellFilter{Level: &level3, School: "Evocation", Class: "Wizard"}),
+			[]string{"Fireball", "Lightning Bolt", "Sending", "Tiny Hut"}},
+		{"an unknown class", spellNames(&SpellFilter{Class: "gunslinger"}), []string{}},
+		{"subraces of a race by name", names(c.Subraces(&SubraceFilter{Race: "Elf"}), func(s *Subrace) string { return s.Index }), []string{"high-elf"}},
+		{"subclasses of a class by index", names(c.Subclasses(&SubclassFilter{Class: "fighter"}), func(s *Subclass) string { return s.Index }), []string{"champion"}},
+	}
+	for _, tt := range tests {
+		// Listings are in data file order, which the test doesn't depend on
+		got := append([]string{}, tt.got...)
+		want := append([]string{}, tt.want...)
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// Boolean and level filters keep only matching spells
+	checks := []struct {
+		name   string
+		filter *SpellFilter
+		keep   func(s *Spell) bool
+	}{
+		{"cantrips", &SpellFilter{Level: &cantrip}, func(s *Spell) bool { return s.Level == 0 }},
+		{"rituals", &SpellFilter{Ritual: &yes}, func(s *Spell) bool { return s.Ritual }},
+		{"not concentration", &SpellFilter{Concentration: &no, Class: "cleric"}, func(s *Spell) bool {
+			return !s.Concentration && slices.Contains(s.Classes, "cleric")
+		}},
+	}
+	for _, tt := range checks {
+		tt.filter.Limit = 500
+		p := c.Spells(tt.filter)
+		if len(p.Results) == 0 || p.Total != len(p.Results) {
+			t.Errorf("%s: listed %d of %d", tt.name, len(p.Results), p.Total)
+		}
+		for _, spell := range p.Results {
+			if !tt.keep(spell) {
+				t.Errorf("%s: listed %s", tt.name, spell.Name)
+			}
+		}
+	}
+}
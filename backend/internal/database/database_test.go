@@ -0,0 +1,104 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// migrationFS builds a file system of migrations named by their file names,
+// each holding some SQL
+func migrationFS(names ...string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, name := range names {
+		fsys["migrations/"+name] = &fstest.MapFile{Data: []byte("-- " + name)}
+	}
+	return fsys
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := migrationFS(
+		"0002_proficiencies.down.sql",
+		"0010_armor.up.sql",
+		"0001_create_users.up.sql",
+		"0003_version.up.sql",
+		"0002_proficiencies.up.sql",
+		"0001_create_users.down.sql",
+		"0003_version.down.sql",
+		"0004_revisions.up.sql",
+		"0004_revisions.down.sql",
+		"0005_sessions.up.sql",
+		"0005_sessions.down.sql",
+		"0006_campaigns.up.sql",
+		"0006_campaigns.down.sql",
+		"0007_rolls.up.sql",
+		"0007_rolls.down.sql",
+		"0008_items.up.sql",
+		"0008_items.down.sql",
+		"0009_purses.up.sql",
+		"0009_purses.down.sql",
+		"0010_armor.down.sql",
+	)
+	// An irreversible migration may have an empty down script
+	fsys["migrations/0009_purses.down.sql"].Data = nil
+
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []int
+	for _, mig := range migrations {
+		versions = append(versions, mig.Version)
+	}
+	// Versions sort numerically, not by file name order
+	if want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}; !reflect.DeepEqual(versions, want) {
+		t.Fatalf("versions = %v, want %v", versions, want)
+	}
+	want := Migration{Version: 2, Name: "proficiencies", Up: "-- 0002_proficiencies.up.sql", Down: "-- 0002_proficiencies.down.sql"}
+	if migrations[1] != want {
+		t.Errorf("migration 2 = %+v, want %+v", migrations[1], want)
+	}
+	if migrations[8].Down != "" || migrations[9].Name != "armor" {
+		t.Errorf("migrations 9 and 10 = %+v, %+v", migrations[8], migrations[9])
+	}
+}
+
+func TestLoadMigrationsRejected(t *testing.T) {
+	tests := []struct {
+		name  string
+		fsys  fstest.MapFS
+		error string
+	}{
+		{"no down script", migrationFS("0001_a.up.sql", "0001_a.down.sql", "0002_b.up.sql"), "0002_b has no down script"},
+		{"no up script", migrationFS("0001_a.up.sql", "0001_a.down.sql", "0002_b.down.sql"), "0002_b has no up script"},
+		{"an empty up script", fstest.MapFS{
+			"migrations/0001_a.up.sql":   {Data: []byte(" \n")},
+			"migrations/0001_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		}, "0001_a has an empty up script"},
+		{"a gap", migrationFS("0001_a.up.sql", "0001_a.down.sql", "0003_c.up.sql", "0003_c.down.sql"), "0003_c should be version 2"},
+		{"not starting at 1", migrationFS("0002_b.up.sql", "0002_b.down.sql"), "0002_b should be version 1"},
+		{"a version used twice", migrationFS("0001_a.up.sql", "0001_a.down.sql", "0001_b.up.sql", "0001_b.down.sql"), "version 1 is used by both a and b"},
+		{"a script written twice", migrationFS("0001_a.up.sql", "1_a.up.sql", "0001_a.down.sql"), "version 1 has two up scripts"},
+		{"another extension", migrationFS("0001_a.up.sql", "0001_a.down.sql", "0002_b.sql"), "0002_b.sql must end in .up.sql or .down.sql"},
+		{"no name", migrationFS("0001.up.sql", "0001.down.sql"), "0001.down.sql must be named NNNN_name"},
+		{"a version that isn't a number", migrationFS("one_a.up.sql", "one_a.down.sql"), "one_a.down.sql has an invalid version"},
+	}
+
+	for _, tt := range tests {
+		_, err := loadMigrations(tt.fsys)
+		if err == nil || !strings.Contains(err.Error(), tt.error) {
+			t.Errorf("%s: loadMigrations returned %v, want an error containing %q", tt.name, err, tt.error)
+		}
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		t.Fatalf("the embedded migrations don't load: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS characters;
+DROP TABLE IF EXISTS users;
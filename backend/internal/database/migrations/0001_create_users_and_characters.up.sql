@@ -0,0 +1,48 @@
+-- gen_random_uuid() is built in from PostgreSQL 13; pgcrypto provides it on
+-- older servers
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS characters (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    race VARCHAR(100) NOT NULL,
+    class VARCHAR(100) NOT NULL,
+    level INTEGER NOT NULL DEFAULT 1,
+    background VARCHAR(100) NOT NULL,
+
+    -- Ability scores
+    strength INTEGER NOT NULL DEFAULT 10,
+    dexterity INTEGER NOT NULL DEFAULT 10,
+    constitution INTEGER NOT NULL DEFAULT 10,
+    intelligence INTEGER NOT NULL DEFAULT 10,
+    wisdom INTEGER NOT NULL DEFAULT 10,
+    charisma INTEGER NOT NULL DEFAULT 10,
+
+    -- Combat stats
+    max_hp INTEGER DEFAULT 0,
+    current_hp INTEGER DEFAULT 0,
+    armor_class INTEGER DEFAULT 10,
+
+    -- Additional fields
+    notes TEXT,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Databases created by the old init script use uuid_generate_v4()
+ALTER TABLE users ALTER COLUMN id SET DEFAULT gen_random_uuid();
+ALTER TABLE characters ALTER COLUMN id SET DEFAULT gen_random_uuid();
+
+CREATE INDEX IF NOT EXISTS idx_characters_user_id ON characters(user_id);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS character_proficiencies;
+ALTER TABLE characters DROP COLUMN IF EXISTS jack_of_all_trades;
@@ -0,0 +1,9 @@
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS jack_of_all_trades BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS character_proficiencies (
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('skill', 'save')),
+    name VARCHAR(50) NOT NULL,
+    level VARCHAR(20) NOT NULL CHECK (level IN ('half', 'proficient', 'expertise')),
+    PRIMARY KEY (character_id, kind, name)
+);
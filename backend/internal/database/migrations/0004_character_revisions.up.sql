@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS character_revisions (
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    restored_from INTEGER,
+    changes JSONB NOT NULL DEFAULT '[]',
+    snapshot JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (character_id, revision)
+);
+
+-- Give existing characters a starting revision so later changes can be
+-- diffed and restored against it
+INSERT INTO character_revisions (character_id, revision, user_id, action, snapshot, created_at)
+SELECT c.id, c.version, c.user_id, 'create',
+       to_jsonb(c) || jsonb_build_object('proficiencies', COALESCE(
+           (SELECT jsonb_agg(jsonb_build_object('kind', p.kind, 'name', p.name, 'level', p.level))
+            FROM character_proficiencies p WHERE p.character_id = c.id),
+           '[]'::jsonb)),
+       c.updated_at
+FROM characters c
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    invite_code VARCHAR(16) NOT NULL UNIQUE,
+    dm_can_edit BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS campaign_members (
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('dm', 'player')),
+    joined_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (campaign_id, user_id)
+);
+
+-- A character belongs to at most one campaign, and leaves it when its owner
+-- leaves the campaign
+CREATE TABLE IF NOT EXISTS campaign_characters (
+    character_id UUID PRIMARY KEY REFERENCES characters(id) ON DELETE CASCADE,
+    campaign_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    attached_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    FOREIGN KEY (campaign_id, user_id) REFERENCES campaign_members(campaign_id, user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_owner_id ON campaigns(owner_id);
+CREATE INDEX IF NOT EXISTS idx_campaign_members_user_id ON campaign_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_campaign_characters_campaign_id ON campaign_characters(campaign_id);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS rolls (
+    id UUID PRIMARY KEY,
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    name VARCHAR(50) NOT NULL DEFAULT '',
+    label VARCHAR(100),
+    expression VARCHAR(255) NOT NULL,
+    total INTEGER NOT NULL,
+    natural_d20 INTEGER,
+    breakdown JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rolls_character_created ON rolls(character_id, created_at DESC);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS items (
+    id UUID PRIMARY KEY,
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    container_id UUID REFERENCES items(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity >= 0),
+    weight NUMERIC(10, 2) NOT NULL DEFAULT 0 CHECK (weight >= 0),
+    cost_cp INTEGER NOT NULL DEFAULT 0 CHECK (cost_cp >= 0),
+    equipped BOOLEAN NOT NULL DEFAULT FALSE,
+    attuned BOOLEAN NOT NULL DEFAULT FALSE,
+    is_container BOOLEAN NOT NULL DEFAULT FALSE,
+    weightless_contents BOOLEAN NOT NULL DEFAULT FALSE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_items_character_id ON items(character_id);
+CREATE INDEX IF NOT EXISTS idx_items_container_id ON items(container_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS purse_transactions;
+DROP TABLE IF EXISTS purses;
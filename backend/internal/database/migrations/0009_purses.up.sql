@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS purses (
+    character_id UUID PRIMARY KEY REFERENCES characters(id) ON DELETE CASCADE,
+    cp INTEGER NOT NULL DEFAULT 0 CHECK (cp >= 0),
+    sp INTEGER NOT NULL DEFAULT 0 CHECK (sp >= 0),
+    ep INTEGER NOT NULL DEFAULT 0 CHECK (ep >= 0),
+    gp INTEGER NOT NULL DEFAULT 0 CHECK (gp >= 0),
+    pp INTEGER NOT NULL DEFAULT 0 CHECK (pp >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS purse_transactions (
+    id UUID PRIMARY KEY,
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    amount JSONB NOT NULL,
+    delta JSONB NOT NULL,
+    balance JSONB NOT NULL,
+    counterparty_id UUID REFERENCES characters(id) ON DELETE SET NULL,
+    note VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_purse_transactions_character_created ON purse_transactions(character_id, created_at DESC);
@@ -0,0 +1,4 @@
+ALTER TABLE characters ALTER COLUMN armor_class SET DEFAULT 10;
+ALTER TABLE characters DROP COLUMN IF EXISTS ac_bonuses;
+ALTER TABLE characters DROP COLUMN IF EXISTS shield;
+ALTER TABLE characters DROP COLUMN IF EXISTS armor;
@@ -0,0 +1,8 @@
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS armor JSONB;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS shield JSONB;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS ac_bonuses JSONB NOT NULL DEFAULT '[]';
+
+-- armor_class is now an optional override of the computed AC. Existing
+-- values are kept so characters don't change AC until the override is
+-- cleared.
+ALTER TABLE characters ALTER COLUMN armor_class DROP DEFAULT;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS character_spells;
+ALTER TABLE characters DROP COLUMN IF EXISTS pact_slots_expended;
+ALTER TABLE characters DROP COLUMN IF EXISTS spell_slots_expended;
+ALTER TABLE characters DROP COLUMN IF EXISTS subclass;
@@ -0,0 +1,17 @@
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS subclass VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS spell_slots_expended JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS pact_slots_expended INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS character_spells (
+    id UUID PRIMARY KEY,
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    level INTEGER NOT NULL CHECK (level BETWEEN 0 AND 9),
+    class VARCHAR(100) NOT NULL,
+    prepared BOOLEAN NOT NULL DEFAULT FALSE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_character_spells_character_id ON character_spells(character_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS character_packs;
+DROP TABLE IF EXISTS homebrew_pack_versions;
+DROP TABLE IF EXISTS homebrew_packs;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS homebrew_packs (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    campaign_id UUID REFERENCES campaigns(id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    latest_version INTEGER NOT NULL DEFAULT 1,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_homebrew_packs_owner_id ON homebrew_packs(owner_id);
+CREATE INDEX IF NOT EXISTS idx_homebrew_packs_campaign_id ON homebrew_packs(campaign_id);
+
+CREATE TABLE IF NOT EXISTS homebrew_pack_versions (
+    pack_id UUID NOT NULL REFERENCES homebrew_packs(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL CHECK (version >= 1),
+    content JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (pack_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS character_packs (
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    pack_id UUID NOT NULL,
+    version INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (character_id, pack_id),
+    FOREIGN KEY (pack_id, version) REFERENCES homebrew_pack_versions(pack_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_character_packs_pack_id ON character_packs(pack_id);
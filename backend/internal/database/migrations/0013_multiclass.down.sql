@@ -0,0 +1,10 @@
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS class VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS subclass VARCHAR(100) NOT NULL DEFAULT '';
+
+-- Multiclass characters keep only the class they started with
+UPDATE characters SET
+    class = COALESCE(classes->0->>'class', ''),
+    subclass = COALESCE(classes->0->>'subclass', ''),
+    level = COALESCE((classes->0->>'levels')::INTEGER, level);
+
+ALTER TABLE characters DROP COLUMN IF EXISTS classes;
@@ -0,0 +1,22 @@
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS classes JSONB NOT NULL DEFAULT '[]';
+
+-- Every existing character becomes a single class entry. Hit dice come from
+-- the SRD; classes it doesn't have get a d8 and can be corrected on the sheet.
+UPDATE characters SET classes = jsonb_build_array(jsonb_build_object(
+    'class', class,
+    'subclass', subclass,
+    'levels', level,
+    'hit_die', CASE lower(trim(class))
+        WHEN 'barbarian' THEN 12
+        WHEN 'fighter' THEN 10
+        WHEN 'paladin' THEN 10
+        WHEN 'ranger' THEN 10
+        WHEN 'sorcerer' THEN 6
+        WHEN 'wizard' THEN 6
+        ELSE 8
+    END
+))
+WHERE classes = '[]';
+
+ALTER TABLE characters DROP COLUMN IF EXISTS class;
+ALTER TABLE characters DROP COLUMN IF EXISTS subclass;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS character_level_ups;
+ALTER TABLE characters DROP COLUMN IF EXISTS feats;
@@ -0,0 +1,24 @@
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS feats JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS character_level_ups (
+    id UUID PRIMARY KEY,
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    class VARCHAR(100) NOT NULL,
+    class_level INTEGER NOT NULL CHECK (class_level BETWEEN 1 AND 20),
+    level INTEGER NOT NULL CHECK (level BETWEEN 2 AND 20),
+    new_class BOOLEAN NOT NULL DEFAULT FALSE,
+    hit_die INTEGER NOT NULL,
+    hp_method VARCHAR(20) NOT NULL,
+    hp_roll INTEGER,
+    hp_gained INTEGER NOT NULL,
+    ability_increases JSONB NOT NULL DEFAULT '{}',
+    feat VARCHAR(100),
+    subclass VARCHAR(100),
+    spell_ids JSONB NOT NULL DEFAULT '[]',
+    features JSONB NOT NULL DEFAULT '[]',
+    revision INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_character_level_ups_character_id ON character_level_ups(character_id, created_at);
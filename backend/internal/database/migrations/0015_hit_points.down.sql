@@ -0,0 +1,2 @@
+ALTER TABLE characters DROP COLUMN IF EXISTS defenses;
+ALTER TABLE characters DROP COLUMN IF EXISTS temp_hp;
@@ -0,0 +1,2 @@
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS temp_hp INTEGER NOT NULL DEFAULT 0 CHECK (temp_hp >= 0);
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS defenses JSONB NOT NULL DEFAULT '{"resistances": [], "vulnerabilities": [], "immunities": []}';
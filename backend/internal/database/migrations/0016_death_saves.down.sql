@@ -0,0 +1 @@
+ALTER TABLE characters DROP COLUMN IF EXISTS death_saves;
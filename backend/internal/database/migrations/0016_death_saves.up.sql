@@ -0,0 +1 @@
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS death_saves JSONB NOT NULL DEFAULT '{"successes": 0, "failures": 0, "stable": false, "dead": false}';
@@ -0,0 +1,3 @@
+ALTER TABLE characters DROP COLUMN IF EXISTS speed;
+ALTER TABLE characters DROP COLUMN IF EXISTS exhaustion;
+ALTER TABLE characters DROP COLUMN IF EXISTS conditions;
@@ -0,0 +1,3 @@
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS conditions JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS exhaustion INTEGER NOT NULL DEFAULT 0 CHECK (exhaustion BETWEEN 0 AND 6);
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS speed INTEGER NOT NULL DEFAULT 30 CHECK (speed >= 0);
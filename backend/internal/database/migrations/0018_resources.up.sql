@@ -0,0 +1 @@
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS resources JSONB NOT NULL DEFAULT '[]';
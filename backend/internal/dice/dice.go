@@ -0,0 +1,362 @@
+package dice
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits that keep a single roll cheap to evaluate and to return
+const (
+	MaxDice       = 100
+	MaxSides      = 1000
+	MaxTerms      = 20
+	MaxModifier   = 1000000
+	maxExplosions = 100
+	maxRerolls    = 100
+)
+
+// Expression is a parsed dice expression such as "4d6kh3+2"
+type Expression struct {
+	Terms []*Term
+}
+
+// Term is a single signed part of an expression: either a constant or a
+// group of identical dice with optional modifiers
+type Term struct {
+	Negative bool
+	// Constant is the value of a constant term; unused for dice
+	Constant int
+
+	Count   int
+	Sides   int
+	Keep    *Selection
+	Drop    *Selection
+	Reroll  *Compare
+	Explode *Compare
+	// RerollOnce rerolls a matching die a single time instead of until it
+	// stops matching
+	RerollOnce bool
+}
+
+// Selection picks the highest or lowest N dice of a group
+type Selection struct {
+	Highest bool
+	Count   int
+}
+
+// Compare matches die faces equal to, at most, or at least a value
+type Compare struct {
+	Op    byte // '=', '<' (at most) or '>' (at least)
+	Value int
+}
+
+// Result is the outcome of rolling an expression
+type Result struct {
+	Expression string       `json:"expression"`
+	Total      int          `json:"total"`
+	Terms      []TermResult `json:"terms"`
+}
+
+// TermResult is the outcome of one term with a per-die breakdown
+type TermResult struct {
+	Notation string `json:"notation"`
+	Sides    int    `json:"sides,omitempty"`
+	Dice     []Die  `json:"dice,omitempty"`
+	// Value is the term's signed contribution to the total
+	Value int `json:"value"`
+}
+
+// Die is a single rolled die
+type Die struct {
+	Value int `json:"value"`
+	// Rerolls lists earlier faces that were rerolled away
+	Rerolls []int `json:"rerolls,omitempty"`
+	// Exploded is set when this die rolled high enough to add another die
+	Exploded bool `json:"exploded,omitempty"`
+	// Dropped dice don't count towards the total
+	Dropped bool `json:"dropped,omitempty"`
+}
+
+// Mode selects advantage or disadvantage on a d20 roll
+type Mode int
+
+// Roll modes
+const (
+	Normal Mode = iota
+	Advantage
+	Disadvantage
+)
+
+// ModeFor combines advantage and disadvantage flags. Having both cancels
+// out, as the rules say.
+func ModeFor(advantage, disadvantage bool) Mode {
+	switch {
+	case advantage && !disadvantage:
+		return Advantage
+	case disadvantage && !advantage:
+		return Disadvantage
+	default:
+		return Normal
+	}
+}
+
+// RNG is a source of random numbers; *rand.Rand satisfies it
+type RNG interface {
+	// Intn returns a number in [0, n)
+	Intn(n int) int
+}
+
+// Roller evaluates expressions using an RNG and is safe for concurrent use
+type Roller struct {
+	mu  sync.Mutex
+	rng RNG
+}
+
+// NewRoller creates a roller drawing from rng
+func NewRoller(rng RNG) *Roller {
+	return &Roller{rng: rng}
+}
+
+// NewSeededRoller creates a roller whose results are reproducible for a
+// given seed
+func NewSeededRoller(seed int64) *Roller {
+	return NewRoller(rand.New(rand.NewSource(seed)))
+}
+
+// NewRandomRoller creates a roller seeded from the clock
+func NewRandomRoller() *Roller {
+	return NewSeededRoller(time.Now().UnixNano())
+}
+
+// WithMode turns the first single d20 of the expression into 2d20 keeping
+// the highest (advantage) or lowest (disadvantage) die
+func (e *Expression) WithMode(mode Mode) (*Expression, error) {
+	if mode == Normal {
+		return e, nil
+	}
+
+	for i, term := range e.Terms {
+		if term.Sides == 20 && term.Count == 1 && term.Keep == nil && term.Drop == nil {
+			cp := *term
+			cp.Count = 2
+			cp.Keep = &Selection{Highest: mode == Advantage, Count: 1}
+
+			terms := append([]*Term{}, e.Terms...)
+			terms[i] = &cp
+			return &Expression{Terms: terms}, nil
+		}
+	}
+
+	return nil, invalid("advantage and disadvantage need a single d20 in the expression")
+}
+
+// String formats the expression in canonical notation
+func (e *Expression) String() string {
+	var b strings.Builder
+	for i, term := range e.Terms {
+		switch {
+		case term.Negative:
+			b.WriteByte('-')
+		case i > 0:
+			b.WriteByte('+')
+		}
+		b.WriteString(term.notation())
+	}
+	return b.String()
+}
+
+// notation formats a term without its sign
+func (t *Term) notation() string {
+	if t.Sides == 0 {
+		return strconv.Itoa(t.Constant)
+	}
+
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(t.Count))
+	b.WriteByte('d')
+	b.WriteString(strconv.Itoa(t.Sides))
+	if t.Reroll != nil {
+		b.WriteByte('r')
+		if t.RerollOnce {
+			b.WriteByte('o')
+		}
+		b.WriteString(t.Reroll.String())
+	}
+	if t.Explode != nil {
+		b.WriteByte('!')
+		if !(t.Explode.Op == '>' && t.Explode.Value == t.Sides) {
+			b.WriteString(t.Explode.String())
+		}
+	}
+	if t.Keep != nil {
+		b.WriteString("k" + t.Keep.String())
+	}
+	if t.Drop != nil {
+		b.WriteString("d" + t.Drop.String())
+	}
+	return b.String()
+}
+
+// String formats a selection as it follows k or d
+func (s *Selection) String() string {
+	if s.Highest {
+		return "h" + strconv.Itoa(s.Count)
+	}
+	return "l" + strconv.Itoa(s.Count)
+}
+
+// String formats a comparison; equality is implied
+func (c *Compare) String() string {
+	if c.Op == '=' {
+		return strconv.Itoa(c.Value)
+	}
+	return string(c.Op) + strconv.Itoa(c.Value)
+}
+
+// Matches reports whether a die face satisfies the comparison
+func (c *Compare) Matches(face int) bool {
+	switch c.Op {
+	case '<':
+		return face <= c.Value
+	case '>':
+		return face >= c.Value
+	default:
+		return face == c.Value
+	}
+}
+
+// Roll evaluates an expression
+func (r *Roller) Roll(e *Expression) *Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &Result{Expression: e.String(), Terms: make([]TermResult, 0, len(e.Terms))}
+	for _, term := range e.Terms {
+		tr := r.rollTerm(term)
+		result.Total += tr.Value
+		result.Terms = append(result.Terms, tr)
+	}
+	return result
+}
+
+// RollNotation parses an expression, applies the roll mode and rolls it
+func (r *Roller) RollNotation(notation string, mode Mode) (*Result, error) {
+	expr, err := Parse(notation)
+	if err != nil {
+		return nil, err
+	}
+	if expr, err = expr.WithMode(mode); err != nil {
+		return nil, err
+	}
+	return r.Roll(expr), nil
+}
+
+// rollTerm evaluates one term; the caller must hold the lock
+func (r *Roller) rollTerm(t *Term) TermResult {
+	tr := TermResult{Notation: t.notation()}
+	if t.Sides == 0 {
+		tr.Value = t.Constant
+	} else {
+		tr.Sides = t.Sides
+		tr.Dice = r.rollDice(t)
+		for _, d := range tr.Dice {
+			if !d.Dropped {
+				tr.Value += d.Value
+			}
+		}
+	}
+
+	if t.Negative {
+		tr.Value = -tr.Value
+	}
+	return tr
+}
+
+// rollDice rolls a group of dice, applying rerolls, explosions and then
+// keep/drop selection
+func (r *Roller) rollDice(t *Term) []Die {
+	dice := make([]Die, 0, t.Count)
+	explosions := 0
+	for i := 0; i < t.Count; i++ {
+		for {
+			d := r.rollDie(t)
+			explode := t.Explode != nil && t.Explode.Matches(d.Value) && explosions < maxExplosions
+			d.Exploded = explode
+			dice = append(dice, d)
+			if !explode {
+				break
+			}
+			explosions++
+		}
+	}
+
+	sel, dropSelected := t.Keep, false
+	if sel == nil {
+		sel, dropSelected = t.Drop, true
+	}
+	if sel != nil {
+		selected := selectDice(dice, sel)
+		for i := range dice {
+			if selected[i] == dropSelected {
+				dice[i].Dropped = true
+			}
+		}
+	}
+
+	return dice
+}
+
+// rollDie rolls a single die, rerolling it as the term asks
+func (r *Roller) rollDie(t *Term) Die {
+	d := Die{Value: r.rng.Intn(t.Sides) + 1}
+	if t.Reroll == nil {
+		return d
+	}
+
+	for n := 0; t.Reroll.Matches(d.Value) && n < maxRerolls; n++ {
+		d.Rerolls = append(d.Rerolls, d.Value)
+		d.Value = r.rng.Intn(t.Sides) + 1
+		if t.RerollOnce {
+			break
+		}
+	}
+	return d
+}
+
+// selectDice marks the highest or lowest sel.Count dice. Ties go to the
+// die rolled first so the breakdown is stable.
+func selectDice(dice []Die, sel *Selection) []bool {
+	selected := make([]bool, len(dice))
+	for n := 0; n < sel.Count && n < len(dice); n++ {
+		best := -1
+		for i, d := range dice {
+			if selected[i] {
+				continue
+			}
+			if best < 0 || (sel.Highest && d.Value > dice[best].Value) || (!sel.Highest && d.Value < dice[best].Value) {
+				best = i
+			}
+		}
+		selected[best] = true
+	}
+	return selected
+}
+
+// Natural returns the kept face of the first d20 in the result, which is
+// what decides natural 1s and 20s
+func (r *Result) Natural() (int, bool) {
+	for _, term := range r.Terms {
+		if term.Sides != 20 {
+			continue
+		}
+		for _, d := range term.Dice {
+			if !d.Dropped {
+				return d.Value, true
+			}
+		}
+	}
+	return 0, false
+}
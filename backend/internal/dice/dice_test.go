@@ -0,0 +1,234 @@
+package dice
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// faces is an RNG that rolls the given faces in order, so tests can check
+// exactly what happens to each die
+type faces []int
+
+func (f *faces) Intn(n int) int {
+	face := (*f)[0]
+	*f = (*f)[1:]
+	return face - 1
+}
+
+func rollWith(t *testing.T, notation string, rolled ...int) *Result {
+	t.Helper()
+	expr, err := Parse(notation)
+	if err != nil {
+		t.Fatalf("Parse(%q) returned %v", notation, err)
+	}
+	rng := faces(rolled)
+	result := NewRoller(&rng).Roll(expr)
+	if len(rng) > 0 {
+		t.Fatalf("rolling %q left faces %v unused", notation, []int(rng))
+	}
+	return result
+}
+
+func TestRollFaces(t *testing.T) {
+	tests := []struct {
+		notation string
+		rolled   []int
+		total    int
+		dice     []Die
+	}{
+		{"2d6+3", []int{4, 5}, 12, []Die{{Value: 4}, {Value: 5}}},
+		{"-1d4", []int{3}, -3, []Die{{Value: 3}}},
+		{"4d6kh3", []int{3, 1, 6, 3}, 12, []Die{{Value: 3}, {Value: 1, Dropped: true}, {Value: 6}, {Value: 3}}},
+		{"4d6kl1", []int{3, 1, 6, 1}, 1, []Die{{Value: 3, Dropped: true}, {Value: 1}, {Value: 6, Dropped: true}, {Value: 1, Dropped: true}}},
+		{"4d6d1", []int{3, 2, 6, 2}, 11, []Die{{Value: 3}, {Value: 2, Dropped: true}, {Value: 6}, {Value: 2}}},
+		{"4d6dh2", []int{3, 2, 6, 5}, 5, []Die{{Value: 3}, {Value: 2}, {Value: 6, Dropped: true}, {Value: 5, Dropped: true}}},
+		{"2d8r1", []int{1, 1, 5, 7}, 12, []Die{{Value: 5, Rerolls: []int{1, 1}}, {Value: 7}}},
+		{"2d8r<2", []int{2, 1, 8, 3}, 11, []Die{{Value: 8, Rerolls: []int{2, 1}}, {Value: 3}}},
+		{"2d8ro1", []int{1, 1, 4}, 5, []Die{{Value: 1, Rerolls: []int{1}}, {Value: 4}}},
+		{"1d6!", []int{6, 6, 2}, 14, []Die{{Value: 6, Exploded: true}, {Value: 6, Exploded: true}, {Value: 2}}},
+		{"2d6!>5", []int{5, 1, 3}, 9, []Die{{Value: 5, Exploded: true}, {Value: 1}, {Value: 3}}},
+		{"3d6!k2", []int{6, 1, 2, 4}, 10, []Die{{Value: 6, Exploded: true}, {Value: 1, Dropped: true}, {Value: 2, Dropped: true}, {Value: 4}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.notation, func(t *testing.T) {
+			result := rollWith(t, tt.notation, tt.rolled...)
+			if result.Total != tt.total {
+				t.Errorf("total = %d, want %d", result.Total, tt.total)
+			}
+			if got := result.Terms[0].Dice; !reflect.DeepEqual(got, tt.dice) {
+				t.Errorf("dice = %+v, want %+v", got, tt.dice)
+			}
+		})
+	}
+}
+
+func TestRollLimitsRerollsAndExplosions(t *testing.T) {
+	expr, err := Parse("1d2r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ones := faces(make([]int, maxRerolls+1))
+	for i := range ones {
+		ones[i] = 1
+	}
+	die := NewRoller(&ones).Roll(expr).Terms[0].Dice[0]
+	if len(die.Rerolls) != maxRerolls {
+		t.Errorf("rerolled %d times, want at most %d", len(die.Rerolls), maxRerolls)
+	}
+
+	expr, err = Parse("1d2!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	twos := faces(make([]int, maxExplosions+1))
+	for i := range twos {
+		twos[i] = 2
+	}
+	dice := NewRoller(&twos).Roll(expr).Terms[0].Dice
+	if len(dice) != maxExplosions+1 {
+		t.Errorf("rolled %d dice, want the first plus %d explosions", len(dice), maxExplosions)
+	}
+}
+
+func TestSeededRollerIsReproducible(t *testing.T) {
+	for _, notation := range []string{"1d20", "4d6kh3", "8d6!r1", "d%-2"} {
+		first, err := NewSeededRoller(42).RollNotation(notation, Normal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := NewSeededRoller(42).RollNotation(notation, Normal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("%s rolled %+v and then %+v with the same seed", notation, first, second)
+		}
+	}
+}
+
+func TestSeededRollsStayInRange(t *testing.T) {
+	roller := NewSeededRoller(7)
+	seen := map[int]bool{}
+	for range 2000 {
+		result, err := roller.RollNotation("1d20+2", Normal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Total < 3 || result.Total > 22 {
+			t.Fatalf("1d20+2 rolled %d", result.Total)
+		}
+		seen[result.Total] = true
+
+		kept, err := roller.RollNotation("4d6kh3", Normal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dropped := 0
+		for _, d := range kept.Terms[0].Dice {
+			if d.Dropped {
+				dropped++
+			}
+		}
+		if dropped != 1 || kept.Total < 3 || kept.Total > 18 {
+			t.Fatalf("4d6kh3 rolled %+v", kept)
+		}
+	}
+	if len(seen) != 20 {
+		t.Errorf("1d20+2 only rolled %d different totals", len(seen))
+	}
+}
+
+func TestWithMode(t *testing.T) {
+	tests := []struct {
+		notation string
+		mode     Mode
+		want     string
+	}{
+		{"1d20+5", Normal, "1d20+5"},
+		{"1d20+5", Advantage, "2d20kh1+5"},
+		{"d20-1", Disadvantage, "2d20kl1-1"},
+		{"1d4+1d20+1d20", Advantage, "1d4+2d20kh1+1d20"},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		original := expr.String()
+		moded, err := expr.WithMode(tt.mode)
+		if err != nil {
+			t.Fatalf("%s with mode %d returned %v", tt.notation, tt.mode, err)
+		}
+		if got := moded.String(); got != tt.want {
+			t.Errorf("%s with mode %d = %q, want %q", tt.notation, tt.mode, got, tt.want)
+		}
+		if got := expr.String(); got != original {
+			t.Errorf("WithMode changed the original expression to %q", got)
+		}
+	}
+
+	for _, notation := range []string{"1d6+2", "2d20", "2d20kh1"} {
+		expr, err := Parse(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := expr.WithMode(Advantage); !errors.Is(err, apperror.ErrValidation) {
+			t.Errorf("%s with advantage returned %v, want a validation error", notation, err)
+		}
+	}
+}
+
+func TestRollNotationWithMode(t *testing.T) {
+	rng := faces{4, 17}
+	result, err := NewRoller(&rng).RollNotation("1d20+3", Advantage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Expression != "2d20kh1+3" || result.Total != 20 {
+		t.Errorf("advantage rolled %s = %d, want 2d20kh1+3 = 20", result.Expression, result.Total)
+	}
+	if natural, ok := result.Natural(); !ok || natural != 17 {
+		t.Errorf("Natural() = %d, %v, want 17", natural, ok)
+	}
+
+	rng = faces{4, 17}
+	result, err = NewRoller(&rng).RollNotation("1d20+3", Disadvantage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 7 {
+		t.Errorf("disadvantage total = %d, want 7", result.Total)
+	}
+
+	if _, err := NewSeededRoller(1).RollNotation("1d20+", Normal); err == nil {
+		t.Error("RollNotation accepted an invalid expression")
+	}
+}
+
+func TestModeFor(t *testing.T) {
+	tests := []struct {
+		advantage, disadvantage bool
+		want                    Mode
+	}{
+		{false, false, Normal},
+		{true, false, Advantage},
+		{false, true, Disadvantage},
+		{true, true, Normal},
+	}
+	for _, tt := range tests {
+		if got := ModeFor(tt.advantage, tt.disadvantage); got != tt.want {
+			t.Errorf("ModeFor(%v, %v) = %d, want %d", tt.advantage, tt.disadvantage, got, tt.want)
+		}
+	}
+}
+
+func TestNaturalWithoutD20(t *testing.T) {
+	result := rollWith(t, "2d6", 3, 4)
+	if _, ok := result.Natural(); ok {
+		t.Error("Natural() found a d20 in 2d6")
+	}
+}
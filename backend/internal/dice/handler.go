@@ -0,0 +1,43 @@
+package dice
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// RollRequest represents a request to roll an arbitrary expression
+type RollRequest struct {
+	Expression   string `json:"expression" binding:"required,max=200"`
+	Advantage    bool   `json:"advantage"`
+	Disadvantage bool   `json:"disadvantage"`
+}
+
+// Handler handles dice HTTP requests
+type Handler struct {
+	roller *Roller
+}
+
+// NewHandler creates a new dice handler
+func NewHandler(roller *Roller) *Handler {
+	return &Handler{roller: roller}
+}
+
+// Roll evaluates a dice expression
+func (h *Handler) Roll(c *gin.Context) {
+	var req RollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	result, err := h.roller.RollNotation(req.Expression, ModeFor(req.Advantage, req.Disadvantage))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
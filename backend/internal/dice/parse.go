@@ -0,0 +1,290 @@
+package dice
+
+import (
+	"fmt"
+	"strings"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// invalid reports a malformed or unsupported expression
+func invalid(detail string) error {
+	return apperror.Validation("invalid_dice_expression", "Invalid dice expression", map[string]string{
+		"expression": detail,
+	})
+}
+
+// Parse reads standard dice notation: terms joined by + and -, where each
+// term is a number or NdS followed by optional modifiers:
+//
+//	kN, khN, klN  keep the highest (default) or lowest N dice
+//	dN, dhN, dlN  drop the lowest (default) or highest N dice
+//	rN, r<N, r>N  reroll faces equal to, at most or at least N
+//	roN ...       reroll only once
+//	!, !>N        explode on the highest face, or on faces of at least N
+//
+// "d%" is a percentile die and a missing count means one die. Whitespace
+// and case are ignored.
+func Parse(input string) (*Expression, error) {
+	p := &parser{src: strings.ToLower(strings.Join(strings.Fields(input), ""))}
+	if p.src == "" {
+		return nil, invalid("expression is empty")
+	}
+
+	expr := &Expression{}
+	negative := false
+	if p.accept('-') {
+		negative = true
+	} else {
+		p.accept('+')
+	}
+
+	totalDice := 0
+	for {
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		term.Negative = negative
+		expr.Terms = append(expr.Terms, term)
+
+		totalDice += term.Count
+		if totalDice > MaxDice {
+			return nil, invalid(fmt.Sprintf("at most %d dice can be rolled at once", MaxDice))
+		}
+		if len(expr.Terms) > MaxTerms {
+			return nil, invalid(fmt.Sprintf("at most %d terms are allowed", MaxTerms))
+		}
+
+		if p.done() {
+			return expr, nil
+		}
+		switch c := p.next(); c {
+		case '+':
+			negative = false
+		case '-':
+			negative = true
+		default:
+			return nil, p.unexpected(c)
+		}
+	}
+}
+
+// parser walks a normalized expression one byte at a time
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) next() byte {
+	c := p.peek()
+	p.pos++
+	return c
+}
+
+// accept consumes c if it is next
+func (p *parser) accept(c byte) bool {
+	if !p.done() && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// number reads an unsigned integer, reporting whether there was one
+func (p *parser) number() (int, bool, error) {
+	start := p.pos
+	n := 0
+	for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+		n = n*10 + int(p.next()-'0')
+		if n > MaxModifier {
+			return 0, false, invalid(fmt.Sprintf("numbers must be at most %d", MaxModifier))
+		}
+	}
+	return n, p.pos > start, nil
+}
+
+// unexpected reports the byte just consumed as out of place
+func (p *parser) unexpected(c byte) error {
+	if c == 0 {
+		return invalid("unexpected end of expression")
+	}
+	return invalid(fmt.Sprintf("unexpected %q at position %d", c, p.pos))
+}
+
+// term reads a constant or a dice group with its modifiers
+func (p *parser) term() (*Term, error) {
+	n, hasCount, err := p.number()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept('d') {
+		if !hasCount {
+			return nil, p.unexpected(p.next())
+		}
+		return &Term{Constant: n}, nil
+	}
+
+	t := &Term{Count: 1}
+	if hasCount {
+		t.Count = n
+	}
+	if t.Count < 1 || t.Count > MaxDice {
+		return nil, invalid(fmt.Sprintf("dice count must be between 1 and %d", MaxDice))
+	}
+
+	if p.accept('%') {
+		t.Sides = 100
+	} else {
+		sides, ok, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, invalid(fmt.Sprintf("expected number of sides at position %d", p.pos+1))
+		}
+		t.Sides = sides
+	}
+	if t.Sides < 1 || t.Sides > MaxSides {
+		return nil, invalid(fmt.Sprintf("dice must have between 1 and %d sides", MaxSides))
+	}
+
+	for !p.done() && p.peek() != '+' && p.peek() != '-' {
+		if err := p.modifier(t); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, t.validate()
+}
+
+// modifier reads one keep, drop, reroll or explode modifier onto t
+func (p *parser) modifier(t *Term) error {
+	switch c := p.next(); c {
+	case 'k', 'd':
+		if t.Keep != nil || t.Drop != nil {
+			return invalid("a dice group can only keep or drop once")
+		}
+		sel, err := p.selection(c == 'k')
+		if err != nil {
+			return err
+		}
+		if c == 'k' {
+			t.Keep = sel
+		} else {
+			t.Drop = sel
+		}
+	case 'r':
+		if t.Reroll != nil {
+			return invalid("a dice group can only have one reroll")
+		}
+		t.RerollOnce = p.accept('o')
+		cmp, err := p.compare()
+		if err != nil {
+			return err
+		}
+		if cmp == nil {
+			return invalid(fmt.Sprintf("expected a face to reroll at position %d", p.pos+1))
+		}
+		t.Reroll = cmp
+	case '!':
+		if t.Explode != nil {
+			return invalid("a dice group can only explode once")
+		}
+		cmp, err := p.compare()
+		if err != nil {
+			return err
+		}
+		if cmp == nil {
+			cmp = &Compare{Op: '>', Value: t.Sides}
+		}
+		t.Explode = cmp
+	default:
+		return p.unexpected(c)
+	}
+	return nil
+}
+
+// selection reads the h/l and count following k or d. Keeping defaults to
+// the highest dice and dropping to the lowest.
+func (p *parser) selection(keep bool) (*Selection, error) {
+	sel := &Selection{Highest: keep, Count: 1}
+	if p.accept('h') {
+		sel.Highest = true
+	} else if p.accept('l') {
+		sel.Highest = false
+	}
+
+	n, ok, err := p.number()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		sel.Count = n
+	}
+	return sel, nil
+}
+
+// compare reads an optional comparison, returning nil if none follows
+func (p *parser) compare() (*Compare, error) {
+	cmp := &Compare{Op: '='}
+	hasOp := true
+	switch {
+	case p.accept('<'):
+		cmp.Op = '<'
+	case p.accept('>'):
+		cmp.Op = '>'
+	default:
+		hasOp = p.accept('=')
+	}
+
+	n, ok, err := p.number()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if hasOp {
+			return nil, invalid(fmt.Sprintf("expected a number at position %d", p.pos+1))
+		}
+		return nil, nil
+	}
+	cmp.Value = n
+	return cmp, nil
+}
+
+// validate rejects modifiers that can't be applied to the term's dice
+func (t *Term) validate() error {
+	for _, sel := range []*Selection{t.Keep, t.Drop} {
+		if sel != nil && (sel.Count < 1 || sel.Count > t.Count) {
+			return invalid(fmt.Sprintf("can only keep or drop between 1 and %d dice", t.Count))
+		}
+	}
+	if t.Reroll != nil && !t.RerollOnce && coversAllFaces(t.Reroll, t.Sides) {
+		return invalid("reroll would match every face of the die")
+	}
+	if t.Explode != nil && coversAllFaces(t.Explode, t.Sides) {
+		return invalid("explosion would match every face of the die")
+	}
+	return nil
+}
+
+// coversAllFaces reports whether every face of a die matches cmp
+func coversAllFaces(cmp *Compare, sides int) bool {
+	for face := 1; face <= sides; face++ {
+		if !cmp.Matches(face) {
+			return false
+		}
+	}
+	return true
+}
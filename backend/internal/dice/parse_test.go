@@ -0,0 +1,152 @@
+package dice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+func TestParseValid(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1d20", "1d20"},
+		{"d20", "1d20"},
+		{"2D6 + 3", "2d6+3"},
+		{"-1d4+2", "-1d4+2"},
+		{"+1d4", "1d4"},
+		{"1d8-1", "1d8-1"},
+		{"7", "7"},
+		{"d%", "1d100"},
+		{"1d6+1d8+2", "1d6+1d8+2"},
+
+		// Keep and drop
+		{"4d6kh3", "4d6kh3"},
+		{"4d6k3", "4d6kh3"},
+		{"2d20k", "2d20kh1"},
+		{"2d20kl1", "2d20kl1"},
+		{"4d6d1", "4d6dl1"},
+		{"4d6dl1", "4d6dl1"},
+		{"4d6dh1", "4d6dh1"},
+
+		// Rerolls
+		{"2d8r1", "2d8r1"},
+		{"2d8r=1", "2d8r1"},
+		{"2d8r<2", "2d8r<2"},
+		{"2d8r>7", "2d8r>7"},
+		{"2d8ro1", "2d8ro1"},
+		{"2d8ro<2", "2d8ro<2"},
+		{"1d6ro<6", "1d6ro<6"},
+
+		// Explosions
+		{"d6!", "1d6!"},
+		{"3d6!>5", "3d6!>5"},
+		{"3d6!>6", "3d6!"},
+		{"3d6!=4", "3d6!4"},
+
+		// Modifiers combine and print in a fixed order
+		{"4d6k3r1", "4d6r1kh3"},
+		{"2d6!r1k1", "2d6r1!kh1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned %v", tt.input, err)
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTerms(t *testing.T) {
+	expr, err := Parse("4d6kl2-1d8ro<2+3d10!>9-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expr.Terms) != 4 {
+		t.Fatalf("got %d terms, want 4", len(expr.Terms))
+	}
+
+	keep := expr.Terms[0]
+	if keep.Negative || keep.Count != 4 || keep.Sides != 6 || keep.Keep == nil || keep.Keep.Highest || keep.Keep.Count != 2 {
+		t.Errorf("keep term = %+v, keep %+v", keep, keep.Keep)
+	}
+
+	reroll := expr.Terms[1]
+	if !reroll.Negative || !reroll.RerollOnce || reroll.Reroll == nil || *reroll.Reroll != (Compare{Op: '<', Value: 2}) {
+		t.Errorf("reroll term = %+v, reroll %+v", reroll, reroll.Reroll)
+	}
+
+	explode := expr.Terms[2]
+	if explode.Negative || explode.Explode == nil || *explode.Explode != (Compare{Op: '>', Value: 9}) {
+		t.Errorf("explode term = %+v, explode %+v", explode, explode.Explode)
+	}
+
+	constant := expr.Terms[3]
+	if !constant.Negative || constant.Sides != 0 || constant.Constant != 5 {
+		t.Errorf("constant term = %+v", constant)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		detail string
+	}{
+		{"empty", "", "expression is empty"},
+		{"blank", "   ", "expression is empty"},
+		{"sign only", "+", "unexpected end of expression"},
+		{"trailing operator", "1d6+", "unexpected end of expression"},
+		{"unknown character", "1d6x", `unexpected 'x'`},
+		{"double operator", "1d6++2", `unexpected '+'`},
+		{"missing sides", "1d", "expected number of sides"},
+		{"zero sides", "1d0", "dice must have between 1 and 1000 sides"},
+		{"too many sides", "1d1001", "dice must have between 1 and 1000 sides"},
+		{"zero dice", "0d6", "dice count must be between 1 and 100"},
+		{"too many dice in a group", "101d6", "dice count must be between 1 and 100"},
+		{"too many dice in total", "60d6+60d6", "at most 100 dice can be rolled at once"},
+		{"too many terms", strings.Repeat("1+", MaxTerms) + "1", "at most 20 terms are allowed"},
+		{"number too large", "1d6+1000001", "numbers must be at most 1000000"},
+		{"keep more than rolled", "2d6kh3", "can only keep or drop between 1 and 2 dice"},
+		{"keep none", "2d6k0", "can only keep or drop between 1 and 2 dice"},
+		{"drop all", "2d6d3", "can only keep or drop between 1 and 2 dice"},
+		{"keep and drop", "4d6k3d1", "a dice group can only keep or drop once"},
+		{"keep twice", "4d6k3k2", "a dice group can only keep or drop once"},
+		{"reroll twice", "2d6r1r2", "a dice group can only have one reroll"},
+		{"reroll without face", "2d6r", "expected a face to reroll"},
+		{"reroll comparison without number", "2d6r<", "expected a number"},
+		{"reroll every face", "1d6r<6", "reroll would match every face of the die"},
+		{"reroll every face of a d1", "1d1r1", "reroll would match every face of the die"},
+		{"explode twice", "1d6!!", "a dice group can only explode once"},
+		{"explode comparison without number", "1d6!>", "expected a number"},
+		{"explode every face", "1d6!>1", "explosion would match every face of the die"},
+		{"explode a d1", "1d1!", "explosion would match every face of the die"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded, want an error", tt.input)
+			}
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Code != "invalid_dice_expression" {
+				t.Fatalf("Parse(%q) returned %v, want invalid_dice_expression", tt.input, err)
+			}
+			if !errors.Is(err, apperror.ErrValidation) {
+				t.Errorf("Parse(%q) error isn't a validation error", tt.input)
+			}
+			if detail := appErr.Fields["expression"]; !strings.Contains(detail, tt.detail) {
+				t.Errorf("Parse(%q) detail = %q, want it to contain %q", tt.input, detail, tt.detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package homebrew
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// Handler handles homebrew HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new homebrew handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListPacks lists the packs the user can use
+func (h *Handler) ListPacks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var filter Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	packs, err := h.service.ListPacks(userID.(string), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, packs)
+}
+
+// CreatePack uploads a new pack
+func (h *Handler) CreatePack(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	upload, err := readUpload(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	detail, err := h.service.CreatePack(userID.(string), upload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, detail)
+}
+
+// GetPack returns a pack with the content of one of its versions
+func (h *Handler) GetPack(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var query VersionQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	detail, err := h.service.GetPack(c.Param("id"), userID.(string), query.Version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// UpdatePack uploads a new version of a pack
+func (h *Handler) UpdatePack(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	upload, err := readUpload(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	detail, err := h.service.UpdatePack(c.Param("id"), userID.(string), upload)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// ListVersions lists the versions of a pack
+func (h *Handler) ListVersions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	versions, err := h.service.ListVersions(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// DeletePack deletes a pack, or archives it if characters still use it
+func (h *Handler) DeletePack(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	archived, err := h.service.DeletePack(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if archived {
+		c.JSON(http.StatusOK, gin.H{"message": "Pack archived because characters still use it", "archived": true})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Pack deleted successfully", "archived": false})
+}
+
+// GetCharacterContent resolves a character's race, class and background
+func (h *Handler) GetCharacterContent(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	res, err := h.service.GetCharacterContent(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// SetCharacterPacks replaces the packs a character references
+func (h *Handler) SetCharacterPacks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req SetPacksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	res, err := h.service.SetCharacterPacks(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// readUpload reads a JSON or YAML pack document from the request body
+func readUpload(c *gin.Context) (*Upload, error) {
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxPackBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, ErrPackTooLarge
+		}
+		return nil, invalidDocument(err)
+	}
+
+	return ParseUpload(data, FormatFor(c.ContentType()))
+}
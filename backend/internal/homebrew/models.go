@@ -0,0 +1,147 @@
+package homebrew
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/content"
+)
+
+// Limits on uploaded packs
+const (
+	// MaxPackBytes is the largest pack document accepted
+	MaxPackBytes = 1 << 20
+	// MaxEntries is the most entries of one kind a pack may hold
+	MaxEntries = 500
+	// MaxCharacterPacks is the most packs one character may reference
+	MaxCharacterPacks = 20
+)
+
+// Content is the homebrew in a pack. Entries use the same shapes as the
+// built-in SRD content.
+type Content struct {
+	Races       []*content.Race       `json:"races"`
+	Subraces    []*content.Subrace    `json:"subraces"`
+	Classes     []*content.Class      `json:"classes"`
+	Subclasses  []*content.Subclass   `json:"subclasses"`
+	Backgrounds []*content.Background `json:"backgrounds"`
+	Spells      []*content.Spell      `json:"spells"`
+	Items       []*content.Equipment  `json:"items"`
+}
+
+// Pack is a user's collection of homebrew content. Every upload adds a new
+// version; old versions are kept so characters built against them still
+// resolve.
+type Pack struct {
+	ID      uuid.UUID `json:"id"`
+	OwnerID uuid.UUID `json:"owner_id"`
+	// CampaignID shares the pack with every member of a campaign
+	CampaignID  *uuid.UUID `json:"campaign_id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description"`
+	// LatestVersion is the version new characters are pinned to
+	LatestVersion int `json:"latest_version"`
+	// Archived packs were deleted while characters still used them. They
+	// are hidden from listings and can't be changed or newly referenced.
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Version is one immutable upload of a pack
+type Version struct {
+	PackID    uuid.UUID `json:"pack_id"`
+	Version   int       `json:"version"`
+	Content   *Content  `json:"content,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Detail is a pack with the content of one of its versions
+type Detail struct {
+	*Pack
+	Version *Version `json:"version"`
+}
+
+// Ref pins a character to one version of a pack
+type Ref struct {
+	PackID  uuid.UUID `json:"pack_id"`
+	Version int       `json:"version"`
+}
+
+// CharacterPack is a pack a character references, as shown to clients
+type CharacterPack struct {
+	Ref
+	Name          string `json:"name"`
+	LatestVersion int    `json:"latest_version"`
+	Archived      bool   `json:"archived"`
+}
+
+// Upload is a pack document as uploaded in JSON or YAML
+type Upload struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=2000"`
+	CampaignID  *string `json:"campaign_id" binding:"omitempty,uuid"`
+	Content
+}
+
+// SetPacksRequest replaces the packs a character references, in lookup
+// order. Version defaults to the pack's latest.
+type SetPacksRequest struct {
+	Packs []PackRequest `json:"packs" binding:"max=20,dive"`
+}
+
+// PackRequest names one pack of a SetPacksRequest
+type PackRequest struct {
+	PackID  string `json:"pack_id" binding:"required,uuid"`
+	Version *int   `json:"version" binding:"omitempty,min=1"`
+}
+
+// Filter narrows a pack listing
+type Filter struct {
+	CampaignID string `form:"campaign_id" binding:"omitempty,uuid"`
+}
+
+// Source says where a resolved entry came from: the built-in SRD content
+// or a version of a homebrew pack
+type Source struct {
+	Kind    string     `json:"kind"`
+	PackID  *uuid.UUID `json:"pack_id,omitempty"`
+	Version int        `json:"version,omitempty"`
+}
+
+// Source kinds
+const (
+	SourceSRD      = "srd"
+	SourceHomebrew = "homebrew"
+)
+
+// Resolved is a content entry together with its source
+type Resolved[T any] struct {
+	Source Source `json:"source"`
+	Entry  *T     `json:"entry"`
+}
+
+// ClassResolution is what one of a character's class entries refers to
+type ClassResolution struct {
+	Class    *Resolved[content.Class]    `json:"class"`
+	Subclass *Resolved[content.Subclass] `json:"subclass"`
+}
+
+// Resolution is what a character's race, classes and background refer to
+// given the packs it references. Fields that don't match anything are
+// null and listed in Unresolved. Classes follow the character's class
+// entries.
+type Resolution struct {
+	Packs      []*CharacterPack              `json:"packs"`
+	Race       *Resolved[content.Race]       `json:"race"`
+	Subrace    *Resolved[content.Subrace]    `json:"subrace"`
+	Classes    []*ClassResolution            `json:"classes"`
+	Background *Resolved[content.Background] `json:"background"`
+	Unresolved []string                      `json:"unresolved"`
+}
+
+// VersionQuery picks the version of a pack to return, the latest by default
+type VersionQuery struct {
+	Version int `form:"version" binding:"omitempty,min=1"`
+}
@@ -0,0 +1,69 @@
+package homebrew
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+
+	"github.com/gin-gonic/gin/binding"
+	"gopkg.in/yaml.v3"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// ErrPackTooLarge is returned for pack documents over MaxPackBytes
+var ErrPackTooLarge = apperror.Validation("pack_too_large", "Pack documents can be at most 1 MiB", nil)
+
+// Pack document formats
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// FormatFor picks the document format for a request Content-Type. Anything
+// that isn't YAML is read as JSON.
+func FormatFor(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return FormatYAML
+	}
+	return FormatJSON
+}
+
+// ParseUpload decodes a pack document and checks its fields. YAML documents
+// use the same field names as JSON ones. Unknown fields are rejected so
+// typos don't silently drop content.
+func ParseUpload(data []byte, format string) (*Upload, error) {
+	if format == FormatYAML {
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, invalidDocument(err)
+		}
+		converted, err := json.Marshal(doc)
+		if err != nil {
+			return nil, invalidDocument(err)
+		}
+		data = converted
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var upload Upload
+	if err := decoder.Decode(&upload); err != nil {
+		return nil, invalidDocument(err)
+	}
+	if decoder.More() {
+		return nil, invalidDocument(fmt.Errorf("unexpected data after the document"))
+	}
+
+	if err := binding.Validator.ValidateStruct(&upload); err != nil {
+		return nil, apperror.FromBinding(err)
+	}
+	return &upload, nil
+}
+
+func invalidDocument(err error) error {
+	return apperror.Validation("invalid_pack", fmt.Sprintf("Invalid pack document: %v", err), nil)
+}
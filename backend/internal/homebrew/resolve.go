@@ -0,0 +1,106 @@
+package homebrew
+
+import (
+	"strings"
+
+	"character-sheet-backend/internal/content"
+)
+
+// Resolver looks up content across the SRD and the pinned versions of a
+// character's packs. Keys match an entry's index or, ignoring case, its
+// name. Pack entries can't reuse SRD indexes or names, so the SRD is
+// checked first and packs after it in the character's order.
+type Resolver struct {
+	catalog  *content.Catalog
+	versions []*Version
+}
+
+// NewResolver creates a resolver over the SRD and the given pack versions
+func NewResolver(catalog *content.Catalog, versions []*Version) *Resolver {
+	return &Resolver{catalog: catalog, versions: versions}
+}
+
+// Race resolves a race
+func (r *Resolver) Race(key string) *Resolved[content.Race] {
+	return resolve(r, key, r.catalog.Race,
+		func(c *Content) []*content.Race { return c.Races },
+		func(e *content.Race) (string, string) { return e.Index, e.Name })
+}
+
+// Subrace resolves a subrace
+func (r *Resolver) Subrace(key string) *Resolved[content.Subrace] {
+	return resolve(r, key, r.catalog.Subrace,
+		func(c *Content) []*content.Subrace { return c.Subraces },
+		func(e *content.Subrace) (string, string) { return e.Index, e.Name })
+}
+
+// Class resolves a class
+func (r *Resolver) Class(key string) *Resolved[content.Class] {
+	return resolve(r, key, r.catalog.Class,
+		func(c *Content) []*content.Class { return c.Classes },
+		func(e *content.Class) (string, string) { return e.Index, e.Name })
+}
+
+// Subclass resolves a subclass
+func (r *Resolver) Subclass(key string) *Resolved[content.Subclass] {
+	return resolve(r, key, r.catalog.Subclass,
+		func(c *Content) []*content.Subclass { return c.Subclasses },
+		func(e *content.Subclass) (string, string) { return e.Index, e.Name })
+}
+
+// Background resolves a background
+func (r *Resolver) Background(key string) *Resolved[content.Background] {
+	return resolve(r, key, r.catalog.Background,
+		func(c *Content) []*content.Background { return c.Backgrounds },
+		func(e *content.Background) (string, string) { return e.Index, e.Name })
+}
+
+// Spell resolves a spell
+func (r *Resolver) Spell(key string) *Resolved[content.Spell] {
+	return resolve(r, key, r.catalog.Spell,
+		func(c *Content) []*content.Spell { return c.Spells },
+		func(e *content.Spell) (string, string) { return e.Index, e.Name })
+}
+
+// Item resolves an equipment item
+func (r *Resolver) Item(key string) *Resolved[content.Equipment] {
+	return resolve(r, key, r.catalog.Item,
+		func(c *Content) []*content.Equipment { return c.Items },
+		func(e *content.Equipment) (string, string) { return e.Index, e.Name })
+}
+
+// resolve finds key in the SRD or, failing that, the first pack version
+// with a matching entry
+func resolve[T any](
+	r *Resolver,
+	key string,
+	srd func(string) (*T, error),
+	entries func(*Content) []*T,
+	names func(*T) (index, name string),
+) *Resolved[T] {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil
+	}
+
+	if entry, err := srd(key); err == nil {
+		return &Resolved[T]{Source: Source{Kind: SourceSRD}, Entry: entry}
+	}
+
+	for _, v := range r.versions {
+		if v.Content == nil {
+			continue
+		}
+		for _, entry := range entries(v.Content) {
+			index, name := names(entry)
+			if index == key || strings.EqualFold(name, key) {
+				packID := v.PackID
+				return &Resolved[T]{
+					Source: Source{Kind: SourceHomebrew, PackID: &packID, Version: v.Version},
+					Entry:  entry,
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package homebrew
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/content"
+)
+
+// gunslinger is a pack version whose only entry is a gunslinger class with
+// the given hit die
+func gunslinger(packID uuid.UUID, version, hitDie int) *Version {
+	return &Version{
+		PackID:  packID,
+		Version: version,
+		Content: &Content{Classes: []*content.Class{{Index: "gunslinger", Name: "Gunslinger", HitDie: hitDie}}},
+	}
+}
+
+func TestResolver(t *testing.T) {
+	catalog := loadCatalog(t)
+	first, second := uuid.New(), uuid.New()
+	resolver := NewResolver(catalog, []*Version{
+		{PackID: uuid.New(), Version: 1},
+		gunslinger(first, 2, 8),
+		gunslinger(second, 1, 10),
+	})
+
+	tests := []struct {
+		key    string
+		source Source
+		hitDie int
+	}{
+		{"fighter", Source{Kind: SourceSRD}, 10},
+		{" Wizard ", Source{Kind: SourceSRD}, 6},
+		// The first pack in the character's order wins
+		{"gunslinger", Source{Kind: SourceHomebrew, PackID: &first, Version: 2}, 8},
+		{"GUNSLINGER", Source{Kind: SourceHomebrew, PackID: &first, Version: 2}, 8},
+	}
+	for _, tt := range tests {
+		resolved := resolver.Class(tt.key)
+		if resolved == nil {
+			t.Errorf("Class(%q) resolved nothing", tt.key)
+			continue
+		}
+		if resolved.Entry.HitDie != tt.hitDie || resolved.Source.Kind != tt.source.Kind || resolved.Source.Version != tt.source.Version {
+			t.Errorf("Class(%q) = d%d from %+v, want d%d from %+v", tt.key, resolved.Entry.HitDie, resolved.Source, tt.hitDie, tt.source)
+		}
+		if (resolved.Source.PackID == nil) != (tt.source.PackID == nil) ||
+			resolved.Source.PackID != nil && *resolved.Source.PackID != *tt.source.PackID {
+			t.Errorf("Class(%q) came from pack %v, want %v", tt.key, resolved.Source.PackID, tt.source.PackID)
+		}
+	}
+
+	for _, key := range []string{"", "  ", "witch", "gun"} {
+		if resolved := resolver.Class(key); resolved != nil {
+			t.Errorf("Class(%q) resolved %+v", key, resolved.Entry)
+		}
+	}
+	// Entries of one kind don't resolve as another
+	if resolved := resolver.Spell("gunslinger"); resolved != nil {
+		t.Errorf("Spell(gunslinger) resolved %+v", resolved.Entry)
+	}
+}
+
+func TestRulesPinnedVersion(t *testing.T) {
+	store := NewMemoryStore()
+	rules := NewRules(store, loadCatalog(t))
+	packID := uuid.New()
+	pack := &Pack{ID: packID, OwnerID: uuid.New(), Name: "Gunslingers", LatestVersion: 1, CreatedAt: time.Now()}
+	if err := store.Create(pack, gunslinger(packID, 1, 8)); err != nil {
+		t.Fatal(err)
+	}
+
+	characterID := uuid.NewString()
+	if err := store.SetCharacterRefs(characterID, []*Ref{{PackID: packID, Version: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	classOf := func(characterID string) *content.Class {
+		t.Helper()
+		class, err := rules.Class(characterID, "gunslinger")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return class
+	}
+
+	// A new version changes nothing for characters pinned to the old one
+	pack.LatestVersion = 2
+	if err := store.AddVersion(pack, gunslinger(packID, 2, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if class := classOf(characterID); class == nil || class.HitDie != 8 {
+		t.Errorf("a character pinned to version 1 resolved %+v, want d8", class)
+	}
+
+	if err := store.SetCharacterRefs(characterID, []*Ref{{PackID: packID, Version: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if class := classOf(characterID); class == nil || class.HitDie != 10 {
+		t.Errorf("a character pinned to version 2 resolved %+v, want d10", class)
+	}
+
+	// Characters without the pack don't see its classes
+	if class := classOf(uuid.NewString()); class != nil {
+		t.Errorf("a character without packs resolved %+v", class)
+	}
+
+	// A pin to a version the pack doesn't have is an error
+	if err := store.SetCharacterRefs(characterID, []*Ref{{PackID: packID, Version: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rules.Class(characterID, "gunslinger"); err == nil {
+		t.Error("resolving against a missing version returned no error")
+	}
+}
@@ -0,0 +1,71 @@
+package homebrew
+
+import "character-sheet-backend/internal/content"
+
+// Rules looks up content for characters in the SRD and the packs they
+// reference. It is separate from Service so the character service can use
+// it without depending on the homebrew service, which depends on it.
+type Rules struct {
+	store   Store
+	catalog *content.Catalog
+}
+
+// NewRules creates rules over the SRD catalog and the packs in store
+func NewRules(store Store, catalog *content.Catalog) *Rules {
+	return &Rules{store: store, catalog: catalog}
+}
+
+// Resolver returns a resolver over the SRD and the pack versions a character
+// references. It doesn't check access, callers must already have done so.
+func (r *Rules) Resolver(characterID string) (*Resolver, error) {
+	_, versions, err := r.pinned(characterID)
+	if err != nil {
+		return nil, err
+	}
+	return NewResolver(r.catalog, versions), nil
+}
+
+// Class returns the rules for one of a character's classes, or nil if
+// neither the SRD nor the character's packs have it
+func (r *Rules) Class(characterID, class string) (*content.Class, error) {
+	resolver, err := r.Resolver(characterID)
+	if err != nil {
+		return nil, err
+	}
+	if resolved := resolver.Class(class); resolved != nil {
+		return resolved.Entry, nil
+	}
+	return nil, nil
+}
+
+// pinned loads the packs a character references and the versions it is
+// pinned to, in lookup order
+func (r *Rules) pinned(characterID string) ([]*CharacterPack, []*Version, error) {
+	refs, err := r.store.CharacterRefs(characterID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	packs := make([]*CharacterPack, 0, len(refs))
+	versions := make([]*Version, 0, len(refs))
+	for _, ref := range refs {
+		pack, err := r.store.Get(ref.PackID.String())
+		if err != nil {
+			return nil, nil, err
+		}
+		v, err := r.store.GetVersion(ref.PackID.String(), ref.Version)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		packs = append(packs, &CharacterPack{
+			Ref:           *ref,
+			Name:          pack.Name,
+			LatestVersion: pack.LatestVersion,
+			Archived:      pack.Archived,
+		})
+		versions = append(versions, v)
+	}
+
+	return packs, versions, nil
+}
@@ -0,0 +1,357 @@
+package homebrew
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/campaign"
+	"character-sheet-backend/internal/character"
+)
+
+var (
+	// ErrOwnerOnly is returned when someone other than its owner changes a
+	// pack
+	ErrOwnerOnly = apperror.Forbidden("pack_owner_only", "Only the pack's owner can do that")
+	// ErrCampaignDMOnly is returned when sharing a pack with a campaign the
+	// user isn't a DM of
+	ErrCampaignDMOnly = apperror.Field("campaign_id", "must be a campaign you are a DM of")
+	// ErrDuplicatePack is returned when a character references a pack twice
+	ErrDuplicatePack = apperror.Field("packs", "must not name a pack more than once")
+)
+
+// Campaigns lists the campaigns a user belongs to, with their role
+type Campaigns interface {
+	ListCampaigns(userID string) ([]*campaign.Campaign, error)
+}
+
+// Service handles homebrew packs and the packs characters reference
+type Service struct {
+	store      Store
+	rules      *Rules
+	characters *character.Service
+	campaigns  Campaigns
+}
+
+// NewService creates a new homebrew service
+func NewService(store Store, rules *Rules, characters *character.Service, campaigns Campaigns) *Service {
+	return &Service{store: store, rules: rules, characters: characters, campaigns: campaigns}
+}
+
+// ListPacks returns the packs a user owns or that are shared with their
+// campaigns, optionally only those shared with one campaign
+func (s *Service) ListPacks(userID string, filter *Filter) ([]*Pack, error) {
+	campaigns, err := s.campaignIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	packs, err := s.store.List(userID, campaigns)
+	if err != nil || filter.CampaignID == "" {
+		return packs, err
+	}
+
+	shared := []*Pack{}
+	for _, pack := range packs {
+		if pack.CampaignID != nil && pack.CampaignID.String() == filter.CampaignID {
+			shared = append(shared, pack)
+		}
+	}
+	return shared, nil
+}
+
+// CreatePack validates an upload and stores it as version 1 of a new pack
+func (s *Service) CreatePack(userID string, upload *Upload) (*Detail, error) {
+	owner, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, apperror.ErrNotAuthenticated
+	}
+
+	campaignID, err := s.sharedWith(userID, upload.CampaignID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(s.rules.catalog, &upload.Content); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pack := &Pack{
+		ID:            uuid.New(),
+		OwnerID:       owner,
+		CampaignID:    campaignID,
+		Name:          upload.Name,
+		Description:   upload.Description,
+		LatestVersion: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	version := &Version{PackID: pack.ID, Version: 1, Content: &upload.Content, CreatedAt: now}
+
+	if err := s.store.Create(pack, version); err != nil {
+		return nil, err
+	}
+
+	return &Detail{Pack: pack, Version: version}, nil
+}
+
+// GetPack returns a pack with the content of one version, the latest when
+// version is 0
+func (s *Service) GetPack(packID, userID string, version int) (*Detail, error) {
+	pack, err := s.readable(packID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == 0 {
+		version = pack.LatestVersion
+	}
+	v, err := s.store.GetVersion(packID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Detail{Pack: pack, Version: v}, nil
+}
+
+// UpdatePack stores an upload as the next version of a pack the user owns.
+// Characters stay pinned to the version they were built against.
+func (s *Service) UpdatePack(packID, userID string, upload *Upload) (*Detail, error) {
+	pack, err := s.owned(packID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	campaignID, err := s.sharedWith(userID, upload.CampaignID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(s.rules.catalog, &upload.Content); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pack.CampaignID = campaignID
+	pack.Name = upload.Name
+	pack.Description = upload.Description
+	pack.LatestVersion++
+	pack.UpdatedAt = now
+	version := &Version{PackID: pack.ID, Version: pack.LatestVersion, Content: &upload.Content, CreatedAt: now}
+
+	if err := s.store.AddVersion(pack, version); err != nil {
+		return nil, err
+	}
+
+	return &Detail{Pack: pack, Version: version}, nil
+}
+
+// ListVersions returns the versions of a pack the user can see
+func (s *Service) ListVersions(packID, userID string) ([]*Version, error) {
+	if _, err := s.readable(packID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListVersions(packID)
+}
+
+// DeletePack deletes a pack the user owns. Packs characters still reference
+// are archived instead, and it reports whether that happened.
+func (s *Service) DeletePack(packID, userID string) (bool, error) {
+	if _, err := s.owned(packID, userID); err != nil {
+		return false, err
+	}
+
+	return s.store.Delete(packID)
+}
+
+// GetCharacterContent resolves a character's race, class and background
+// against the SRD and the packs it references
+func (s *Service) GetCharacterContent(characterID, userID string) (*Resolution, error) {
+	char, err := s.characters.Authorize(characterID, userID, character.AccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.resolution(char)
+}
+
+// SetCharacterPacks replaces the packs a character the user owns references.
+// Packs must be visible to the user and not archived, though a character
+// may keep an archived pack it already references.
+func (s *Service) SetCharacterPacks(characterID, userID string, req *SetPacksRequest) (*Resolution, error) {
+	char, err := s.characters.Authorize(characterID, userID, character.AccessOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.store.CharacterRefs(characterID)
+	if err != nil {
+		return nil, err
+	}
+	pinned := make(map[uuid.UUID]int, len(current))
+	for _, ref := range current {
+		pinned[ref.PackID] = ref.Version
+	}
+
+	refs := make([]*Ref, 0, len(req.Packs))
+	seen := map[string]bool{}
+	for _, p := range req.Packs {
+		if seen[p.PackID] {
+			return nil, ErrDuplicatePack
+		}
+		seen[p.PackID] = true
+
+		pack, err := s.readable(p.PackID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		ref := &Ref{PackID: pack.ID, Version: pack.LatestVersion}
+		if pack.Archived {
+			ref.Version = pinned[pack.ID]
+		}
+		if p.Version != nil {
+			ref.Version = *p.Version
+		}
+		if pack.Archived && pinned[pack.ID] != ref.Version {
+			return nil, ErrPackNotFound
+		}
+		if _, err := s.store.GetVersion(p.PackID, ref.Version); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+
+	if err := s.store.SetCharacterRefs(characterID, refs); err != nil {
+		return nil, err
+	}
+
+	return s.resolution(char)
+}
+
+// resolution resolves a character's content against its packs
+func (s *Service) resolution(char *character.Character) (*Resolution, error) {
+	packs, versions, err := s.rules.pinned(char.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	r := NewResolver(s.rules.catalog, versions)
+
+	res := &Resolution{Packs: packs, Classes: []*ClassResolution{}, Unresolved: []string{}}
+	if res.Race = r.Race(char.Race); res.Race == nil {
+		// Characters often name their subrace, e.g. "Hill Dwarf"
+		if res.Subrace = r.Subrace(char.Race); res.Subrace != nil {
+			res.Race = r.Race(res.Subrace.Entry.Race)
+		}
+	}
+	if res.Race == nil {
+		res.Unresolved = append(res.Unresolved, "race")
+	}
+	for i, entry := range char.Classes {
+		class := &ClassResolution{Class: r.Class(entry.Class)}
+		if class.Class == nil {
+			res.Unresolved = append(res.Unresolved, fmt.Sprintf("classes[%d].class", i))
+		}
+		if entry.Subclass != "" {
+			if class.Subclass = r.Subclass(entry.Subclass); class.Subclass == nil {
+				res.Unresolved = append(res.Unresolved, fmt.Sprintf("classes[%d].subclass", i))
+			}
+		}
+		res.Classes = append(res.Classes, class)
+	}
+	if res.Background = r.Background(char.Background); res.Background == nil {
+		res.Unresolved = append(res.Unresolved, "background")
+	}
+
+	return res, nil
+}
+
+// readable loads a pack the user owns or that is shared with one of their
+// campaigns. Packs the user can't see are reported as missing.
+func (s *Service) readable(packID, userID string) (*Pack, error) {
+	if !isValidID(packID) {
+		return nil, ErrPackNotFound
+	}
+
+	pack, err := s.store.Get(packID)
+	if err != nil {
+		return nil, err
+	}
+	if pack.OwnerID.String() == userID {
+		return pack, nil
+	}
+
+	if pack.CampaignID != nil {
+		campaigns, err := s.campaignIDs(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range campaigns {
+			if id == pack.CampaignID.String() {
+				return pack, nil
+			}
+		}
+	}
+	return nil, ErrPackNotFound
+}
+
+// owned loads a pack the user owns and that isn't archived
+func (s *Service) owned(packID, userID string) (*Pack, error) {
+	pack, err := s.readable(packID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pack.OwnerID.String() != userID {
+		return nil, ErrOwnerOnly
+	}
+	if pack.Archived {
+		return nil, ErrPackNotFound
+	}
+	return pack, nil
+}
+
+// sharedWith checks the campaign an upload is shared with, which the user
+// must be a DM of
+func (s *Service) sharedWith(userID string, campaignID *string) (*uuid.UUID, error) {
+	if campaignID == nil {
+		return nil, nil
+	}
+
+	campaigns, err := s.campaigns.ListCampaigns(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range campaigns {
+		if c.ID.String() == *campaignID {
+			if c.Role != campaign.RoleDM {
+				return nil, ErrCampaignDMOnly
+			}
+			id := c.ID
+			return &id, nil
+		}
+	}
+	return nil, ErrCampaignDMOnly
+}
+
+// campaignIDs returns the IDs of the campaigns a user belongs to
+func (s *Service) campaignIDs(userID string) ([]string, error) {
+	campaigns, err := s.campaigns.ListCampaigns(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(campaigns))
+	for _, c := range campaigns {
+		ids = append(ids, c.ID.String())
+	}
+	return ids, nil
+}
+
+// isValidID reports whether id is a UUID, so malformed IDs are reported as
+// missing rather than reaching the database
+func isValidID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
@@ -0,0 +1,42 @@
+package homebrew
+
+import "character-sheet-backend/internal/apperror"
+
+var (
+	// ErrPackNotFound is returned when a pack doesn't exist or the user
+	// can't see it
+	ErrPackNotFound = apperror.NotFound("pack_not_found", "Homebrew pack not found")
+	// ErrVersionNotFound is returned for a version a pack doesn't have
+	ErrVersionNotFound = apperror.NotFound("pack_version_not_found", "Homebrew pack version not found")
+	// ErrVersionConflict is returned when another upload added a version
+	// first
+	ErrVersionConflict = apperror.Conflict("pack_version_conflict", "The pack was updated by someone else, reload it and try again")
+)
+
+// Store persists homebrew packs, their versions and the packs characters
+// reference
+type Store interface {
+	// List returns the packs a user owns or that are shared with one of
+	// campaignIDs, leaving out archived packs
+	List(userID string, campaignIDs []string) ([]*Pack, error)
+	// Get returns a pack
+	Get(packID string) (*Pack, error)
+	// GetVersion returns one version of a pack with its content
+	GetVersion(packID string, version int) (*Version, error)
+	// ListVersions returns a pack's versions, newest first, without their
+	// content
+	ListVersions(packID string) ([]*Version, error)
+	// Create stores a new pack with its first version
+	Create(pack *Pack, version *Version) error
+	// AddVersion saves the pack's details and adds version, which must
+	// follow the pack's stored latest version
+	AddVersion(pack *Pack, version *Version) error
+	// Delete removes a pack, or archives it if any character references
+	// it. It reports whether the pack was archived.
+	Delete(packID string) (archived bool, err error)
+	// CharacterRefs returns the packs a character references, in lookup
+	// order
+	CharacterRefs(characterID string) ([]*Ref, error)
+	// SetCharacterRefs replaces the packs a character references
+	SetCharacterRefs(characterID string, refs []*Ref) error
+}
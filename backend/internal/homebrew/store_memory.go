@@ -0,0 +1,194 @@
+package homebrew
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a homebrew Store kept in process memory, for tests and
+// demos that run without a database
+type MemoryStore struct {
+	mu       sync.Mutex
+	packs    map[string]*Pack
+	versions map[string][]*Version
+	refs     map[string][]*Ref
+}
+
+// NewMemoryStore creates an empty in-memory homebrew store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		packs:    make(map[string]*Pack),
+		versions: make(map[string][]*Version),
+		refs:     make(map[string][]*Ref),
+	}
+}
+
+// List returns the packs a user owns or that are shared with one of
+// campaignIDs, by name
+func (s *MemoryStore) List(userID string, campaignIDs []string) ([]*Pack, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shared := make(map[string]bool, len(campaignIDs))
+	for _, id := range campaignIDs {
+		shared[id] = true
+	}
+
+	packs := []*Pack{}
+	for _, pack := range s.packs {
+		if pack.Archived {
+			continue
+		}
+		if pack.OwnerID.String() == userID || (pack.CampaignID != nil && shared[pack.CampaignID.String()]) {
+			packs = append(packs, pack.clone())
+		}
+	}
+	sort.Slice(packs, func(i, j int) bool {
+		if packs[i].Name != packs[j].Name {
+			return packs[i].Name < packs[j].Name
+		}
+		return packs[i].ID.String() < packs[j].ID.String()
+	})
+	return packs, nil
+}
+
+// Get returns a pack
+func (s *MemoryStore) Get(packID string) (*Pack, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pack, ok := s.packs[packID]
+	if !ok {
+		return nil, ErrPackNotFound
+	}
+	return pack.clone(), nil
+}
+
+// GetVersion returns one version of a pack with its content. Versions are
+// never changed once stored, so they are shared rather than copied.
+func (s *MemoryStore) GetVersion(packID string, version int) (*Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range s.versions[packID] {
+		if v.Version == version {
+			cp := *v
+			return &cp, nil
+		}
+	}
+	return nil, ErrVersionNotFound
+}
+
+// ListVersions returns a pack's versions, newest first, without their
+// content
+func (s *MemoryStore) ListVersions(packID string) ([]*Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := s.versions[packID]
+	list := make([]*Version, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		cp := *versions[i]
+		cp.Content = nil
+		list = append(list, &cp)
+	}
+	return list, nil
+}
+
+// Create stores a new pack with its first version
+func (s *MemoryStore) Create(pack *Pack, version *Version) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := pack.ID.String()
+	s.packs[id] = pack.clone()
+	cp := *version
+	s.versions[id] = []*Version{&cp}
+	return nil
+}
+
+// AddVersion saves the pack's details and adds version, which must follow
+// the pack's stored latest version
+func (s *MemoryStore) AddVersion(pack *Pack, version *Version) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := pack.ID.String()
+	existing, ok := s.packs[id]
+	if !ok || existing.Archived {
+		return ErrPackNotFound
+	}
+	if existing.LatestVersion != version.Version-1 {
+		return ErrVersionConflict
+	}
+
+	s.packs[id] = pack.clone()
+	cp := *version
+	s.versions[id] = append(s.versions[id], &cp)
+	return nil
+}
+
+// Delete removes a pack, or archives it if any character references it
+func (s *MemoryStore) Delete(packID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pack, ok := s.packs[packID]
+	if !ok {
+		return false, ErrPackNotFound
+	}
+
+	for _, refs := range s.refs {
+		for _, ref := range refs {
+			if ref.PackID == pack.ID {
+				pack.Archived = true
+				return true, nil
+			}
+		}
+	}
+
+	delete(s.packs, packID)
+	delete(s.versions, packID)
+	return false, nil
+}
+
+// CharacterRefs returns the packs a character references, in lookup order
+func (s *MemoryStore) CharacterRefs(characterID string) ([]*Ref, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refs := make([]*Ref, 0, len(s.refs[characterID]))
+	for _, ref := range s.refs[characterID] {
+		cp := *ref
+		refs = append(refs, &cp)
+	}
+	return refs, nil
+}
+
+// SetCharacterRefs replaces the packs a character references
+func (s *MemoryStore) SetCharacterRefs(characterID string, refs []*Ref) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := make([]*Ref, 0, len(refs))
+	for _, ref := range refs {
+		cp := *ref
+		stored = append(stored, &cp)
+	}
+	s.refs[characterID] = stored
+	return nil
+}
+
+// clone returns a copy of a pack that can be handed out safely
+func (p *Pack) clone() *Pack {
+	cp := *p
+	if p.CampaignID != nil {
+		id := *p.CampaignID
+		cp.CampaignID = &id
+	}
+	if p.Description != nil {
+		description := *p.Description
+		cp.Description = &description
+	}
+	return &cp
+}
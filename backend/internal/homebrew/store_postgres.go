@@ -0,0 +1,305 @@
+package homebrew
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// packColumns is the column list shared by every pack SELECT
+const packColumns = `
+	id, owner_id, campaign_id, name, description, latest_version, archived,
+	created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// PostgresStore is a homebrew Store backed by PostgreSQL
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a homebrew store using the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func scanPack(row rowScanner) (*Pack, error) {
+	pack := &Pack{}
+	err := row.Scan(
+		&pack.ID, &pack.OwnerID, &pack.CampaignID, &pack.Name, &pack.Description,
+		&pack.LatestVersion, &pack.Archived, &pack.CreatedAt, &pack.UpdatedAt,
+	)
+	return pack, err
+}
+
+// List returns the packs a user owns or that are shared with one of
+// campaignIDs, by name
+func (s *PostgresStore) List(userID string, campaignIDs []string) ([]*Pack, error) {
+	query := `SELECT ` + packColumns + `
+		FROM homebrew_packs
+		WHERE NOT archived AND (owner_id = $1 OR campaign_id = ANY($2::uuid[]))
+		ORDER BY name, id
+	`
+
+	rows, err := s.db.Query(query, userID, pq.Array(campaignIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query homebrew packs: %w", err)
+	}
+	defer rows.Close()
+
+	packs := []*Pack{}
+	for rows.Next() {
+		pack, err := scanPack(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan homebrew pack: %w", err)
+		}
+		packs = append(packs, pack)
+	}
+
+	return packs, rows.Err()
+}
+
+// Get returns a pack
+func (s *PostgresStore) Get(packID string) (*Pack, error) {
+	query := `SELECT ` + packColumns + ` FROM homebrew_packs WHERE id = $1`
+
+	pack, err := scanPack(s.db.QueryRow(query, packID))
+	if err == sql.ErrNoRows {
+		return nil, ErrPackNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get homebrew pack: %w", err)
+	}
+
+	return pack, nil
+}
+
+// GetVersion returns one version of a pack with its content
+func (s *PostgresStore) GetVersion(packID string, version int) (*Version, error) {
+	v := &Version{}
+	var raw []byte
+	err := s.db.QueryRow(
+		"SELECT pack_id, version, content, created_at FROM homebrew_pack_versions WHERE pack_id = $1 AND version = $2",
+		packID, version,
+	).Scan(&v.PackID, &v.Version, &raw, &v.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrVersionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get homebrew pack version: %w", err)
+	}
+
+	if err := json.Unmarshal(raw, &v.Content); err != nil {
+		return nil, fmt.Errorf("failed to decode homebrew pack content: %w", err)
+	}
+	return v, nil
+}
+
+// ListVersions returns a pack's versions, newest first, without their
+// content
+func (s *PostgresStore) ListVersions(packID string) ([]*Version, error) {
+	rows, err := s.db.Query(
+		"SELECT pack_id, version, created_at FROM homebrew_pack_versions WHERE pack_id = $1 ORDER BY version DESC",
+		packID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query homebrew pack versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []*Version{}
+	for rows.Next() {
+		v := &Version{}
+		if err := rows.Scan(&v.PackID, &v.Version, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan homebrew pack version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+// Create stores a new pack with its first version
+func (s *PostgresStore) Create(pack *Pack, version *Version) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO homebrew_packs (`+packColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		pack.ID, pack.OwnerID, pack.CampaignID, pack.Name, pack.Description,
+		pack.LatestVersion, pack.Archived, pack.CreatedAt, pack.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create homebrew pack: %w", err)
+	}
+
+	if err := insertVersion(tx, version); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit homebrew pack: %w", err)
+	}
+	return nil
+}
+
+// AddVersion saves the pack's details and adds version, which must follow
+// the pack's stored latest version
+func (s *PostgresStore) AddVersion(pack *Pack, version *Version) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE homebrew_packs SET
+			campaign_id = $3, name = $4, description = $5, latest_version = $2,
+			updated_at = $6
+		WHERE id = $1 AND latest_version = $2 - 1 AND NOT archived`,
+		pack.ID, version.Version, pack.CampaignID, pack.Name, pack.Description, pack.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update homebrew pack: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if n == 0 {
+		var archived bool
+		err := tx.QueryRow("SELECT archived FROM homebrew_packs WHERE id = $1", pack.ID).Scan(&archived)
+		if err == sql.ErrNoRows || archived {
+			return ErrPackNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check homebrew pack: %w", err)
+		}
+		return ErrVersionConflict
+	}
+
+	if err := insertVersion(tx, version); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit homebrew pack: %w", err)
+	}
+	return nil
+}
+
+func insertVersion(tx *sql.Tx, version *Version) error {
+	raw, err := json.Marshal(version.Content)
+	if err != nil {
+		return fmt.Errorf("failed to encode homebrew pack content: %w", err)
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO homebrew_pack_versions (pack_id, version, content, created_at) VALUES ($1, $2, $3, $4)",
+		version.PackID, version.Version, raw, version.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create homebrew pack version: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a pack, or archives it if any character references it
+func (s *PostgresStore) Delete(packID string) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var referenced bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM character_packs WHERE pack_id = p.id)
+		FROM homebrew_packs p
+		WHERE p.id = $1
+		FOR UPDATE`,
+		packID,
+	).Scan(&referenced)
+	if err == sql.ErrNoRows {
+		return false, ErrPackNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get homebrew pack: %w", err)
+	}
+
+	if referenced {
+		_, err = tx.Exec("UPDATE homebrew_packs SET archived = TRUE, updated_at = NOW() WHERE id = $1", packID)
+	} else {
+		_, err = tx.Exec("DELETE FROM homebrew_packs WHERE id = $1", packID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete homebrew pack: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit homebrew pack deletion: %w", err)
+	}
+	return referenced, nil
+}
+
+// CharacterRefs returns the packs a character references, in lookup order
+func (s *PostgresStore) CharacterRefs(characterID string) ([]*Ref, error) {
+	rows, err := s.db.Query(
+		"SELECT pack_id, version FROM character_packs WHERE character_id = $1 ORDER BY position",
+		characterID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query character packs: %w", err)
+	}
+	defer rows.Close()
+
+	refs := []*Ref{}
+	for rows.Next() {
+		ref := &Ref{}
+		if err := rows.Scan(&ref.PackID, &ref.Version); err != nil {
+			return nil, fmt.Errorf("failed to scan character pack: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, rows.Err()
+}
+
+// SetCharacterRefs replaces the packs a character references
+func (s *PostgresStore) SetCharacterRefs(characterID string, refs []*Ref) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT 1 FROM characters WHERE id = $1 FOR UPDATE", characterID); err != nil {
+		return fmt.Errorf("failed to lock character: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM character_packs WHERE character_id = $1", characterID); err != nil {
+		return fmt.Errorf("failed to clear character packs: %w", err)
+	}
+	for i, ref := range refs {
+		_, err := tx.Exec(
+			"INSERT INTO character_packs (character_id, pack_id, version, position) VALUES ($1, $2, $3, $4)",
+			characterID, ref.PackID, ref.Version, i,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add character pack: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit character packs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package homebrew
+
+import (
+	"fmt"
+	"regexp"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/character"
+	"character-sheet-backend/internal/content"
+)
+
+var (
+	indexPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	dicePattern  = regexp.MustCompile(`^[0-9]+(d[0-9]+)?$`)
+
+	sizes      = map[string]bool{"Tiny": true, "Small": true, "Medium": true, "Large": true}
+	hitDice    = map[int]bool{6: true, 8: true, 10: true, 12: true}
+	components = map[string]bool{"V": true, "S": true, "M": true}
+	schools    = map[string]bool{
+		"abjuration": true, "conjuration": true, "divination": true, "enchantment": true,
+		"evocation": true, "illusion": true, "necromancy": true, "transmutation": true,
+	}
+	categories = map[string]bool{
+		content.CategoryWeapon: true, content.CategoryArmor: true, content.CategoryAdventuringGear: true,
+		content.CategoryTool: true, content.CategoryMount: true,
+	}
+	armorTypes = map[string]bool{"light": true, "medium": true, "heavy": true, "shield": true}
+)
+
+// checker collects the problems found in a pack, keyed by the path of the
+// offending field such as classes[0].hit_die
+type checker struct {
+	catalog *content.Catalog
+	pack    *Content
+	fields  map[string]string
+}
+
+// validate checks pack content against the homebrew schema. References
+// between entries may point into the pack itself or at the SRD, but entries
+// may not reuse an SRD index or name, so a reference always means one thing.
+func validate(catalog *content.Catalog, pack *Content) error {
+	c := &checker{catalog: catalog, pack: pack, fields: map[string]string{}}
+
+	c.checkCount("races", len(pack.Races))
+	c.checkCount("subraces", len(pack.Subraces))
+	c.checkCount("classes", len(pack.Classes))
+	c.checkCount("subclasses", len(pack.Subclasses))
+	c.checkCount("backgrounds", len(pack.Backgrounds))
+	c.checkCount("spells", len(pack.Spells))
+	c.checkCount("items", len(pack.Items))
+
+	seen := map[string]bool{}
+	for i, race := range pack.Races {
+		path := fmt.Sprintf("races[%d]", i)
+		if race == nil {
+			c.fail(path, "is required")
+			continue
+		}
+		_, err := catalog.Race(race.Index)
+		_, byName := catalog.Race(race.Name)
+		c.checkEntry(path, race.Index, race.Name, seen, err == nil || byName == nil)
+		if !sizes[race.Size] {
+			c.fail(path+".size", "must be one of: Tiny Small Medium Large")
+		}
+		c.checkRange(path+".speed", race.Speed, 0, 120)
+		c.checkRange(path+".darkvision", race.Darkvision, 0, 300)
+		c.checkBonuses(path+".ability_bonuses", race.AbilityBonuses)
+		if choice := race.AbilityBonusChoice; choice != nil {
+			c.checkRange(path+".ability_bonus_choice.count", choice.Count, 1, len(character.Abilities))
+			c.checkAbilities(path+".ability_bonus_choice.from", choice.From)
+		}
+		c.checkRange(path+".language_choices", race.LanguageChoices, 0, 10)
+		c.checkTraits(path+".traits", race.Traits)
+		for j, index := range race.Subraces {
+			if sub := findSubrace(pack, index); sub == nil || sub.Race != race.Index {
+				c.fail(fmt.Sprintf("%s.subraces[%d]", path, j), "must be a subrace of this race in the pack")
+			}
+		}
+	}
+
+	seen = map[string]bool{}
+	for i, sub := range pack.Subraces {
+		path := fmt.Sprintf("subraces[%d]", i)
+		if sub == nil {
+			c.fail(path, "is required")
+			continue
+		}
+		_, err := catalog.Subrace(sub.Index)
+		_, byName := catalog.Subrace(sub.Name)
+		c.checkEntry(path, sub.Index, sub.Name, seen, err == nil || byName == nil)
+		if !c.hasRace(sub.Race) {
+			c.fail(path+".race", "must be a race in the pack or the SRD")
+		}
+		c.checkBonuses(path+".ability_bonuses", sub.AbilityBonuses)
+		c.checkTraits(path+".traits", sub.Traits)
+	}
+
+	seen = map[string]bool{}
+	for i, class := range pack.Classes {
+		path := fmt.Sprintf("classes[%d]", i)
+		if class == nil {
+			c.fail(path, "is required")
+			continue
+		}
+		_, err := catalog.Class(class.Index)
+		_, byName := catalog.Class(class.Name)
+		c.checkEntry(path, class.Index, class.Name, seen, err == nil || byName == nil)
+		if !hitDice[class.HitDie] {
+			c.fail(path+".hit_die", "must be one of: 6 8 10 12")
+		}
+		c.checkAbilities(path+".primary_abilities", class.PrimaryAbilities)
+		c.checkAbilities(path+".saving_throws", class.SavingThrows)
+		if class.SpellcastingAbility != "" && !isAbility(class.SpellcastingAbility) {
+			c.fail(path+".spellcasting_ability", "must be an ability")
+		}
+		c.checkRange(path+".skill_choices.count", class.SkillChoices.Count, 0, len(character.Skills))
+		c.checkSkills(path+".skill_choices.from", class.SkillChoices.From)
+		for ability, score := range class.Multiclass.Prerequisites {
+			if !isAbility(ability) {
+				c.fail(path+".multiclass.prerequisites", fmt.Sprintf("%q is not an ability", ability))
+			}
+			c.checkRange(path+".multiclass.prerequisites."+ability, score, 1, 30)
+		}
+		c.checkRange(path+".subclass_level", class.SubclassLevel, 1, 20)
+		for j, level := range class.ASILevels {
+			c.checkRange(fmt.Sprintf("%s.asi_levels[%d]", path, j), level, 1, 20)
+		}
+		c.checkFeatures(path+".features", class.Features)
+		for j, index := range class.Subclasses {
+			if sub := findSubclass(pack, index); sub == nil || sub.Class != class.Index {
+				c.fail(fmt.Sprintf("%s.subclasses[%d]", path, j), "must be a subclass of this class in the pack")
+			}
+		}
+	}
+
+	seen = map[string]bool{}
+	for i, sub := range pack.Subclasses {
+		path := fmt.Sprintf("subclasses[%d]", i)
+		if sub == nil {
+			c.fail(path, "is required")
+			continue
+		}
+		_, err := catalog.Subclass(sub.Index)
+		_, byName := catalog.Subclass(sub.Name)
+		c.checkEntry(path, sub.Index, sub.Name, seen, err == nil || byName == nil)
+		if !c.hasClass(sub.Class) {
+			c.fail(path+".class", "must be a class in the pack or the SRD")
+		}
+		c.checkFeatures(path+".features", sub.Features)
+	}
+
+	seen = map[string]bool{}
+	for i, bg := range pack.Backgrounds {
+		path := fmt.Sprintf("backgrounds[%d]", i)
+		if bg == nil {
+			c.fail(path, "is required")
+			continue
+		}
+		_, err := catalog.Background(bg.Index)
+		_, byName := catalog.Background(bg.Name)
+		c.checkEntry(path, bg.Index, bg.Name, seen, err == nil || byName == nil)
+		c.checkSkills(path+".skill_proficiencies", bg.SkillProficiencies)
+		c.checkRange(path+".language_choices", bg.LanguageChoices, 0, 10)
+	}
+
+	seen = map[string]bool{}
+	for i, spell := range pack.Spells {
+		path := fmt.Sprintf("spells[%d]", i)
+		if spell == nil {
+			c.fail(path, "is required")
+			continue
+		}
+		_, err := catalog.Spell(spell.Index)
+		_, byName := catalog.Spell(spell.Name)
+		c.checkEntry(path, spell.Index, spell.Name, seen, err == nil || byName == nil)
+		c.checkRange(path+".level", spell.Level, 0, 9)
+		if !schools[spell.School] {
+			c.fail(path+".school", "must be a school of magic, e.g. evocation")
+		}
+		for j, comp := range spell.Components {
+			if !components[comp] {
+				c.fail(fmt.Sprintf("%s.components[%d]", path, j), "must be one of: V S M")
+			}
+		}
+		for j, class := range spell.Classes {
+			if !c.hasClass(class) {
+				c.fail(fmt.Sprintf("%s.classes[%d]", path, j), "must be a class in the pack or the SRD")
+			}
+		}
+	}
+
+	seen = map[string]bool{}
+	for i, item := range pack.Items {
+		path := fmt.Sprintf("items[%d]", i)
+		if item == nil {
+			c.fail(path, "is required")
+			continue
+		}
+		_, err := catalog.Item(item.Index)
+		_, byName := catalog.Item(item.Name)
+		c.checkEntry(path, item.Index, item.Name, seen, err == nil || byName == nil)
+		c.checkItem(path, item)
+	}
+
+	if len(c.fields) > 0 {
+		return apperror.Validation("invalid_pack", "Pack content is invalid", c.fields)
+	}
+	return nil
+}
+
+func (c *checker) fail(path, message string) {
+	if _, ok := c.fields[path]; !ok {
+		c.fields[path] = message
+	}
+}
+
+func (c *checker) checkCount(path string, n int) {
+	if n > MaxEntries {
+		c.fail(path, fmt.Sprintf("must have at most %d entries", MaxEntries))
+	}
+}
+
+// checkEntry checks an entry's index and name, which must be unique among
+// entries of the same kind and unused by the SRD
+func (c *checker) checkEntry(path, index, name string, seen map[string]bool, inSRD bool) {
+	switch {
+	case !indexPattern.MatchString(index) || len(index) > 100:
+		c.fail(path+".index", "must be lower case letters and digits separated by dashes")
+	case seen[index]:
+		c.fail(path+".index", "is used by another entry")
+	case inSRD:
+		c.fail(path+".index", "is already used by the SRD")
+	}
+	seen[index] = true
+
+	if name == "" || len(name) > 100 {
+		c.fail(path+".name", "is required and must be at most 100 characters")
+	}
+}
+
+func (c *checker) checkRange(path string, n, min, max int) {
+	if n < min || n > max {
+		c.fail(path, fmt.Sprintf("must be between %d and %d", min, max))
+	}
+}
+
+func (c *checker) checkBonuses(path string, bonuses map[string]int) {
+	for ability, bonus := range bonuses {
+		if !isAbility(ability) {
+			c.fail(path, fmt.Sprintf("%q is not an ability", ability))
+		}
+		c.checkRange(path+"."+ability, bonus, -5, 5)
+	}
+}
+
+func (c *checker) checkAbilities(path string, abilities []string) {
+	for i, ability := range abilities {
+		if !isAbility(ability) {
+			c.fail(fmt.Sprintf("%s[%d]", path, i), "must be an ability")
+		}
+	}
+}
+
+func (c *checker) checkSkills(path string, skills []string) {
+	for i, skill := range skills {
+		if _, ok := character.Skills[skill]; !ok {
+			c.fail(fmt.Sprintf("%s[%d]", path, i), "must be a skill, e.g. sleight_of_hand")
+		}
+	}
+}
+
+func (c *checker) checkTraits(path string, traits []content.Trait) {
+	for i, trait := range traits {
+		if trait.Name == "" {
+			c.fail(fmt.Sprintf("%s[%d].name", path, i), "is required")
+		}
+	}
+}
+
+func (c *checker) checkFeatures(path string, features []content.Feature) {
+	for i, feature := range features {
+		c.checkRange(fmt.Sprintf("%s[%d].level", path, i), feature.Level, 1, 20)
+		if feature.Name == "" {
+			c.fail(fmt.Sprintf("%s[%d].name", path, i), "is required")
+		}
+	}
+}
+
+func (c *checker) checkItem(path string, item *content.Equipment) {
+	if !categories[item.Category] {
+		c.fail(path+".category", "must be one of: weapon armor adventuring_gear tool mount")
+	}
+	if item.CostCP < 0 {
+		c.fail(path+".cost_cp", "must be at least 0")
+	}
+	if item.Weight < 0 {
+		c.fail(path+".weight", "must be at least 0")
+	}
+
+	if (item.Weapon != nil) != (item.Category == content.CategoryWeapon) {
+		c.fail(path+".weapon", "is required for weapons and only allowed on them")
+	} else if w := item.Weapon; w != nil {
+		if w.Category != "simple" && w.Category != "martial" {
+			c.fail(path+".weapon.category", "must be one of: simple martial")
+		}
+		if w.Range != "melee" && w.Range != "ranged" {
+			c.fail(path+".weapon.range", "must be one of: melee ranged")
+		}
+		if w.Damage != nil && !dicePattern.MatchString(w.Damage.Dice) {
+			c.fail(path+".weapon.damage.dice", "must be dice such as 1d8")
+		}
+		if w.Versatile != "" && !dicePattern.MatchString(w.Versatile) {
+			c.fail(path+".weapon.versatile", "must be dice such as 1d10")
+		}
+	}
+
+	if (item.Armor != nil) != (item.Category == content.CategoryArmor) {
+		c.fail(path+".armor", "is required for armor and only allowed on it")
+	} else if a := item.Armor; a != nil {
+		if !armorTypes[a.Type] {
+			c.fail(path+".armor.type", "must be one of: light medium heavy shield")
+		}
+		c.checkRange(path+".armor.base_ac", a.BaseAC, 0, 30)
+		c.checkRange(path+".armor.strength_minimum", a.StrengthMinimum, 0, 30)
+	}
+}
+
+func (c *checker) hasRace(key string) bool {
+	if _, err := c.catalog.Race(key); err == nil {
+		return true
+	}
+	for _, race := range c.pack.Races {
+		if race != nil && race.Index == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) hasClass(key string) bool {
+	if _, err := c.catalog.Class(key); err == nil {
+		return true
+	}
+	for _, class := range c.pack.Classes {
+		if class != nil && class.Index == key {
+			return true
+		}
+	}
+	return false
+}
+
+func findSubrace(pack *Content, index string) *content.Subrace {
+	for _, sub := range pack.Subraces {
+		if sub != nil && sub.Index == index {
+			return sub
+		}
+	}
+	return nil
+}
+
+func findSubclass(pack *Content, index string) *content.Subclass {
+	for _, sub := range pack.Subclasses {
+		if sub != nil && sub.Index == index {
+			return sub
+		}
+	}
+	return nil
+}
+
+func isAbility(name string) bool {
+	for _, ability := range character.Abilities {
+		if ability == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package homebrew
+
+import (
+	"errors"
+	"testing"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/content"
+)
+
+func loadCatalog(t *testing.T) *content.Catalog {
+	t.Helper()
+	catalog, err := content.Load()
+	if err != nil {
+		t.Fatalf("content.Load returned %v", err)
+	}
+	return catalog
+}
+
+// testPack is valid pack content with one entry of each kind, referring to
+// each other and to the SRD
+func testPack() *Content {
+	return &Content{
+		Races: []*content.Race{{
+			Index: "goblin", Name: "Goblin", Size: "Small", Speed: 30, Darkvision: 60,
+			AbilityBonuses: map[string]int{"dexterity": 2}, Subraces: []string{"forest-goblin"},
+		}},
+		Subraces: []*content.Subrace{{Index: "forest-goblin", Name: "Forest Goblin", Race: "goblin"}},
+		Classes: []*content.Class{{
+			Index: "gunslinger", Name: "Gunslinger", HitDie: 8,
+			PrimaryAbilities: []string{"dexterity"}, SavingThrows: []string{"dexterity", "charisma"},
+			Multiclass:    content.Multiclass{Prerequisites: map[string]int{"dexterity": 13}},
+			SubclassLevel: 3, Subclasses: []string{"high-roller"},
+			Features: []content.Feature{{Level: 1, Name: "Grit"}},
+		}},
+		Subclasses: []*content.Subclass{
+			{Index: "high-roller", Name: "High Roller", Class: "gunslinger"},
+			{Index: "hexblade-of-the-dunes", Name: "Hexblade of the Dunes", Class: "warlock"},
+		},
+		Backgrounds: []*content.Background{{Index: "outlaw", Name: "Outlaw", SkillProficiencies: []string{"stealth"}}},
+		Spells: []*content.Spell{{
+			Index: "quick-draw", Name: "Quick Draw", Level: 1, School: "evocation",
+			Components: []string{"V", "S"}, Classes: []string{"gunslinger", "wizard"},
+		}},
+		Items: []*content.Equipment{{
+			Index: "pepperbox", Name: "Pepperbox", Category: content.CategoryWeapon, CostCP: 25000, Weight: 5,
+			Weapon: &content.WeaponStats{Category: "martial", Range: "ranged", Damage: &content.Damage{Dice: "1d10", Type: "piercing"}},
+		}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	catalog := loadCatalog(t)
+	tests := []struct {
+		name   string
+		change func(pack *Content)
+		// field is the path of the problem reported, or "" for a valid pack
+		field string
+	}{
+		{"a valid pack", func(pack *Content) {}, ""},
+		{"an empty pack", func(pack *Content) { *pack = Content{} }, ""},
+		{"an index that isn't a slug", func(pack *Content) { pack.Classes[0].Index = "Gun Slinger" }, "classes[0].index"},
+		{"a missing name", func(pack *Content) { pack.Backgrounds[0].Name = "" }, "backgrounds[0].name"},
+		{"a missing entry", func(pack *Content) { pack.Items = append(pack.Items, nil) }, "items[1]"},
+		{"too many entries", func(pack *Content) { pack.Spells = make([]*content.Spell, MaxEntries+1) }, "spells"},
+
+		// Duplicates within the pack
+		{"a duplicate index", func(pack *Content) {
+			pack.Subclasses = append(pack.Subclasses, &content.Subclass{Index: "high-roller", Name: "Other", Class: "gunslinger"})
+		}, "subclasses[2].index"},
+		// The same index may be used for entries of different kinds
+		{"an index shared across kinds", func(pack *Content) { pack.Backgrounds[0].Index = "gunslinger" }, ""},
+
+		// Collisions with the SRD
+		{"an SRD index", func(pack *Content) { pack.Classes[0].Index = "fighter" }, "classes[0].index"},
+		{"an SRD name", func(pack *Content) { pack.Races[0].Name = "Elf" }, "races[0].index"},
+		{"an SRD name in another case", func(pack *Content) { pack.Spells[0].Name = "FIREBALL" }, "spells[0].index"},
+		{"an SRD item", func(pack *Content) { pack.Items[0].Index = "dagger" }, "items[0].index"},
+
+		// References
+		{"a subrace of an unknown race", func(pack *Content) { pack.Subraces[0].Race = "orc" }, "subraces[0].race"},
+		{"a subrace of an SRD race", func(pack *Content) {
+			pack.Races[0].Subraces = nil
+			pack.Subraces[0].Race = "elf"
+		}, ""},
+		{"a race listing another race's subrace", func(pack *Content) {
+			pack.Races[0].Subraces = []string{"forest-goblin"}
+			pack.Subraces[0].Race = "dwarf"
+		}, "races[0].subraces[0]"},
+		{"a race listing a missing subrace", func(pack *Content) { pack.Races[0].Subraces = []string{"cave-goblin"} }, "races[0].subraces[0]"},
+		{"a subclass of an unknown class", func(pack *Content) { pack.Subclasses[1].Class = "witch" }, "subclasses[1].class"},
+		{"a class listing a missing subclass", func(pack *Content) { pack.Classes[0].Subclasses = []string{"champion"} }, "classes[0].subclasses[0]"},
+		{"a spell of an unknown class", func(pack *Content) { pack.Spells[0].Classes = []string{"gunslinger", "witch"} }, "spells[0].classes[1]"},
+
+		// Schema
+		{"a hit die that isn't one", func(pack *Content) { pack.Classes[0].HitDie = 7 }, "classes[0].hit_die"},
+		{"a prerequisite that isn't an ability", func(pack *Content) {
+			pack.Classes[0].Multiclass.Prerequisites = map[string]int{"luck": 13}
+		}, "classes[0].multiclass.prerequisites"},
+		{"a spell above 9th level", func(pack *Content) { pack.Spells[0].Level = 10 }, "spells[0].level"},
+		{"an unknown skill", func(pack *Content) { pack.Backgrounds[0].SkillProficiencies = []string{"lockpicking"} }, "backgrounds[0].skill_proficiencies[0]"},
+		{"a weapon without stats", func(pack *Content) { pack.Items[0].Weapon = nil }, "items[0].weapon"},
+		{"weapon stats on gear", func(pack *Content) { pack.Items[0].Category = content.CategoryAdventuringGear }, "items[0].weapon"},
+		{"weapon damage that isn't dice", func(pack *Content) { pack.Items[0].Weapon.Damage.Dice = "d10+1" }, "items[0].weapon.damage.dice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pack := testPack()
+			tt.change(pack)
+			err := validate(catalog, pack)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("validate returned %v", err)
+				}
+				return
+			}
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Code != "invalid_pack" {
+				t.Fatalf("validate returned %v, want invalid_pack", err)
+			}
+			if _, ok := appErr.Fields[tt.field]; !ok {
+				t.Errorf("fields = %v, want %s", appErr.Fields, tt.field)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package inventory
+
+import "math"
+
+// Variant encumbrance levels
+const (
+	Unencumbered      = "unencumbered"
+	Encumbered        = "encumbered"
+	HeavilyEncumbered = "heavily_encumbered"
+	OverCapacity      = "over_capacity"
+)
+
+// Encumbrance describes how a character's load affects them under both the
+// standard rule and the variant encumbrance rule. Weights are in pounds and
+// assume a Medium creature.
+type Encumbrance struct {
+	Carried float64 `json:"carried"`
+	// Capacity is Strength x 15, the most a character can carry
+	Capacity float64 `json:"capacity"`
+	// PushDragLift is Strength x 30
+	PushDragLift float64 `json:"push_drag_lift"`
+	// OverCapacity is the standard rule's only penalty: above capacity the
+	// character can only push or drag the load, at 5 feet of speed
+	OverCapacity bool               `json:"over_capacity"`
+	Variant      VariantEncumbrance `json:"variant"`
+}
+
+// VariantEncumbrance applies the optional rule that slows characters well
+// before they reach their capacity
+type VariantEncumbrance struct {
+	Status string `json:"status"`
+	// EncumberedAt is Strength x 5 and HeavilyEncumberedAt Strength x 10
+	EncumberedAt        float64 `json:"encumbered_at"`
+	HeavilyEncumberedAt float64 `json:"heavily_encumbered_at"`
+	// SpeedPenalty is subtracted from the character's speed in feet
+	SpeedPenalty int `json:"speed_penalty"`
+	// Disadvantage applies to ability checks, attack rolls and saving throws
+	// that use Strength, Dexterity or Constitution
+	Disadvantage bool `json:"disadvantage"`
+}
+
+// ComputeEncumbrance works out the load on a character with the given
+// Strength score carrying items
+func ComputeEncumbrance(strength int, items []*Item) *Encumbrance {
+	str := float64(strength)
+	carried := CarriedWeight(items)
+
+	enc := &Encumbrance{
+		Carried:      carried,
+		Capacity:     str * 15,
+		PushDragLift: str * 30,
+		OverCapacity: carried > str*15,
+		Variant: VariantEncumbrance{
+			Status:              Unencumbered,
+			EncumberedAt:        str * 5,
+			HeavilyEncumberedAt: str * 10,
+		},
+	}
+
+	switch {
+	case enc.OverCapacity:
+		enc.Variant.Status = OverCapacity
+		enc.Variant.SpeedPenalty = 20
+		enc.Variant.Disadvantage = true
+	case carried > enc.Variant.HeavilyEncumberedAt:
+		enc.Variant.Status = HeavilyEncumbered
+		enc.Variant.SpeedPenalty = 20
+		enc.Variant.Disadvantage = true
+	case carried > enc.Variant.EncumberedAt:
+		enc.Variant.Status = Encumbered
+		enc.Variant.SpeedPenalty = 10
+	}
+
+	return enc
+}
+
+// CarriedWeight totals the weight of items, leaving out anything stored
+// (at any depth) inside a container with weightless contents
+func CarriedWeight(items []*Item) float64 {
+	byID := make(map[string]*Item, len(items))
+	for _, item := range items {
+		byID[item.ID.String()] = item
+	}
+
+	total := 0.0
+	for _, item := range items {
+		if !insideWeightless(item, byID) {
+			total += item.Weight * float64(item.Quantity)
+		}
+	}
+
+	// Round away floating point noise from summing fractional weights
+	return math.Round(total*100) / 100
+}
+
+// insideWeightless reports whether an item is nested anywhere inside a
+// container whose contents weigh nothing
+func insideWeightless(item *Item, byID map[string]*Item) bool {
+	for depth := 0; item.ContainerID != nil && depth < len(byID); depth++ {
+		container, ok := byID[item.ContainerID.String()]
+		if !ok {
+			return false
+		}
+		if container.WeightlessContents {
+			return true
+		}
+		item = container
+	}
+	return false
+}
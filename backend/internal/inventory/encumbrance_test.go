@@ -0,0 +1,85 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// newItem returns an item weighing weight pounds a unit, stored in
+// container when it isn't nil
+func newItem(quantity int, weight float64, container *Item) *Item {
+	item := &Item{ID: uuid.New(), Name: "Item", Quantity: quantity, Weight: weight}
+	if container != nil {
+		item.ContainerID = &container.ID
+	}
+	return item
+}
+
+func TestCarriedWeight(t *testing.T) {
+	backpack := newItem(1, 5, nil)
+	backpack.IsContainer = true
+	holding := newItem(1, 15, backpack)
+	holding.IsContainer = true
+	holding.WeightlessContents = true
+	pouch := newItem(1, 1, holding)
+	pouch.IsContainer = true
+
+	tests := []struct {
+		name  string
+		items []*Item
+		want  float64
+	}{
+		{"nothing", nil, 0},
+		{"weight per unit", []*Item{newItem(3, 2.5, nil)}, 7.5},
+		{"no units", []*Item{newItem(0, 10, nil)}, 0},
+		{"fractional weights", []*Item{newItem(10, 0.1, nil), newItem(1, 0.2, nil)}, 1.2},
+		{"in a container", []*Item{backpack, newItem(2, 3, backpack)}, 11},
+		// The bag of holding weighs 15 pounds but nothing inside it does, at
+		// any depth
+		{"in a weightless container", []*Item{backpack, holding, pouch, newItem(1, 50, holding), newItem(4, 2, pouch)}, 20},
+		{"in a missing container", []*Item{newItem(1, 4, holding)}, 4},
+	}
+
+	for _, tt := range tests {
+		if got := CarriedWeight(tt.items); got != tt.want {
+			t.Errorf("%s: CarriedWeight() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestComputeEncumbrance(t *testing.T) {
+	tests := []struct {
+		carried      float64
+		status       string
+		speedPenalty int
+		disadvantage bool
+		overCapacity bool
+	}{
+		{0, Unencumbered, 0, false, false},
+		{50, Unencumbered, 0, false, false},
+		{50.5, Encumbered, 10, false, false},
+		{100, Encumbered, 10, false, false},
+		{101, HeavilyEncumbered, 20, true, false},
+		{150, HeavilyEncumbered, 20, true, false},
+		{151, OverCapacity, 20, true, true},
+	}
+
+	for _, tt := range tests {
+		enc := ComputeEncumbrance(10, []*Item{newItem(1, tt.carried, nil)})
+		if enc.Carried != tt.carried || enc.Capacity != 150 || enc.PushDragLift != 300 {
+			t.Errorf("carrying %v: carried %v of %v, push %v", tt.carried, enc.Carried, enc.Capacity, enc.PushDragLift)
+		}
+		if enc.Variant.EncumberedAt != 50 || enc.Variant.HeavilyEncumberedAt != 100 {
+			t.Errorf("carrying %v: thresholds %v/%v, want 50/100", tt.carried, enc.Variant.EncumberedAt, enc.Variant.HeavilyEncumberedAt)
+		}
+		if enc.OverCapacity != tt.overCapacity {
+			t.Errorf("carrying %v: over capacity = %t, want %t", tt.carried, enc.OverCapacity, tt.overCapacity)
+		}
+		v := enc.Variant
+		if v.Status != tt.status || v.SpeedPenalty != tt.speedPenalty || v.Disadvantage != tt.disadvantage {
+			t.Errorf("carrying %v: variant %s (-%d, disadvantage %t), want %s (-%d, disadvantage %t)",
+				tt.carried, v.Status, v.SpeedPenalty, v.Disadvantage, tt.status, tt.speedPenalty, tt.disadvantage)
+		}
+	}
+}
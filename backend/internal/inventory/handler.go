@@ -0,0 +1,115 @@
+package inventory
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// Handler handles inventory HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new inventory handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetInventory returns a character's items and encumbrance
+func (h *Handler) GetInventory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	inv, err := h.service.GetInventory(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inv)
+}
+
+// GetItem returns a single item
+func (h *Handler) GetItem(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	item, err := h.service.GetItem(c.Param("id"), userID.(string), c.Param("itemId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// CreateItem adds an item to a character
+func (h *Handler) CreateItem(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req CreateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	item, err := h.service.CreateItem(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateItem changes an item
+func (h *Handler) UpdateItem(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req UpdateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	item, err := h.service.UpdateItem(c.Param("id"), userID.(string), c.Param("itemId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteItem removes an item
+func (h *Handler) DeleteItem(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	if err := h.service.DeleteItem(c.Param("id"), userID.(string), c.Param("itemId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item deleted successfully"})
+}
@@ -0,0 +1,73 @@
+package inventory
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxAttuned is the number of magic items a character can be attuned to
+const MaxAttuned = 3
+
+// Item is something a character carries
+type Item struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	CharacterID uuid.UUID `json:"character_id" db:"character_id"`
+	// ContainerID is the item this one is stored in, or nil when carried
+	// directly
+	ContainerID *uuid.UUID `json:"container_id" db:"container_id"`
+	Name        string     `json:"name" db:"name"`
+	Quantity    int        `json:"quantity" db:"quantity"`
+	// Weight is in pounds per unit
+	Weight float64 `json:"weight" db:"weight"`
+	// CostCP is the value of one unit in copper pieces
+	CostCP   int  `json:"cost_cp" db:"cost_cp"`
+	Equipped bool `json:"equipped" db:"equipped"`
+	Attuned  bool `json:"attuned" db:"attuned"`
+	// IsContainer allows other items to be stored in this one
+	IsContainer bool `json:"is_container" db:"is_container"`
+	// WeightlessContents leaves the contents out of the carried weight, as
+	// with a Bag of Holding
+	WeightlessContents bool      `json:"weightless_contents" db:"weightless_contents"`
+	Notes              *string   `json:"notes" db:"notes"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Inventory is a character's items with the totals computed from them
+type Inventory struct {
+	Items       []*Item      `json:"items"`
+	Attuned     int          `json:"attuned"`
+	MaxAttuned  int          `json:"max_attuned"`
+	TotalCostCP int          `json:"total_cost_cp"`
+	Encumbrance *Encumbrance `json:"encumbrance"`
+}
+
+// CreateItemRequest represents the request to add an item
+type CreateItemRequest struct {
+	Name               string  `json:"name" binding:"required,max=255"`
+	Quantity           *int    `json:"quantity" binding:"omitempty,min=0"`
+	Weight             float64 `json:"weight" binding:"min=0"`
+	CostCP             int     `json:"cost_cp" binding:"min=0"`
+	Equipped           bool    `json:"equipped"`
+	Attuned            bool    `json:"attuned"`
+	IsContainer        bool    `json:"is_container"`
+	WeightlessContents bool    `json:"weightless_contents"`
+	ContainerID        *string `json:"container_id" binding:"omitempty,uuid"`
+	Notes              *string `json:"notes"`
+}
+
+// UpdateItemRequest represents the request to change an item. An empty
+// container_id takes the item out of its container.
+type UpdateItemRequest struct {
+	Name               *string  `json:"name" binding:"omitempty,min=1,max=255"`
+	Quantity           *int     `json:"quantity" binding:"omitempty,min=0"`
+	Weight             *float64 `json:"weight" binding:"omitempty,min=0"`
+	CostCP             *int     `json:"cost_cp" binding:"omitempty,min=0"`
+	Equipped           *bool    `json:"equipped"`
+	Attuned            *bool    `json:"attuned"`
+	IsContainer        *bool    `json:"is_container"`
+	WeightlessContents *bool    `json:"weightless_contents"`
+	ContainerID        *string  `json:"container_id"`
+	Notes              *string  `json:"notes"`
+}
@@ -0,0 +1,238 @@
+package inventory
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/character"
+)
+
+var (
+	// ErrInvalidContainer is returned when an item is stored in something
+	// that isn't one of the character's containers
+	ErrInvalidContainer = apperror.Field("container_id", "must be a container carried by this character")
+	// ErrContainerCycle is returned when a container would end up inside
+	// itself
+	ErrContainerCycle = apperror.Field("container_id", "can't store a container inside itself")
+	// ErrContainerNotEmpty is returned when an item holding other items
+	// stops being a container
+	ErrContainerNotEmpty = apperror.Conflict("container_not_empty", "Take the items out of this container first")
+)
+
+// Service handles inventory operations
+type Service struct {
+	store      Store
+	characters *character.Service
+}
+
+// NewService creates a new inventory service
+func NewService(store Store, characters *character.Service) *Service {
+	return &Service{store: store, characters: characters}
+}
+
+// GetInventory returns a character's items with attunement and encumbrance
+// totals
+func (s *Service) GetInventory(characterID, userID string) (*Inventory, error) {
+	char, err := s.characters.Authorize(characterID, userID, character.AccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.store.List(characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Inventory{
+		Items:       items,
+		MaxAttuned:  MaxAttuned,
+		Encumbrance: ComputeEncumbrance(char.Strength, items),
+	}
+	for _, item := range items {
+		if item.Attuned {
+			inv.Attuned++
+		}
+		inv.TotalCostCP += item.CostCP * item.Quantity
+	}
+
+	return inv, nil
+}
+
+// GetItem returns a single item
+func (s *Service) GetItem(characterID, userID, itemID string) (*Item, error) {
+	if _, err := s.characters.Authorize(characterID, userID, character.AccessRead); err != nil {
+		return nil, err
+	}
+	if !isValidID(itemID) {
+		return nil, ErrItemNotFound
+	}
+
+	return s.store.Get(characterID, itemID)
+}
+
+// CreateItem adds an item to a character the user owns
+func (s *Service) CreateItem(characterID, userID string, req *CreateItemRequest) (*Item, error) {
+	char, err := s.characters.Authorize(characterID, userID, character.AccessOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	item := &Item{
+		ID:                 uuid.New(),
+		CharacterID:        char.ID,
+		Name:               req.Name,
+		Quantity:           1,
+		Weight:             req.Weight,
+		CostCP:             req.CostCP,
+		Equipped:           req.Equipped,
+		Attuned:            req.Attuned,
+		IsContainer:        req.IsContainer,
+		WeightlessContents: req.WeightlessContents && req.IsContainer,
+		Notes:              req.Notes,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if req.Quantity != nil {
+		item.Quantity = *req.Quantity
+	}
+	if req.ContainerID != nil {
+		id := uuid.MustParse(*req.ContainerID)
+		item.ContainerID = &id
+	}
+
+	if err := s.store.Create(item, checkItem(item)); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// UpdateItem changes an item of a character the user owns
+func (s *Service) UpdateItem(characterID, userID, itemID string, req *UpdateItemRequest) (*Item, error) {
+	if _, err := s.characters.Authorize(characterID, userID, character.AccessOwner); err != nil {
+		return nil, err
+	}
+	if !isValidID(itemID) {
+		return nil, ErrItemNotFound
+	}
+
+	item, err := s.store.Get(characterID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		item.Name = *req.Name
+	}
+	if req.Quantity != nil {
+		item.Quantity = *req.Quantity
+	}
+	if req.Weight != nil {
+		item.Weight = *req.Weight
+	}
+	if req.CostCP != nil {
+		item.CostCP = *req.CostCP
+	}
+	if req.Equipped != nil {
+		item.Equipped = *req.Equipped
+	}
+	if req.Attuned != nil {
+		item.Attuned = *req.Attuned
+	}
+	if req.IsContainer != nil {
+		item.IsContainer = *req.IsContainer
+	}
+	if req.WeightlessContents != nil {
+		item.WeightlessContents = *req.WeightlessContents
+	}
+	item.WeightlessContents = item.WeightlessContents && item.IsContainer
+	if req.Notes != nil {
+		item.Notes = req.Notes
+	}
+	if req.ContainerID != nil {
+		if *req.ContainerID == "" {
+			item.ContainerID = nil
+		} else {
+			id, err := uuid.Parse(*req.ContainerID)
+			if err != nil {
+				return nil, ErrInvalidContainer
+			}
+			item.ContainerID = &id
+		}
+	}
+	item.UpdatedAt = time.Now()
+
+	if err := s.store.Update(item, checkItem(item)); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// DeleteItem removes an item from a character the user owns. Anything
+// stored in it moves to the container that held it.
+func (s *Service) DeleteItem(characterID, userID, itemID string) error {
+	if _, err := s.characters.Authorize(characterID, userID, character.AccessOwner); err != nil {
+		return err
+	}
+	if !isValidID(itemID) {
+		return ErrItemNotFound
+	}
+
+	return s.store.Delete(characterID, itemID)
+}
+
+// checkItem returns a Check accepting item if it fits among the character's
+// other items: attunement stays within MaxAttuned and containers nest
+// without cycles
+func checkItem(item *Item) Check {
+	return func(items []*Item) error {
+		byID := make(map[uuid.UUID]*Item, len(items)+1)
+		attuned := 0
+		for _, other := range items {
+			if other.ID == item.ID {
+				continue
+			}
+			byID[other.ID] = other
+			if other.Attuned {
+				attuned++
+			}
+			if !item.IsContainer && other.ContainerID != nil && *other.ContainerID == item.ID {
+				return ErrContainerNotEmpty
+			}
+		}
+		byID[item.ID] = item
+
+		if item.Attuned && attuned >= MaxAttuned {
+			return ErrAttunementLimit
+		}
+
+		if item.ContainerID == nil {
+			return nil
+		}
+		container, ok := byID[*item.ContainerID]
+		if !ok || !container.IsContainer {
+			return ErrInvalidContainer
+		}
+		for depth := 0; container != nil && depth <= len(byID); depth++ {
+			if container.ID == item.ID {
+				return ErrContainerCycle
+			}
+			if container.ContainerID == nil {
+				break
+			}
+			container = byID[*container.ContainerID]
+		}
+		return nil
+	}
+}
+
+// isValidID reports whether id is a well-formed UUID, so malformed IDs are
+// treated as missing rather than reaching the database
+func isValidID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
@@ -0,0 +1,56 @@
+package inventory
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckItem(t *testing.T) {
+	backpack := newItem(1, 5, nil)
+	backpack.IsContainer = true
+	pouch := newItem(1, 1, backpack)
+	pouch.IsContainer = true
+	rope := newItem(1, 10, nil)
+	items := []*Item{backpack, pouch, rope}
+
+	attuned := make([]*Item, MaxAttuned)
+	for i := range attuned {
+		attuned[i] = newItem(1, 0, nil)
+		attuned[i].Attuned = true
+	}
+
+	// with returns a copy of an item after a change
+	with := func(item *Item, change func(item *Item)) *Item {
+		cp := item.clone()
+		change(cp)
+		return cp
+	}
+
+	tests := []struct {
+		name  string
+		items []*Item
+		item  *Item
+		want  error
+	}{
+		{"carried directly", items, newItem(1, 1, nil), nil},
+		{"in a container", items, newItem(1, 1, backpack), nil},
+		{"in a nested container", items, newItem(1, 1, pouch), nil},
+		{"in a missing container", items, newItem(1, 1, newItem(1, 1, nil)), ErrInvalidContainer},
+		{"in something that isn't a container", items, newItem(1, 1, rope), ErrInvalidContainer},
+		{"a container in itself", items, with(backpack, func(item *Item) { item.ContainerID = &backpack.ID }), ErrContainerCycle},
+		{"a container in its own contents", items, with(backpack, func(item *Item) { item.ContainerID = &pouch.ID }), ErrContainerCycle},
+		{"moving out of a container", items, with(pouch, func(item *Item) { item.ContainerID = nil }), nil},
+		{"an empty container no longer a container", items, with(pouch, func(item *Item) { item.IsContainer = false }), nil},
+		{"a full container no longer a container", items, with(backpack, func(item *Item) { item.IsContainer = false }), ErrContainerNotEmpty},
+		{"attuning below the limit", attuned[1:], with(rope, func(item *Item) { item.Attuned = true }), nil},
+		{"attuning past the limit", attuned, with(rope, func(item *Item) { item.Attuned = true }), ErrAttunementLimit},
+		{"saving an attuned item at the limit", attuned, with(attuned[0], func(item *Item) { item.Name = "Ring" }), nil},
+		{"not attuned at the limit", attuned, newItem(1, 1, nil), nil},
+	}
+
+	for _, tt := range tests {
+		if err := checkItem(tt.item)(tt.items); !errors.Is(err, tt.want) {
+			t.Errorf("%s: check returned %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
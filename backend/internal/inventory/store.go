@@ -0,0 +1,31 @@
+package inventory
+
+import "character-sheet-backend/internal/apperror"
+
+var (
+	// ErrItemNotFound is returned when an item doesn't exist on the character
+	ErrItemNotFound = apperror.NotFound("item_not_found", "Item not found")
+	// ErrAttunementLimit is returned when attuning to more than MaxAttuned
+	// items
+	ErrAttunementLimit = apperror.Conflict("attunement_limit", "A character can be attuned to at most 3 items")
+)
+
+// Check inspects a character's current items before a write is applied and
+// returns an error to reject it
+type Check func(items []*Item) error
+
+// Store persists character items. Writes to one character's items are
+// serialized, so a Check sees the items exactly as the write will find them.
+type Store interface {
+	// List returns a character's items in the order they were added
+	List(characterID string) ([]*Item, error)
+	// Get returns a single item of a character
+	Get(characterID, itemID string) (*Item, error)
+	// Create stores a new item if check accepts the character's items
+	Create(item *Item, check Check) error
+	// Update saves an existing item if check accepts the character's items
+	Update(item *Item, check Check) error
+	// Delete removes an item, moving anything stored in it into the
+	// container that held it
+	Delete(characterID, itemID string) error
+}
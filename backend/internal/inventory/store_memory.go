@@ -0,0 +1,112 @@
+package inventory
+
+import "sync"
+
+// MemoryStore is an inventory Store kept in process memory, for tests and
+// demos that run without a database
+type MemoryStore struct {
+	mu    sync.Mutex
+	items map[string][]*Item
+}
+
+// NewMemoryStore creates an empty in-memory inventory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string][]*Item)}
+}
+
+// List returns a character's items in the order they were added
+func (s *MemoryStore) List(characterID string) ([]*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return cloneItems(s.items[characterID]), nil
+}
+
+// Get returns a single item of a character
+func (s *MemoryStore) Get(characterID, itemID string) (*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range s.items[characterID] {
+		if item.ID.String() == itemID {
+			return item.clone(), nil
+		}
+	}
+	return nil, ErrItemNotFound
+}
+
+// Create stores a new item if check accepts the character's items
+func (s *MemoryStore) Create(item *Item, check Check) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := item.CharacterID.String()
+	if err := check(cloneItems(s.items[id])); err != nil {
+		return err
+	}
+
+	s.items[id] = append(s.items[id], item.clone())
+	return nil
+}
+
+// Update saves an existing item if check accepts the character's items
+func (s *MemoryStore) Update(item *Item, check Check) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := s.items[item.CharacterID.String()]
+	for i, existing := range items {
+		if existing.ID == item.ID {
+			if err := check(cloneItems(items)); err != nil {
+				return err
+			}
+			items[i] = item.clone()
+			return nil
+		}
+	}
+	return ErrItemNotFound
+}
+
+// Delete removes an item, moving its contents into its own container
+func (s *MemoryStore) Delete(characterID, itemID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := s.items[characterID]
+	for i, item := range items {
+		if item.ID.String() != itemID {
+			continue
+		}
+
+		for _, other := range items {
+			if other.ContainerID != nil && *other.ContainerID == item.ID {
+				other.ContainerID = item.ContainerID
+			}
+		}
+		s.items[characterID] = append(items[:i:i], items[i+1:]...)
+		return nil
+	}
+	return ErrItemNotFound
+}
+
+// clone returns a copy of an item that can be handed out safely
+func (i *Item) clone() *Item {
+	cp := *i
+	if i.ContainerID != nil {
+		id := *i.ContainerID
+		cp.ContainerID = &id
+	}
+	if i.Notes != nil {
+		notes := *i.Notes
+		cp.Notes = &notes
+	}
+	return &cp
+}
+
+func cloneItems(items []*Item) []*Item {
+	cp := make([]*Item, 0, len(items))
+	for _, item := range items {
+		cp = append(cp, item.clone())
+	}
+	return cp
+}
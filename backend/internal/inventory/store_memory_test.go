@@ -0,0 +1,85 @@
+package inventory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryStoreChecks(t *testing.T) {
+	s := NewMemoryStore()
+	characterID := uuid.New()
+	id := characterID.String()
+	rejected := errors.New("rejected")
+	accept := func([]*Item) error { return nil }
+
+	backpack := newItem(1, 5, nil)
+	backpack.CharacterID = characterID
+	backpack.IsContainer = true
+	if err := s.Create(backpack, accept); err != nil {
+		t.Fatal(err)
+	}
+
+	// Checks see the character's items before the write, and a rejected
+	// write stores nothing
+	rope := newItem(1, 10, backpack)
+	rope.CharacterID = characterID
+	var seen []*Item
+	err := s.Create(rope, func(items []*Item) error {
+		seen = items
+		return rejected
+	})
+	if !errors.Is(err, rejected) {
+		t.Fatalf("Create returned %v, want the check's error", err)
+	}
+	if len(seen) != 1 || seen[0].ID != backpack.ID {
+		t.Errorf("Create checked %d items, want the backpack", len(seen))
+	}
+	if items, _ := s.List(id); len(items) != 1 {
+		t.Errorf("a rejected create stored %d items, want 1", len(items))
+	}
+	if err := s.Create(rope, checkItem(rope)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Checks get copies they can't change the stored items through
+	update := backpack.clone()
+	update.Name = "Backpack"
+	err = s.Update(update, func(items []*Item) error {
+		for _, item := range items {
+			item.Weight = 100
+		}
+		return rejected
+	})
+	if !errors.Is(err, rejected) {
+		t.Fatalf("Update returned %v, want the check's error", err)
+	}
+	stored, err := s.Get(id, backpack.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Name != backpack.Name || stored.Weight != 5 {
+		t.Errorf("a rejected update stored %q weighing %v", stored.Name, stored.Weight)
+	}
+
+	// The service's check runs against the stored items too
+	update.IsContainer = false
+	if err := s.Update(update, checkItem(update)); !errors.Is(err, ErrContainerNotEmpty) {
+		t.Errorf("unmarking a full container returned %v, want ErrContainerNotEmpty", err)
+	}
+	missing := newItem(1, 1, nil)
+	missing.CharacterID = characterID
+	if err := s.Update(missing, accept); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("updating a missing item returned %v, want ErrItemNotFound", err)
+	}
+
+	// Deleting a container moves its contents to the container that held it
+	if err := s.Delete(id, backpack.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+	items, _ := s.List(id)
+	if len(items) != 1 || items[0].ID != rope.ID || items[0].ContainerID != nil {
+		t.Errorf("after deleting the backpack, items = %+v, want the rope carried directly", items)
+	}
+}
@@ -0,0 +1,199 @@
+package inventory
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// itemColumns is the column list shared by every item SELECT
+const itemColumns = `
+	id, character_id, container_id, name, quantity, weight, cost_cp,
+	equipped, attuned, is_container, weightless_contents, notes,
+	created_at, updated_at`
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// PostgresStore is an inventory Store backed by PostgreSQL
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates an inventory store using the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func scanItem(row rowScanner) (*Item, error) {
+	item := &Item{}
+	err := row.Scan(
+		&item.ID, &item.CharacterID, &item.ContainerID, &item.Name, &item.Quantity,
+		&item.Weight, &item.CostCP, &item.Equipped, &item.Attuned, &item.IsContainer,
+		&item.WeightlessContents, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+	)
+	return item, err
+}
+
+// List returns a character's items in the order they were added
+func (s *PostgresStore) List(characterID string) ([]*Item, error) {
+	return listItems(s.db, characterID)
+}
+
+func listItems(q queryer, characterID string) ([]*Item, error) {
+	query := `SELECT ` + itemColumns + `
+		FROM items
+		WHERE character_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := q.Query(query, characterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*Item{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// Get returns a single item of a character
+func (s *PostgresStore) Get(characterID, itemID string) (*Item, error) {
+	query := `SELECT ` + itemColumns + `
+		FROM items
+		WHERE character_id = $1 AND id = $2
+	`
+
+	item, err := scanItem(s.db.QueryRow(query, characterID, itemID))
+	if err == sql.ErrNoRows {
+		return nil, ErrItemNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+
+	return item, nil
+}
+
+// Create stores a new item if check accepts the character's items
+func (s *PostgresStore) Create(item *Item, check Check) error {
+	return s.write(item.CharacterID.String(), check, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO items (`+itemColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+			item.ID, item.CharacterID, item.ContainerID, item.Name, item.Quantity,
+			item.Weight, item.CostCP, item.Equipped, item.Attuned, item.IsContainer,
+			item.WeightlessContents, item.Notes, item.CreatedAt, item.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create item: %w", err)
+		}
+		return nil
+	})
+}
+
+// Update saves an existing item if check accepts the character's items
+func (s *PostgresStore) Update(item *Item, check Check) error {
+	return s.write(item.CharacterID.String(), check, func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			UPDATE items SET
+				container_id = $3, name = $4, quantity = $5, weight = $6, cost_cp = $7,
+				equipped = $8, attuned = $9, is_container = $10, weightless_contents = $11,
+				notes = $12, updated_at = $13
+			WHERE character_id = $1 AND id = $2`,
+			item.CharacterID, item.ID, item.ContainerID, item.Name, item.Quantity,
+			item.Weight, item.CostCP, item.Equipped, item.Attuned, item.IsContainer,
+			item.WeightlessContents, item.Notes, item.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update item: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if n == 0 {
+			return ErrItemNotFound
+		}
+		return nil
+	})
+}
+
+// Delete removes an item, moving its contents into its own container
+func (s *PostgresStore) Delete(characterID, itemID string) error {
+	return s.write(characterID, nil, func(tx *sql.Tx) error {
+		var containerID *string
+		err := tx.QueryRow(
+			"SELECT container_id FROM items WHERE character_id = $1 AND id = $2",
+			characterID, itemID,
+		).Scan(&containerID)
+		if err == sql.ErrNoRows {
+			return ErrItemNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get item: %w", err)
+		}
+
+		_, err = tx.Exec(
+			"UPDATE items SET container_id = $3 WHERE character_id = $1 AND container_id = $2",
+			characterID, itemID, containerID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to move container contents: %w", err)
+		}
+
+		if _, err := tx.Exec("DELETE FROM items WHERE id = $1", itemID); err != nil {
+			return fmt.Errorf("failed to delete item: %w", err)
+		}
+		return nil
+	})
+}
+
+// write runs apply in a transaction holding a lock on the character row, so
+// concurrent writes to the same inventory take turns and check sees the
+// items as they are when apply runs
+func (s *PostgresStore) write(characterID string, check Check, apply func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT 1 FROM characters WHERE id = $1 FOR UPDATE", characterID); err != nil {
+		return fmt.Errorf("failed to lock character: %w", err)
+	}
+
+	if check != nil {
+		items, err := listItems(tx, characterID)
+		if err != nil {
+			return err
+		}
+		if err := check(items); err != nil {
+			return err
+		}
+	}
+
+	if err := apply(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit items: %w", err)
+	}
+
+	return nil
+}
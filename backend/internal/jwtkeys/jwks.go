@@ -0,0 +1,94 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"sort"
+)
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the keyring's asymmetric public keys so other services can
+// verify its tokens. HMAC secrets are never published.
+func (kr *Keyring) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range kr.keys {
+		switch pub := key.verifyingKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, rsaJWK(pub, key.ID))
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, ed25519JWK(pub, key.ID))
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
+
+func rsaJWK(pub *rsa.PublicKey, kid string) JWK {
+	return JWK{
+		KeyType:   "RSA",
+		KeyID:     kid,
+		Use:       "sig",
+		Algorithm: RS256,
+		N:         b64(pub.N.Bytes()),
+		E:         b64(big.NewInt(int64(pub.E)).Bytes()),
+	}
+}
+
+func ed25519JWK(pub ed25519.PublicKey, kid string) JWK {
+	return JWK{
+		KeyType:   "OKP",
+		KeyID:     kid,
+		Use:       "sig",
+		Algorithm: EdDSA,
+		Curve:     "Ed25519",
+		X:         b64(pub),
+	}
+}
+
+// rsaThumbprint is the RFC 7638 thumbprint of an RSA public key
+func rsaThumbprint(pub *rsa.PublicKey) string {
+	jwk := rsaJWK(pub, "")
+	// Members in lexicographic order, as the RFC requires
+	return thumbprint(map[string]string{"e": jwk.E, "kty": "RSA", "n": jwk.N})
+}
+
+// ed25519Thumbprint is the RFC 8037 thumbprint of an Ed25519 public key
+func ed25519Thumbprint(pub ed25519.PublicKey) string {
+	return thumbprint(map[string]string{"crv": "Ed25519", "kty": "OKP", "x": b64(pub)})
+}
+
+func thumbprint(members map[string]string) string {
+	// encoding/json sorts map keys, giving the canonical form
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return b64(sum[:])
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,165 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// Key is a single signing or verification key identified by its kid
+type Key struct {
+	ID        string
+	Algorithm string
+	// signingKey is nil for keys that may only verify
+	signingKey   interface{}
+	verifyingKey interface{}
+}
+
+// Keyring signs tokens with its current key and verifies tokens signed by
+// any of its keys, so old keys can stay trusted while tokens signed with
+// them expire
+type Keyring struct {
+	current  *Key
+	keys     map[string]*Key
+	issuer   string
+	audience string
+}
+
+// NewKeyring creates a keyring signing with current. Extra keys are only
+// used to verify tokens.
+func NewKeyring(issuer, audience string, current *Key, verifyOnly ...*Key) (*Keyring, error) {
+	if current == nil || current.signingKey == nil {
+		return nil, errors.New("keyring needs a key that can sign")
+	}
+
+	kr := &Keyring{
+		current:  current,
+		keys:     map[string]*Key{current.ID: current},
+		issuer:   issuer,
+		audience: audience,
+	}
+	for _, key := range verifyOnly {
+		if _, exists := kr.keys[key.ID]; exists {
+			continue
+		}
+		kr.keys[key.ID] = &Key{ID: key.ID, Algorithm: key.Algorithm, verifyingKey: key.verifyingKey}
+	}
+
+	return kr, nil
+}
+
+// HMACKey creates an HS256 key. Its kid is derived from the secret, so a
+// rotated secret automatically gets a new kid.
+func HMACKey(secret string) (*Key, error) {
+	if secret == "" {
+		return nil, errors.New("HMAC secret must not be empty")
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return &Key{
+		ID:           "hs-" + hex.EncodeToString(sum[:8]),
+		Algorithm:    HS256,
+		signingKey:   []byte(secret),
+		verifyingKey: []byte(secret),
+	}, nil
+}
+
+// PrivateKey creates an RS256 or EdDSA signing key from a parsed private key
+func PrivateKey(private crypto.Signer) (*Key, error) {
+	key, err := PublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	key.signingKey = private
+	return key, nil
+}
+
+// PublicKey creates a verification-only RS256 or EdDSA key
+func PublicKey(public crypto.PublicKey) (*Key, error) {
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		return &Key{ID: rsaThumbprint(pub), Algorithm: RS256, verifyingKey: pub}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: ed25519Thumbprint(pub), Algorithm: EdDSA, verifyingKey: pub}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", public)
+}
+
+// Sign issues a token with the given claims, adding the issuer, audience,
+// issued-at and not-before claims and the kid header
+func (kr *Keyring) Sign(claims jwt.MapClaims, now time.Time) (string, error) {
+	claims["iss"] = kr.issuer
+	claims["aud"] = kr.audience
+	claims["iat"] = now.Unix()
+	claims["nbf"] = now.Unix()
+
+	token := jwt.NewWithClaims(signingMethod(kr.current.Algorithm), claims)
+	token.Header["kid"] = kr.current.ID
+	return token.SignedString(kr.current.signingKey)
+}
+
+// Parse verifies a token and returns its claims. The token must name a
+// known kid, use exactly that key's algorithm, and carry a valid issuer,
+// audience, expiry and not-before time.
+func (kr *Keyring) Parse(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, kr.keyFunc,
+		jwt.WithValidMethods([]string{HS256, RS256, EdDSA}),
+		jwt.WithIssuer(kr.issuer),
+		jwt.WithAudience(kr.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := claims["nbf"]; !ok {
+		return nil, errors.New("token has no nbf claim")
+	}
+
+	return claims, nil
+}
+
+// keyFunc picks the verification key named by the token's kid and pins the
+// algorithm to that key's, so a token can't choose how it is verified
+func (kr *Keyring) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("token has no kid header")
+	}
+
+	key, ok := kr.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("kid %q requires %s, token uses %s", kid, key.Algorithm, token.Method.Alg())
+	}
+
+	return key.verifyingKey, nil
+}
+
+func signingMethod(alg string) jwt.SigningMethod {
+	switch alg {
+	case RS256:
+		return jwt.SigningMethodRS256
+	case EdDSA:
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodHS256
+}
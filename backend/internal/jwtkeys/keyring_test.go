@@ -0,0 +1,242 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "character-sheet-test"
+	testAudience = "character-sheet-test"
+)
+
+func rsaKey(t *testing.T) (*Key, *rsa.PrivateKey) {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, private
+}
+
+func ed25519Key(t *testing.T) *Key {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newKeyring(t *testing.T, issuer, audience string, current *Key, verifyOnly ...*Key) *Keyring {
+	t.Helper()
+	kr, err := NewKeyring(issuer, audience, current, verifyOnly...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr
+}
+
+// validClaims are claims the test keyrings accept from a token signed now
+func validClaims(now time.Time) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": "user",
+		"iss": testIssuer,
+		"aud": testAudience,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+// forge signs claims the way an attacker could, choosing the algorithm and
+// kid header
+func forge(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestParse(t *testing.T) {
+	now := time.Now()
+	current, private := rsaKey(t)
+	kr := newKeyring(t, testIssuer, testAudience, current)
+
+	// The RSA public key as an attacker would find it published, to use as
+	// an HMAC secret
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	// with returns the valid claims after a change
+	with := func(change func(claims jwt.MapClaims)) jwt.MapClaims {
+		claims := validClaims(now)
+		change(claims)
+		return claims
+	}
+	other := ed25519Key(t)
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"signed by the keyring", forge(t, jwt.SigningMethodRS256, current.ID, private, validClaims(now)), true},
+		{"alg none", forge(t, jwt.SigningMethodNone, current.ID, jwt.UnsafeAllowNoneSignatureType, validClaims(now)), false},
+		{"HS256 with the RSA public key", forge(t, jwt.SigningMethodHS256, current.ID, publicPEM, validClaims(now)), false},
+		{"HS256 with the RSA modulus", forge(t, jwt.SigningMethodHS256, current.ID, private.PublicKey.N.Bytes(), validClaims(now)), false},
+		{"RS384 with the right key", forge(t, jwt.SigningMethodRS384, current.ID, private, validClaims(now)), false},
+		{"no kid", forge(t, jwt.SigningMethodRS256, "", private, validClaims(now)), false},
+		{"unknown kid", forge(t, jwt.SigningMethodRS256, "someone-else", private, validClaims(now)), false},
+		{"another key's kid", forge(t, jwt.SigningMethodEdDSA, other.ID, other.signingKey, validClaims(now)), false},
+		{"another issuer", forge(t, jwt.SigningMethodRS256, current.ID, private, with(func(c jwt.MapClaims) { c["iss"] = "elsewhere" })), false},
+		{"no issuer", forge(t, jwt.SigningMethodRS256, current.ID, private, with(func(c jwt.MapClaims) { delete(c, "iss") })), false},
+		{"another audience", forge(t, jwt.SigningMethodRS256, current.ID, private, with(func(c jwt.MapClaims) { c["aud"] = "elsewhere" })), false},
+		{"one of several audiences", forge(t, jwt.SigningMethodRS256, current.ID, private, with(func(c jwt.MapClaims) { c["aud"] = []string{"elsewhere", testAudience} })), true},
+		{"expired", forge(t, jwt.SigningMethodRS256, current.ID, private, with(func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Minute).Unix() })), false},
+		{"no expiry", forge(t, jwt.SigningMethodRS256, current.ID, private, with(func(c jwt.MapClaims) { delete(c, "exp") })), false},
+		{"not yet valid", forge(t, jwt.SigningMethodRS256, current.ID, private, with(func(c jwt.MapClaims) { c["nbf"] = now.Add(time.Hour).Unix() })), false},
+		{"no not-before", forge(t, jwt.SigningMethodRS256, current.ID, private, with(func(c jwt.MapClaims) { delete(c, "nbf") })), false},
+	}
+
+	for _, tt := range tests {
+		claims, err := kr.Parse(tt.token)
+		if tt.valid && (err != nil || claims["sub"] != "user") {
+			t.Errorf("%s: Parse returned %v, %v", tt.name, claims, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: Parse accepted the token", tt.name)
+		}
+	}
+}
+
+func TestSignParse(t *testing.T) {
+	hmac, err := HMACKey("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaSigner, _ := rsaKey(t)
+	keys := map[string]*Key{HS256: hmac, RS256: rsaSigner, EdDSA: ed25519Key(t)}
+
+	for alg, key := range keys {
+		kr := newKeyring(t, testIssuer, testAudience, key)
+		token, err := kr.Sign(jwt.MapClaims{"sub": "user", "exp": time.Now().Add(time.Hour).Unix()}, time.Now())
+		if err != nil {
+			t.Fatalf("%s: Sign returned %v", alg, err)
+		}
+		claims, err := kr.Parse(token)
+		if err != nil || claims["sub"] != "user" || claims["iss"] != testIssuer {
+			t.Errorf("%s: Parse returned %v, %v", alg, claims, err)
+		}
+
+		// The same key under another issuer or audience rejects it
+		for _, other := range []*Keyring{
+			newKeyring(t, "elsewhere", testAudience, key),
+			newKeyring(t, testIssuer, "elsewhere", key),
+		} {
+			if _, err := other.Parse(token); err == nil {
+				t.Errorf("%s: a keyring for %s/%s accepted the token", alg, other.issuer, other.audience)
+			}
+		}
+	}
+}
+
+func TestRotation(t *testing.T) {
+	now := time.Now()
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{"sub": "user", "exp": now.Add(time.Hour).Unix()}
+	}
+
+	oldKey, _ := rsaKey(t)
+	old := newKeyring(t, testIssuer, testAudience, oldKey)
+	oldToken, err := old.Sign(claims(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// After rotating to a new key the old one still verifies, but only
+	// verifies
+	newKey := ed25519Key(t)
+	rotated := newKeyring(t, testIssuer, testAudience, newKey, oldKey)
+	if _, err := rotated.Parse(oldToken); err != nil {
+		t.Errorf("the rotated keyring rejected a token from the old key: %v", err)
+	}
+	newToken, err := rotated.Sign(claims(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != newKey.ID || parsed.Method.Alg() != EdDSA {
+		t.Errorf("rotated keyring signed with %v (%s), want %s", parsed.Header["kid"], parsed.Method.Alg(), newKey.ID)
+	}
+	if rotated.keys[oldKey.ID].signingKey != nil {
+		t.Error("the rotated keyring kept the old key's private key")
+	}
+	if _, err := old.Parse(newToken); err == nil {
+		t.Error("the old keyring accepted a token from the new key")
+	}
+
+	// Once the old key is dropped its tokens are rejected
+	dropped := newKeyring(t, testIssuer, testAudience, newKey)
+	if _, err := dropped.Parse(oldToken); err == nil {
+		t.Error("a keyring without the old key accepted its token")
+	}
+
+	// A rotated HMAC secret gets a new kid, so both can be trusted at once
+	oldSecret, _ := HMACKey("old secret")
+	newSecret, _ := HMACKey("new secret")
+	if oldSecret.ID == newSecret.ID {
+		t.Fatalf("both secrets have kid %s", oldSecret.ID)
+	}
+	oldToken, err = newKeyring(t, testIssuer, testAudience, oldSecret).Sign(claims(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newKeyring(t, testIssuer, testAudience, newSecret, oldSecret).Parse(oldToken); err != nil {
+		t.Errorf("the rotated HMAC keyring rejected a token from the old secret: %v", err)
+	}
+	if _, err := newKeyring(t, testIssuer, testAudience, newSecret).Parse(oldToken); err == nil {
+		t.Error("a keyring with only the new secret accepted the old secret's token")
+	}
+}
+
+func TestNewKeyringNeedsSigningKey(t *testing.T) {
+	key, _ := rsaKey(t)
+	public, err := PublicKey(key.verifyingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewKeyring(testIssuer, testAudience, public); err == nil {
+		t.Error("NewKeyring accepted a verification-only key")
+	}
+	if _, err := NewKeyring(testIssuer, testAudience, nil); err == nil {
+		t.Error("NewKeyring accepted no key")
+	}
+}
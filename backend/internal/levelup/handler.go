@@ -0,0 +1,76 @@
+package levelup
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// Handler handles level-up HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new level-up handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// LevelUp gains a level for a character
+func (h *Handler) LevelUp(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	result, err := h.service.LevelUp(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UndoLevelUp reverts a character's latest level-up
+func (h *Handler) UndoLevelUp(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	result, err := h.service.UndoLevelUp(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListLevelUps lists a character's level-ups
+func (h *Handler) ListLevelUps(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	levelUps, err := h.service.ListLevelUps(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, levelUps)
+}
@@ -0,0 +1,79 @@
+package levelup
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/character"
+	"character-sheet-backend/internal/content"
+	"character-sheet-backend/internal/spellcasting"
+)
+
+// Revision actions recorded for level-ups
+const (
+	ActionLevelUp     = "level_up"
+	ActionUndoLevelUp = "undo_level_up"
+)
+
+// How the hit points gained on a level-up are worked out
+const (
+	HitPointsRoll    = "roll"
+	HitPointsAverage = "average"
+)
+
+// Request represents the choices made on gaining a level. Class may be a
+// class the character already has or a new one to multiclass into.
+type Request struct {
+	Class string `json:"class" binding:"required,max=100"`
+	// HitPoints is "roll" to roll the class's hit die or "average" to take
+	// its fixed value
+	HitPoints string `json:"hit_points" binding:"required,oneof=roll average"`
+	// AbilityIncreases and Feat are the two ways of taking an Ability Score
+	// Improvement; exactly one is required at the levels granting one
+	AbilityIncreases map[string]int `json:"ability_increases"`
+	Feat             *string        `json:"feat" binding:"omitempty,min=1,max=100"`
+	// Subclass is required at the level the class chooses its subclass
+	Subclass *string `json:"subclass" binding:"omitempty,min=1,max=100"`
+	// Spells are new spells learned through the class, by name or index
+	Spells []string `json:"spells" binding:"max=10,dive,min=1,max=100"`
+}
+
+// LevelUp is the record of one level gained, kept so the level-up can be
+// undone as a whole
+type LevelUp struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	CharacterID uuid.UUID `json:"character_id" db:"character_id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	Class       string    `json:"class" db:"class"`
+	// ClassLevel is the character's level in Class after the level-up and
+	// Level its total level
+	ClassLevel int `json:"class_level" db:"class_level"`
+	Level      int `json:"level" db:"level"`
+	// NewClass is set when the level-up multiclassed into Class
+	NewClass bool   `json:"new_class" db:"new_class"`
+	HitDie   int    `json:"hit_die" db:"hit_die"`
+	HPMethod string `json:"hp_method" db:"hp_method"`
+	HPRoll   *int   `json:"hp_roll" db:"hp_roll"`
+	HPGained int    `json:"hp_gained" db:"hp_gained"`
+	// AbilityIncreases are the points added to each ability score
+	AbilityIncreases map[string]int `json:"ability_increases" db:"ability_increases"`
+	Feat             *string        `json:"feat" db:"feat"`
+	// Subclass is the subclass chosen at this level, if any
+	Subclass *string `json:"subclass" db:"subclass"`
+	// SpellIDs are the spells added to the character
+	SpellIDs []uuid.UUID `json:"spell_ids" db:"spell_ids"`
+	// Features are the class and subclass features gained
+	Features []content.Feature `json:"features" db:"features"`
+	// Revision is the character revision the level-up produced
+	Revision  int       `json:"revision" db:"revision"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Result is a character after a level-up or its undo, along with the
+// level-up and the spells it added
+type Result struct {
+	Character *character.Character  `json:"character"`
+	LevelUp   *LevelUp              `json:"level_up"`
+	Spells    []*spellcasting.Spell `json:"spells"`
+}
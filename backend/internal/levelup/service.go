@@ -0,0 +1,441 @@
+package levelup
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/character"
+	"character-sheet-backend/internal/content"
+	"character-sheet-backend/internal/dice"
+	"character-sheet-backend/internal/homebrew"
+	"character-sheet-backend/internal/spellcasting"
+)
+
+var (
+	// ErrMaxLevel is returned when leveling up a character that is already
+	// at the highest level
+	ErrMaxLevel = apperror.Conflict("max_level", fmt.Sprintf("The character is already level %d", character.MaxLevel))
+	// ErrMaxHPRequired is returned when leveling up a character without
+	// maximum hit points to add to
+	ErrMaxHPRequired = apperror.Conflict("max_hp_required", "Set the character's maximum hit points before leveling up")
+	// ErrCannotLevelUp is returned when leveling up a dying or dead
+	// character
+	ErrCannotLevelUp = apperror.Conflict("cannot_level_up", "A dying or dead character can't level up")
+	// ErrUnknownClass is returned for a class that can't be looked up, so
+	// its progression isn't known
+	ErrUnknownClass = apperror.Field("class", "must be a class in the SRD or the character's homebrew packs")
+	// ErrLevelUpChanged is returned when undoing a level-up whose class
+	// levels were edited by hand since
+	ErrLevelUpChanged = apperror.Conflict("level_up_changed", "The character's classes changed since the level-up, so it can't be undone")
+)
+
+// Service handles leveling characters up and undoing level-ups
+type Service struct {
+	store      Store
+	rules      *homebrew.Rules
+	characters *character.Service
+	roller     *dice.Roller
+}
+
+// NewService creates a new level-up service
+func NewService(store Store, rules *homebrew.Rules, characters *character.Service, roller *dice.Roller) *Service {
+	return &Service{store: store, rules: rules, characters: characters, roller: roller}
+}
+
+// choices are a level-up request's content, looked up before the character
+// is locked
+type choices struct {
+	class    *content.Class
+	subclass *content.Subclass
+	spells   []*content.Spell
+	resolver *homebrew.Resolver
+}
+
+// LevelUp gains a level in a class for a character the user owns, applying
+// hit points, an Ability Score Improvement or feat, a subclass and new
+// spells as the class's progression calls for
+func (s *Service) LevelUp(characterID, userID string, req *Request) (*Result, error) {
+	if _, err := s.characters.Authorize(characterID, userID, character.AccessOwner); err != nil {
+		return nil, err
+	}
+
+	chosen, err := s.lookup(characterID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied *Change
+	char, err := s.store.LevelUp(characterID, func(c *character.Character, known []*spellcasting.Spell) (*Change, error) {
+		before := c.Clone()
+		applied, err = s.apply(c, known, chosen, req, userID)
+		if err != nil {
+			return nil, err
+		}
+		c.UpdatedAt = applied.LevelUp.CreatedAt
+		applied.Revision = character.NewRevision(userID, ActionLevelUp, before, c)
+		return applied, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	char.Derived = character.ComputeDerived(char)
+	return &Result{Character: char, LevelUp: applied.LevelUp, Spells: applied.Spells}, nil
+}
+
+// UndoLevelUp reverts the latest level-up of a character the user owns:
+// its class level, hit points, ability scores, feat, subclass and spells
+func (s *Service) UndoLevelUp(characterID, userID string) (*Result, error) {
+	if _, err := s.characters.Authorize(characterID, userID, character.AccessOwner); err != nil {
+		return nil, err
+	}
+
+	char, last, err := s.store.Undo(characterID, func(c *character.Character, last *LevelUp) (*character.Revision, error) {
+		before := c.Clone()
+		if err := revert(c, last); err != nil {
+			return nil, err
+		}
+		c.UpdatedAt = time.Now()
+		return character.NewRevision(userID, ActionUndoLevelUp, before, c), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	char.Derived = character.ComputeDerived(char)
+	return &Result{Character: char, LevelUp: last, Spells: []*spellcasting.Spell{}}, nil
+}
+
+// ListLevelUps returns the level-ups of a character the user can see,
+// newest first
+func (s *Service) ListLevelUps(characterID, userID string) ([]*LevelUp, error) {
+	if _, err := s.characters.Authorize(characterID, userID, character.AccessRead); err != nil {
+		return nil, err
+	}
+
+	return s.store.List(characterID)
+}
+
+// lookup resolves the class, subclass and spells a request names against
+// the SRD and the character's packs
+func (s *Service) lookup(characterID string, req *Request) (*choices, error) {
+	resolver, err := s.rules.Resolver(characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	chosen := &choices{resolver: resolver}
+	class := resolver.Class(req.Class)
+	if class == nil {
+		return nil, ErrUnknownClass
+	}
+	chosen.class = class.Entry
+
+	if req.Subclass != nil {
+		subclass := resolver.Subclass(*req.Subclass)
+		if subclass == nil {
+			return nil, apperror.Field("subclass", "must be a subclass in the SRD or the character's homebrew packs")
+		}
+		if !isClass(chosen.class, subclass.Entry.Class) {
+			return nil, apperror.Field("subclass", fmt.Sprintf("must be a %s subclass", chosen.class.Name))
+		}
+		chosen.subclass = subclass.Entry
+	}
+
+	fields := map[string]string{}
+	for i, name := range req.Spells {
+		spell := resolver.Spell(name)
+		if spell == nil {
+			fields[fmt.Sprintf("spells[%d]", i)] = "must be a spell in the SRD or the character's homebrew packs"
+			continue
+		}
+		chosen.spells = append(chosen.spells, spell.Entry)
+	}
+	if len(fields) > 0 {
+		return nil, apperror.Validation("invalid_spells", "Some spells couldn't be found", fields)
+	}
+
+	return chosen, nil
+}
+
+// apply gains the level on a locked character and returns what the level-up
+// writes besides the character
+func (s *Service) apply(c *character.Character, known []*spellcasting.Spell, chosen *choices, req *Request, userID string) (*Change, error) {
+	if c.Level >= character.MaxLevel {
+		return nil, ErrMaxLevel
+	}
+	if c.MaxHP == nil {
+		return nil, ErrMaxHPRequired
+	}
+	if status := c.Status(); status == character.StatusDying || status == character.StatusDead {
+		return nil, ErrCannotLevelUp
+	}
+	class := chosen.class
+
+	levelUp := &LevelUp{
+		ID:               uuid.New(),
+		CharacterID:      c.ID,
+		UserID:           uuid.MustParse(userID),
+		Class:            class.Name,
+		HPMethod:         req.HitPoints,
+		AbilityIncreases: map[string]int{},
+		SpellIDs:         []uuid.UUID{},
+		Features:         []content.Feature{},
+		CreatedAt:        time.Now(),
+	}
+
+	i := slices.IndexFunc(c.Classes, func(entry character.ClassEntry) bool {
+		return isClass(class, entry.Class)
+	})
+	if i < 0 {
+		c.Classes = append(c.Classes, character.ClassEntry{Class: class.Name, HitDie: class.HitDie})
+		i = len(c.Classes) - 1
+		levelUp.NewClass = true
+	}
+	c.Classes[i].Levels++
+	if err := s.characters.CheckClasses(c, levelUp.NewClass); err != nil {
+		return nil, err
+	}
+	entry := &c.Classes[i]
+	levelUp.Class = entry.Class
+	levelUp.ClassLevel = entry.Levels
+	levelUp.Level = c.Level
+	levelUp.HitDie = entry.HitDie
+
+	oldCon := character.AbilityModifier(c.Constitution)
+	if err := improveAbilities(c, class, entry.Levels, req, levelUp); err != nil {
+		return nil, err
+	}
+	if err := s.gainHitPoints(c, oldCon, req.HitPoints, levelUp); err != nil {
+		return nil, err
+	}
+
+	if err := chooseSubclass(entry, class, chosen.subclass, levelUp); err != nil {
+		return nil, err
+	}
+	levelUp.Features = features(class, chosen.resolver, entry)
+
+	// Spellcasting reads the derived stats, which have changed with the level
+	c.Derived = character.ComputeDerived(c)
+	spells, err := learnSpells(c, entry, known, chosen)
+	if err != nil {
+		return nil, err
+	}
+	for _, spell := range spells {
+		levelUp.SpellIDs = append(levelUp.SpellIDs, spell.ID)
+	}
+
+	return &Change{LevelUp: levelUp, Spells: spells}, nil
+}
+
+// gainHitPoints rolls or takes the average of the hit die and adds it with
+// the Constitution modifier to the character's hit points, at least 1. A
+// Constitution modifier raised by this level-up also applies to every
+// earlier level. Current hit points stay within the maximum as halved by
+// exhaustion.
+func (s *Service) gainHitPoints(c *character.Character, oldCon int, method string, levelUp *LevelUp) error {
+	die := levelUp.HitDie/2 + 1
+	if method == HitPointsRoll {
+		result, err := s.roller.RollNotation(fmt.Sprintf("1d%d", levelUp.HitDie), dice.Normal)
+		if err != nil {
+			return err
+		}
+		die = result.Total
+		levelUp.HPRoll = &die
+	}
+
+	newCon := character.AbilityModifier(c.Constitution)
+	levelUp.HPGained = max(die+newCon, 1) + (newCon-oldCon)*(c.Level-1)
+
+	maxHP := *c.MaxHP + levelUp.HPGained
+	c.MaxHP = &maxHP
+	if c.CurrentHP != nil {
+		current := min(max(*c.CurrentHP+levelUp.HPGained, 0), c.HPMax())
+		c.CurrentHP = &current
+		if current > 0 {
+			// A stable character back above 0 hit points no longer needs
+			// death saves
+			c.DeathSaves = character.DeathSaves{}
+		}
+	}
+	return nil
+}
+
+// improveAbilities applies an Ability Score Improvement or feat, which are
+// required at the class levels granting an improvement and not allowed at
+// others
+func improveAbilities(c *character.Character, class *content.Class, classLevel int, req *Request, levelUp *LevelUp) error {
+	increases := len(req.AbilityIncreases) > 0
+	if !slices.Contains(class.ASILevels, classLevel) {
+		if increases || req.Feat != nil {
+			return apperror.Field("ability_increases", fmt.Sprintf("%s level %d doesn't grant an Ability Score Improvement", class.Name, classLevel))
+		}
+		return nil
+	}
+	if increases == (req.Feat != nil) {
+		return apperror.Field("ability_increases", fmt.Sprintf("exactly one of ability_increases or feat is required at %s level %d", class.Name, classLevel))
+	}
+
+	if req.Feat != nil {
+		feat := strings.TrimSpace(*req.Feat)
+		if slices.ContainsFunc(c.Feats, func(taken string) bool { return strings.EqualFold(taken, feat) }) {
+			return apperror.Field("feat", "has already been taken")
+		}
+		c.Feats = append(c.Feats, feat)
+		levelUp.Feat = &feat
+		return nil
+	}
+
+	fields := map[string]string{}
+	total := 0
+	for ability, n := range req.AbilityIncreases {
+		path := "ability_increases." + ability
+		switch {
+		case !slices.Contains(character.Abilities, ability):
+			fields[path] = "must be an ability score"
+		case n < 1 || n > 2:
+			fields[path] = "must be 1 or 2"
+		case c.Score(ability)+n > 20:
+			fields[path] = "would raise the score above 20"
+		}
+		total += n
+	}
+	if total != 2 && len(fields) == 0 {
+		fields["ability_increases"] = "must add up to 2"
+	}
+	if len(fields) > 0 {
+		return apperror.Validation("invalid_ability_increases", "The ability score increases are invalid", fields)
+	}
+
+	for ability, n := range req.AbilityIncreases {
+		c.SetScore(ability, c.Score(ability)+n)
+		levelUp.AbilityIncreases[ability] = n
+	}
+	return nil
+}
+
+// chooseSubclass sets the subclass chosen at the class's subclass level,
+// where one is required unless the class entry already has one
+func chooseSubclass(entry *character.ClassEntry, class *content.Class, subclass *content.Subclass, levelUp *LevelUp) error {
+	due := entry.Levels == class.SubclassLevel && entry.Subclass == ""
+	switch {
+	case due && subclass == nil:
+		return apperror.Field("subclass", fmt.Sprintf("is required at %s level %d", class.Name, entry.Levels))
+	case !due && subclass != nil:
+		return apperror.Field("subclass", fmt.Sprintf("can only be chosen at %s level %d", class.Name, class.SubclassLevel))
+	case subclass != nil:
+		entry.Subclass = subclass.Name
+		levelUp.Subclass = &subclass.Name
+	}
+	return nil
+}
+
+// features lists the class and subclass features gained at a class entry's
+// new level
+func features(class *content.Class, resolver *homebrew.Resolver, entry *character.ClassEntry) []content.Feature {
+	gained := []content.Feature{}
+	for _, feature := range class.Features {
+		if feature.Level == entry.Levels {
+			gained = append(gained, feature)
+		}
+	}
+	if entry.Subclass == "" {
+		return gained
+	}
+	if subclass := resolver.Subclass(entry.Subclass); subclass != nil {
+		for _, feature := range subclass.Entry.Features {
+			if feature.Level == entry.Levels {
+				gained = append(gained, feature)
+			}
+		}
+	}
+	return gained
+}
+
+// learnSpells builds the spells learned through a class entry, which must
+// be on the class's spell list, of a level it can cast and not already known
+func learnSpells(c *character.Character, entry *character.ClassEntry, known []*spellcasting.Spell, chosen *choices) ([]*spellcasting.Spell, error) {
+	highest := spellcasting.HighestSpellLevel(spellcasting.ClassLevel{
+		Class:    entry.Class,
+		Subclass: entry.Subclass,
+		Level:    entry.Levels,
+	})
+
+	fields := map[string]string{}
+	spells := []*spellcasting.Spell{}
+	for i, spell := range chosen.spells {
+		path := fmt.Sprintf("spells[%d]", i)
+		isKnown := func(other *spellcasting.Spell) bool {
+			return strings.EqualFold(other.Class, entry.Class) && strings.EqualFold(other.Name, spell.Name)
+		}
+		switch {
+		case !slices.ContainsFunc(spell.Classes, func(class string) bool { return isClass(chosen.class, class) }):
+			fields[path] = fmt.Sprintf("isn't on the %s spell list", chosen.class.Name)
+		case spell.Level > highest:
+			fields[path] = fmt.Sprintf("is above the highest spell level %s %d can learn", entry.Class, entry.Levels)
+		case slices.ContainsFunc(known, isKnown) || slices.ContainsFunc(spells, isKnown):
+			fields[path] = "is already known"
+		default:
+			learned, _, err := spellcasting.NewSpell(c, entry.Class, spell.Name, spell.Level)
+			if err != nil {
+				return nil, err
+			}
+			spells = append(spells, learned)
+		}
+	}
+	if len(fields) > 0 {
+		return nil, apperror.Validation("invalid_spells", "Some spells can't be learned at this level", fields)
+	}
+	return spells, nil
+}
+
+// revert undoes a level-up on a locked character
+func revert(c *character.Character, last *LevelUp) error {
+	i := slices.IndexFunc(c.Classes, func(entry character.ClassEntry) bool {
+		return strings.EqualFold(entry.Class, last.Class)
+	})
+	if i < 0 || c.Classes[i].Levels != last.ClassLevel {
+		return ErrLevelUpChanged
+	}
+
+	if last.NewClass {
+		c.Classes = slices.Delete(c.Classes, i, i+1)
+	} else {
+		c.Classes[i].Levels--
+		c.Classes[i].HitDiceSpent = min(c.Classes[i].HitDiceSpent, c.Classes[i].Levels)
+		if last.Subclass != nil {
+			c.Classes[i].Subclass = ""
+		}
+	}
+	c.Level = character.TotalLevel(c.Classes)
+
+	for ability, n := range last.AbilityIncreases {
+		c.SetScore(ability, c.Score(ability)-n)
+	}
+	if last.Feat != nil {
+		if j := slices.IndexFunc(c.Feats, func(feat string) bool { return strings.EqualFold(feat, *last.Feat) }); j >= 0 {
+			c.Feats = slices.Delete(c.Feats, j, j+1)
+		}
+	}
+
+	if c.MaxHP != nil {
+		maxHP := max(*c.MaxHP-last.HPGained, 1)
+		c.MaxHP = &maxHP
+		if c.CurrentHP != nil && *c.CurrentHP > c.HPMax() {
+			current := c.HPMax()
+			c.CurrentHP = &current
+		}
+	}
+	return nil
+}
+
+// isClass reports whether name is a class's index or name
+func isClass(class *content.Class, name string) bool {
+	name = strings.TrimSpace(name)
+	return strings.EqualFold(name, class.Index) || strings.EqualFold(name, class.Name)
+}
@@ -0,0 +1,146 @@
+package levelup
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/character"
+	"character-sheet-backend/internal/content"
+	"character-sheet-backend/internal/dice"
+	"character-sheet-backend/internal/homebrew"
+	"character-sheet-backend/internal/spellcasting"
+)
+
+// newTestServices wires the level-up service over in-memory stores
+func newTestServices(t *testing.T) (*Service, *character.Service) {
+	t.Helper()
+	catalog, err := content.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	characters := character.NewMemoryStore()
+	rules := homebrew.NewRules(homebrew.NewMemoryStore(), catalog)
+	roller := dice.NewSeededRoller(1)
+	characterService := character.NewService(characters, nil, roller, rules)
+	store := NewMemoryStore(characters, spellcasting.NewMemoryStore())
+	return NewService(store, rules, characterService, roller), characterService
+}
+
+// newFighter creates a level 1 fighter with 12 hit points and a +2
+// Constitution modifier
+func newFighter(t *testing.T, characters *character.Service, userID string) *character.Character {
+	t.Helper()
+	maxHP := 12
+	c, err := characters.CreateCharacter(userID, &character.CreateCharacterRequest{
+		Name: "Tordek", Race: "Dwarf", Class: "Fighter", Level: 1, Background: "Soldier",
+		Strength: 16, Dexterity: 12, Constitution: 14, Intelligence: 10, Wisdom: 13, Charisma: 8,
+		MaxHP: &maxHP, CurrentHP: &maxHP,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func damage(t *testing.T, characters *character.Service, c *character.Character, userID string, amount int) {
+	t.Helper()
+	_, err := characters.ChangeHitPoints(c.ID.String(), userID, &character.HPRequest{
+		Operations: []character.HPOperation{{Type: character.HPDamage, Amount: amount}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLevelUpGainsHitPoints(t *testing.T) {
+	levelUps, characters := newTestServices(t)
+	userID := uuid.NewString()
+	c := newFighter(t, characters, userID)
+	damage(t, characters, c, userID, 5)
+
+	result, err := levelUps.LevelUp(c.ID.String(), userID, &Request{Class: "Fighter", HitPoints: HitPointsAverage})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The average of a d10 is 6, plus 2 for Constitution
+	if got := result.LevelUp.HPGained; got != 8 {
+		t.Errorf("gained %d hit points, want 8", got)
+	}
+	if *result.Character.MaxHP != 20 || *result.Character.CurrentHP != 15 {
+		t.Errorf("hit points = %d/%d, want 15/20", *result.Character.CurrentHP, *result.Character.MaxHP)
+	}
+}
+
+func TestLevelUpWhileExhausted(t *testing.T) {
+	levelUps, characters := newTestServices(t)
+	userID := uuid.NewString()
+	c := newFighter(t, characters, userID)
+	level := 4
+	if _, err := characters.SetExhaustion(c.ID.String(), userID, &character.ExhaustionRequest{Level: &level}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := levelUps.LevelUp(c.ID.String(), userID, &Request{Class: "Fighter", HitPoints: HitPointsAverage})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Exhaustion halves the new maximum of 20, so 6 + 8 stops at 10
+	if *result.Character.MaxHP != 20 || *result.Character.CurrentHP != 10 {
+		t.Errorf("hit points = %d/%d, want 10/20", *result.Character.CurrentHP, *result.Character.MaxHP)
+	}
+
+	// Undoing it keeps current hit points within the halved maximum too
+	if _, err := characters.SetExhaustion(c.ID.String(), userID, &character.ExhaustionRequest{Level: new(int)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := characters.ChangeHitPoints(c.ID.String(), userID, &character.HPRequest{
+		Operations: []character.HPOperation{{Type: character.HPHeal, Amount: 20}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := characters.SetExhaustion(c.ID.String(), userID, &character.ExhaustionRequest{Level: &level}); err != nil {
+		t.Fatal(err)
+	}
+	undone, err := levelUps.UndoLevelUp(c.ID.String(), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *undone.Character.MaxHP != 12 || *undone.Character.CurrentHP != 6 {
+		t.Errorf("hit points after undo = %d/%d, want 6/12", *undone.Character.CurrentHP, *undone.Character.MaxHP)
+	}
+}
+
+func TestLevelUpAtZeroHitPoints(t *testing.T) {
+	levelUps, characters := newTestServices(t)
+	userID := uuid.NewString()
+	req := &Request{Class: "Fighter", HitPoints: HitPointsAverage}
+
+	dying := newFighter(t, characters, userID)
+	damage(t, characters, dying, userID, 12)
+	if _, err := levelUps.LevelUp(dying.ID.String(), userID, req); !errors.Is(err, ErrCannotLevelUp) {
+		t.Errorf("leveling up a dying character returned %v, want ErrCannotLevelUp", err)
+	}
+
+	dead := newFighter(t, characters, userID)
+	exhausted := character.MaxExhaustion
+	if _, err := characters.SetExhaustion(dead.ID.String(), userID, &character.ExhaustionRequest{Level: &exhausted}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := levelUps.LevelUp(dead.ID.String(), userID, req); !errors.Is(err, ErrCannotLevelUp) {
+		t.Errorf("leveling up a dead character returned %v, want ErrCannotLevelUp", err)
+	}
+
+	// A stable character can level up, which brings them back above 0
+	if _, err := characters.Stabilize(dying.ID.String(), userID); err != nil {
+		t.Fatal(err)
+	}
+	result, err := levelUps.LevelUp(dying.ID.String(), userID, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *result.Character.CurrentHP != 8 || result.Character.DeathSaves != (character.DeathSaves{}) {
+		t.Errorf("stable character leveled up to %d hit points with death saves %+v", *result.Character.CurrentHP, result.Character.DeathSaves)
+	}
+}
@@ -0,0 +1,35 @@
+package levelup
+
+import (
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/character"
+	"character-sheet-backend/internal/spellcasting"
+)
+
+// ErrNothingToUndo is returned when undoing a level-up of a character that
+// has none recorded
+var ErrNothingToUndo = apperror.NotFound("level_up_not_found", "The character has no level-up to undo")
+
+// Change is what a level-up writes: the character's revision, the record of
+// the level-up and the spells it adds
+type Change struct {
+	Revision *character.Revision
+	LevelUp  *LevelUp
+	Spells   []*spellcasting.Spell
+}
+
+// Store persists level-ups together with the character and spell changes
+// they make, so each one is written and undone as a unit
+type Store interface {
+	// LevelUp lets change modify a character, given its current spells,
+	// then saves the character, the level-up and its spells in one write.
+	// The character is locked throughout, and the level-up's Revision is
+	// set to the revision the write produced.
+	LevelUp(characterID string, change func(c *character.Character, spells []*spellcasting.Spell) (*Change, error)) (*character.Character, error)
+	// Undo lets change revert a character's latest level-up, then saves the
+	// character and removes the level-up and the spells it added in one
+	// write. It fails with ErrNothingToUndo when there is no level-up.
+	Undo(characterID string, change func(c *character.Character, last *LevelUp) (*character.Revision, error)) (*character.Character, *LevelUp, error)
+	// List returns a character's level-ups, newest first
+	List(characterID string) ([]*LevelUp, error)
+}
@@ -0,0 +1,141 @@
+package levelup
+
+import (
+	"errors"
+	"sync"
+
+	"character-sheet-backend/internal/character"
+	"character-sheet-backend/internal/spellcasting"
+)
+
+// MemoryStore is a level-up Store kept in process memory, for tests and
+// demos that run without a database. It writes through the in-memory
+// character and spell stores; level-ups take turns under its lock.
+type MemoryStore struct {
+	mu         sync.Mutex
+	characters character.CharacterStore
+	spells     spellcasting.Store
+	levelUps   map[string][]*LevelUp
+}
+
+// NewMemoryStore creates an empty in-memory level-up store over the given
+// character and spell stores
+func NewMemoryStore(characters character.CharacterStore, spells spellcasting.Store) *MemoryStore {
+	return &MemoryStore{
+		characters: characters,
+		spells:     spells,
+		levelUps:   make(map[string][]*LevelUp),
+	}
+}
+
+// LevelUp applies change and saves the character, the level-up and its
+// spells
+func (s *MemoryStore) LevelUp(characterID string, change func(c *character.Character, spells []*spellcasting.Spell) (*Change, error)) (*character.Character, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var applied *Change
+	char, err := s.characters.Apply(characterID, func(c *character.Character) (*character.Revision, error) {
+		spells, err := s.spells.List(characterID)
+		if err != nil {
+			return nil, err
+		}
+		applied, err = change(c, spells)
+		if err != nil {
+			return nil, err
+		}
+		return applied.Revision, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, spell := range applied.Spells {
+		if err := s.spells.Create(spell, acceptAll); err != nil {
+			return nil, err
+		}
+	}
+
+	applied.LevelUp.Revision = char.Version
+	s.levelUps[characterID] = append(s.levelUps[characterID], applied.LevelUp.clone())
+	return char, nil
+}
+
+// Undo reverts the latest level-up and removes it with its spells
+func (s *MemoryStore) Undo(characterID string, change func(c *character.Character, last *LevelUp) (*character.Revision, error)) (*character.Character, *LevelUp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	levelUps := s.levelUps[characterID]
+	if len(levelUps) == 0 {
+		return nil, nil, ErrNothingToUndo
+	}
+	last := levelUps[len(levelUps)-1].clone()
+
+	char, err := s.characters.Apply(characterID, func(c *character.Character) (*character.Revision, error) {
+		return change(c, last)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, id := range last.SpellIDs {
+		err := s.spells.Delete(characterID, id.String())
+		if err != nil && !errors.Is(err, spellcasting.ErrSpellNotFound) {
+			return nil, nil, err
+		}
+	}
+
+	s.levelUps[characterID] = levelUps[:len(levelUps)-1]
+	return char, last, nil
+}
+
+// List returns a character's level-ups, newest first
+func (s *MemoryStore) List(characterID string) ([]*LevelUp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	levelUps := s.levelUps[characterID]
+	list := make([]*LevelUp, 0, len(levelUps))
+	for i := len(levelUps) - 1; i >= 0; i-- {
+		list = append(list, levelUps[i].clone())
+	}
+	return list, nil
+}
+
+// acceptAll is a spell Check that accepts any spells; level-ups check the
+// spells they add themselves while the character is locked
+func acceptAll([]*spellcasting.Spell) error {
+	return nil
+}
+
+// clone returns a copy of a level-up that can be handed out safely
+func (l *LevelUp) clone() *LevelUp {
+	cp := *l
+	cp.HPRoll = cloneInt(l.HPRoll)
+	cp.Feat = cloneString(l.Feat)
+	cp.Subclass = cloneString(l.Subclass)
+	cp.AbilityIncreases = make(map[string]int, len(l.AbilityIncreases))
+	for ability, n := range l.AbilityIncreases {
+		cp.AbilityIncreases[ability] = n
+	}
+	cp.SpellIDs = append(cp.SpellIDs[:0:0], l.SpellIDs...)
+	cp.Features = append(cp.Features[:0:0], l.Features...)
+	return &cp
+}
+
+func cloneInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	n := *v
+	return &n
+}
+
+func cloneString(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	s := *v
+	return &s
+}
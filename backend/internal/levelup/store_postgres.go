@@ -0,0 +1,203 @@
+package levelup
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"character-sheet-backend/internal/character"
+	"character-sheet-backend/internal/spellcasting"
+)
+
+// levelUpColumns is the column list shared by every level-up SELECT
+const levelUpColumns = `
+	id, character_id, user_id, class, class_level, level, new_class, hit_die,
+	hp_method, hp_roll, hp_gained, ability_increases, feat, subclass,
+	spell_ids, features, revision, created_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// PostgresStore is a level-up Store backed by PostgreSQL. It writes
+// characters and spells through their stores' transaction helpers so a
+// level-up commits or rolls back as a whole.
+type PostgresStore struct {
+	db         *sql.DB
+	characters *character.PostgresStore
+}
+
+// NewPostgresStore creates a level-up store using the given database and
+// character store
+func NewPostgresStore(db *sql.DB, characters *character.PostgresStore) *PostgresStore {
+	return &PostgresStore{db: db, characters: characters}
+}
+
+func scanLevelUp(row rowScanner) (*LevelUp, error) {
+	l := &LevelUp{}
+	var increases, spellIDs, features []byte
+	err := row.Scan(
+		&l.ID, &l.CharacterID, &l.UserID, &l.Class, &l.ClassLevel, &l.Level, &l.NewClass, &l.HitDie,
+		&l.HPMethod, &l.HPRoll, &l.HPGained, &increases, &l.Feat, &l.Subclass,
+		&spellIDs, &features, &l.Revision, &l.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(increases, &l.AbilityIncreases); err != nil {
+		return nil, fmt.Errorf("failed to decode ability increases: %w", err)
+	}
+	if err := json.Unmarshal(spellIDs, &l.SpellIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode level-up spells: %w", err)
+	}
+	if err := json.Unmarshal(features, &l.Features); err != nil {
+		return nil, fmt.Errorf("failed to decode level-up features: %w", err)
+	}
+	return l, nil
+}
+
+// LevelUp applies change and saves the character, the level-up and its
+// spells in one transaction
+func (s *PostgresStore) LevelUp(characterID string, change func(c *character.Character, spells []*spellcasting.Spell) (*Change, error)) (*character.Character, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var applied *Change
+	char, err := s.characters.ApplyTx(tx, characterID, func(c *character.Character) (*character.Revision, error) {
+		spells, err := spellcasting.ListTx(tx, characterID)
+		if err != nil {
+			return nil, err
+		}
+		applied, err = change(c, spells)
+		if err != nil {
+			return nil, err
+		}
+		return applied.Revision, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, spell := range applied.Spells {
+		if err := spellcasting.InsertTx(tx, spell); err != nil {
+			return nil, err
+		}
+	}
+
+	applied.LevelUp.Revision = char.Version
+	if err := insertLevelUp(tx, applied.LevelUp); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit level-up: %w", err)
+	}
+
+	return char, nil
+}
+
+// Undo reverts the latest level-up and removes it with its spells in one
+// transaction
+func (s *PostgresStore) Undo(characterID string, change func(c *character.Character, last *LevelUp) (*character.Revision, error)) (*character.Character, *LevelUp, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var last *LevelUp
+	char, err := s.characters.ApplyTx(tx, characterID, func(c *character.Character) (*character.Revision, error) {
+		// The character row is locked by now, so no other level-up can
+		// slip in between reading the latest one and removing it
+		query := `SELECT ` + levelUpColumns + `
+			FROM character_level_ups
+			WHERE character_id = $1
+			ORDER BY created_at DESC, level DESC
+			LIMIT 1
+		`
+		var err error
+		last, err = scanLevelUp(tx.QueryRow(query, characterID))
+		if err == sql.ErrNoRows {
+			return nil, ErrNothingToUndo
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get level-up: %w", err)
+		}
+		return change(c, last)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := spellcasting.DeleteTx(tx, characterID, last.SpellIDs); err != nil {
+		return nil, nil, err
+	}
+	if _, err := tx.Exec("DELETE FROM character_level_ups WHERE id = $1", last.ID); err != nil {
+		return nil, nil, fmt.Errorf("failed to delete level-up: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit level-up undo: %w", err)
+	}
+
+	return char, last, nil
+}
+
+// List returns a character's level-ups, newest first
+func (s *PostgresStore) List(characterID string) ([]*LevelUp, error) {
+	query := `SELECT ` + levelUpColumns + `
+		FROM character_level_ups
+		WHERE character_id = $1
+		ORDER BY created_at DESC, level DESC
+	`
+
+	rows, err := s.db.Query(query, characterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query level-ups: %w", err)
+	}
+	defer rows.Close()
+
+	levelUps := []*LevelUp{}
+	for rows.Next() {
+		l, err := scanLevelUp(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan level-up: %w", err)
+		}
+		levelUps = append(levelUps, l)
+	}
+
+	return levelUps, rows.Err()
+}
+
+// insertLevelUp stores a level-up record in tx
+func insertLevelUp(tx *sql.Tx, l *LevelUp) error {
+	increases, err := json.Marshal(l.AbilityIncreases)
+	if err != nil {
+		return fmt.Errorf("failed to encode ability increases: %w", err)
+	}
+	spellIDs, err := json.Marshal(l.SpellIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode level-up spells: %w", err)
+	}
+	features, err := json.Marshal(l.Features)
+	if err != nil {
+		return fmt.Errorf("failed to encode level-up features: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO character_level_ups (`+levelUpColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`,
+		l.ID, l.CharacterID, l.UserID, l.Class, l.ClassLevel, l.Level, l.NewClass, l.HitDie,
+		l.HPMethod, l.HPRoll, l.HPGained, increases, l.Feat, l.Subclass,
+		spellIDs, features, l.Revision, l.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create level-up: %w", err)
+	}
+	return nil
+}
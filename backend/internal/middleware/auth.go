@@ -1,50 +1,74 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/jwtkeys"
 )
 
-// AuthMiddleware validates JWT tokens and extracts user information
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// SessionChecker reports whether a login session is still active
+type SessionChecker interface {
+	IsSessionActive(sessionID string) (bool, error)
+}
+
+// AuthMiddleware validates JWT tokens and extracts user information. Tokens
+// must be signed by a key in the keyring with that key's algorithm, and
+// tokens whose session has been revoked are rejected even before they expire.
+func AuthMiddleware(keys *jwtkeys.Keyring, sessions SessionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Error(apperror.Unauthorized("missing_token", "Authorization header required"))
 			c.Abort()
 			return
 		}
 
-		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-		
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtSecret), nil
-		})
+		scheme, tokenString, ok := strings.Cut(authHeader, " ")
+		tokenString = strings.TrimSpace(tokenString)
+		if !ok || !strings.EqualFold(scheme, "Bearer") || tokenString == "" {
+			c.Error(apperror.Unauthorized("invalid_token", "Authorization header must be a Bearer token"))
+			c.Abort()
+			return
+		}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		claims, err := keys.Parse(tokenString)
+		if err != nil {
+			c.Error(apperror.Unauthorized("invalid_token", "Invalid token"))
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
+		userID, ok := claims["user_id"].(string)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.Error(apperror.Unauthorized("invalid_token", "Invalid user ID in token"))
 			c.Abort()
 			return
 		}
 
-		userID, ok := claims["user_id"].(string)
+		sessionID, ok := claims["sid"].(string)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
+			c.Error(apperror.Unauthorized("invalid_token", "Token has no session; please log in again"))
+			c.Abort()
+			return
+		}
+
+		active, err := sessions.IsSessionActive(sessionID)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if !active {
+			c.Error(apperror.Unauthorized("session_revoked", "Session has been logged out"))
 			c.Abort()
 			return
 		}
 
 		c.Set("user_id", userID)
+		c.Set("session_id", sessionID)
 		c.Next()
 	}
-} 
\ No newline at end of file
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// ErrorResponse is the JSON envelope for every error reply
+type ErrorResponse struct {
+	Error     string            `json:"error"`
+	Code      string            `json:"code"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	Details   interface{}       `json:"details,omitempty"`
+	RequestID string            `json:"request_id"`
+}
+
+func init() {
+	// Report binding errors using JSON (or query parameter) names rather
+	// than Go field names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name := strings.SplitN(f.Tag.Get(tag), ",", 2)[0]
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return f.Name
+		})
+	}
+}
+
+// RequestID assigns every request an ID, reusing one supplied by the client
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" {
+			id = uuid.New().String()
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// ErrorHandler turns the last error attached with c.Error into a JSON
+// error response
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		status, body := errorResponse(err)
+		body.RequestID = c.GetString("request_id")
+
+		if status == http.StatusInternalServerError {
+			log.Printf("request %s failed: %v", body.RequestID, err)
+		}
+
+		c.JSON(status, body)
+	}
+}
+
+// errorResponse maps an error to its HTTP status and response body
+func errorResponse(err error) (int, ErrorResponse) {
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		return http.StatusInternalServerError, ErrorResponse{
+			Error: "Internal server error",
+			Code:  "internal_error",
+		}
+	}
+
+	body := ErrorResponse{
+		Error:   appErr.Message,
+		Code:    appErr.Code,
+		Fields:  appErr.Fields,
+		Details: appErr.Details,
+	}
+
+	switch appErr.Kind {
+	case apperror.ErrNotFound:
+		return http.StatusNotFound, body
+	case apperror.ErrConflict:
+		return http.StatusConflict, body
+	case apperror.ErrValidation:
+		return http.StatusBadRequest, body
+	case apperror.ErrUnauthorized:
+		return http.StatusUnauthorized, body
+	case apperror.ErrForbidden:
+		return http.StatusForbidden, body
+	case apperror.ErrPreconditionFailed:
+		return http.StatusPreconditionFailed, body
+	case apperror.ErrPreconditionRequired:
+		return http.StatusPreconditionRequired, body
+	}
+
+	return http.StatusInternalServerError, body
+}
@@ -0,0 +1,139 @@
+package purse
+
+// Coin values in copper pieces
+const (
+	CopperValue   = 1
+	SilverValue   = 10
+	ElectrumValue = 50
+	GoldValue     = 100
+	PlatinumValue = 1000
+)
+
+// CoinsPerPound is how many coins of any kind weigh a pound
+const CoinsPerPound = 50
+
+// values lists coin values in the order of Coins.array, cheapest first
+var values = [5]int{CopperValue, SilverValue, ElectrumValue, GoldValue, PlatinumValue}
+
+// Coins is a count of each kind of coin
+type Coins struct {
+	CP int `json:"cp"`
+	SP int `json:"sp"`
+	EP int `json:"ep"`
+	GP int `json:"gp"`
+	PP int `json:"pp"`
+}
+
+func (c Coins) array() [5]int {
+	return [5]int{c.CP, c.SP, c.EP, c.GP, c.PP}
+}
+
+func coinsFrom(a [5]int) Coins {
+	return Coins{CP: a[0], SP: a[1], EP: a[2], GP: a[3], PP: a[4]}
+}
+
+// Value is what the coins are worth in copper pieces
+func (c Coins) Value() int {
+	total := 0
+	for i, n := range c.array() {
+		total += n * values[i]
+	}
+	return total
+}
+
+// Count is the number of coins
+func (c Coins) Count() int {
+	return c.CP + c.SP + c.EP + c.GP + c.PP
+}
+
+// IsZero reports whether there are no coins at all
+func (c Coins) IsZero() bool {
+	return c == Coins{}
+}
+
+// Add returns the coins of both c and o
+func (c Coins) Add(o Coins) Coins {
+	a, b := c.array(), o.array()
+	for i := range a {
+		a[i] += b[i]
+	}
+	return coinsFrom(a)
+}
+
+// Sub returns c less each denomination of o, which may go negative
+func (c Coins) Sub(o Coins) Coins {
+	a, b := c.array(), o.array()
+	for i := range a {
+		a[i] -= b[i]
+	}
+	return coinsFrom(a)
+}
+
+// Pay takes amount out of purse and returns what is left. Matching coins
+// are paid first. Anything still owed is paid in smaller coins when they add
+// up to it exactly, otherwise by breaking the smallest coin that covers it,
+// with the change going back into the purse. ok is false when the purse is
+// worth less than amount.
+func Pay(purse, amount Coins) (left Coins, ok bool) {
+	if purse.Value() < amount.Value() {
+		return purse, false
+	}
+
+	have, want := purse.array(), amount.array()
+	owed := 0
+	for i := range have {
+		n := min(have[i], want[i])
+		have[i] -= n
+		owed += (want[i] - n) * values[i]
+	}
+	if owed == 0 {
+		return coinsFrom(have), true
+	}
+
+	if exact, rest := payDown(have, owed); rest == 0 {
+		return coinsFrom(exact), true
+	}
+	for i := range have {
+		if have[i] > 0 && values[i] >= owed {
+			return coinsFrom(breakCoin(have, i, owed)), true
+		}
+	}
+
+	// No single coin covers the rest, so pay what the smaller coins can and
+	// break one for the remainder. Any denomination payDown stopped short
+	// on has a coin worth more than what is left.
+	have, owed = payDown(have, owed)
+	for i := range have {
+		if have[i] > 0 && values[i] > owed {
+			have = breakCoin(have, i, owed)
+			break
+		}
+	}
+	return coinsFrom(have), true
+}
+
+// payDown pays as much of owed as it can with coins no larger than it,
+// largest first, and returns the coins left and what is still owed
+func payDown(have [5]int, owed int) ([5]int, int) {
+	for i := len(have) - 1; i >= 0; i-- {
+		n := min(have[i], owed/values[i])
+		have[i] -= n
+		owed -= n * values[i]
+	}
+	return have, owed
+}
+
+// breakCoin pays owed with one coin of denomination i and adds the change
+// to have in the fewest coins, skipping electrum as shopkeepers do
+func breakCoin(have [5]int, i, owed int) [5]int {
+	have[i]--
+	change := values[i] - owed
+	for j := i - 1; j >= 0; j-- {
+		if values[j] == ElectrumValue {
+			continue
+		}
+		have[j] += change / values[j]
+		change %= values[j]
+	}
+	return have
+}
@@ -0,0 +1,82 @@
+package purse
+
+import "testing"
+
+func TestCoinsValue(t *testing.T) {
+	c := Coins{CP: 3, SP: 2, EP: 1, GP: 4, PP: 1}
+	if got := c.Value(); got != 3+20+50+400+1000 {
+		t.Errorf("Value() = %d", got)
+	}
+	if got := c.Count(); got != 11 {
+		t.Errorf("Count() = %d, want 11", got)
+	}
+	if c.IsZero() || !(Coins{}).IsZero() {
+		t.Error("IsZero() is wrong")
+	}
+}
+
+func TestPay(t *testing.T) {
+	tests := []struct {
+		name   string
+		purse  Coins
+		amount Coins
+		want   Coins
+	}{
+		{"matching coins", Coins{GP: 5, SP: 3}, Coins{GP: 2, SP: 1}, Coins{GP: 3, SP: 2}},
+		{"nothing", Coins{GP: 1}, Coins{}, Coins{GP: 1}},
+		{"smaller coins add up exactly", Coins{SP: 15}, Coins{GP: 1}, Coins{SP: 5}},
+		{"largest smaller coins first", Coins{CP: 20, SP: 12}, Coins{GP: 1, CP: 5}, Coins{CP: 15, SP: 2}},
+		{"break a gold piece", Coins{GP: 1}, Coins{SP: 3}, Coins{SP: 7}},
+		{"break a platinum piece", Coins{PP: 1}, Coins{CP: 1}, Coins{CP: 9, SP: 9, GP: 9}},
+		{"break the smallest coin that covers it", Coins{GP: 1, PP: 1}, Coins{SP: 5}, Coins{SP: 5, PP: 1}},
+		{"change skips electrum", Coins{GP: 1}, Coins{SP: 4, CP: 5}, Coins{CP: 5, SP: 5}},
+		{"match then break", Coins{SP: 3, GP: 1}, Coins{SP: 5}, Coins{SP: 8}},
+		{"pay down then break", Coins{GP: 2}, Coins{EP: 3}, Coins{SP: 5}},
+		{"everything", Coins{CP: 1, SP: 1, GP: 1}, Coins{CP: 111}, Coins{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, ok := Pay(tt.purse, tt.amount)
+			if !ok {
+				t.Fatalf("Pay(%+v, %+v) couldn't pay", tt.purse, tt.amount)
+			}
+			if left != tt.want {
+				t.Errorf("Pay(%+v, %+v) = %+v, want %+v", tt.purse, tt.amount, left, tt.want)
+			}
+			if left.Value() != tt.purse.Value()-tt.amount.Value() {
+				t.Errorf("left %d cp from %d cp paying %d cp", left.Value(), tt.purse.Value(), tt.amount.Value())
+			}
+		})
+	}
+}
+
+func TestPayShort(t *testing.T) {
+	purse := Coins{GP: 1, SP: 4}
+	left, ok := Pay(purse, Coins{GP: 1, SP: 4, CP: 1})
+	if ok {
+		t.Fatal("Pay() paid more than the purse is worth")
+	}
+	if left != purse {
+		t.Errorf("a failed payment left %+v, want the purse unchanged", left)
+	}
+}
+
+func TestBreakCoin(t *testing.T) {
+	tests := []struct {
+		have [5]int
+		i    int
+		owed int
+		want [5]int
+	}{
+		{[5]int{0, 0, 0, 1, 0}, 3, 100, [5]int{0, 0, 0, 0, 0}},
+		{[5]int{0, 0, 0, 1, 0}, 3, 1, [5]int{9, 9, 0, 0, 0}},
+		{[5]int{0, 0, 1, 0, 0}, 2, 5, [5]int{5, 4, 0, 0, 0}},
+		{[5]int{2, 0, 0, 0, 1}, 4, 250, [5]int{2, 5, 0, 7, 0}},
+	}
+	for _, tt := range tests {
+		if got := breakCoin(tt.have, tt.i, tt.owed); got != tt.want {
+			t.Errorf("breakCoin(%v, %d, %d) = %v, want %v", tt.have, tt.i, tt.owed, got, tt.want)
+		}
+	}
+}
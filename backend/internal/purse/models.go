@@ -0,0 +1,88 @@
+package purse
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transaction kinds
+const (
+	KindDeposit     = "deposit"
+	KindWithdraw    = "withdraw"
+	KindTransferIn  = "transfer_in"
+	KindTransferOut = "transfer_out"
+)
+
+// DefaultLimit is the page size used when a listing doesn't ask for one
+const DefaultLimit = 50
+
+// Purse is the coin a character carries
+type Purse struct {
+	CharacterID uuid.UUID `json:"character_id"`
+	Coins
+	// TotalCP is the purse's worth in copper pieces
+	TotalCP int `json:"total_cp"`
+	// Weight is in pounds, at CoinsPerPound
+	Weight float64 `json:"weight"`
+}
+
+// Transaction is a logged change to a character's purse
+type Transaction struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	CharacterID uuid.UUID `json:"character_id" db:"character_id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	Kind        string    `json:"kind" db:"kind"`
+	// Amount is the coin asked for
+	Amount Coins `json:"amount" db:"amount"`
+	// Delta is what actually went in or out of each denomination, negative
+	// for coins paid out. It differs from Amount when change was made.
+	Delta   Coins `json:"delta" db:"delta"`
+	Balance Coins `json:"balance" db:"balance"`
+	// CounterpartyID is the other character in a transfer
+	CounterpartyID *uuid.UUID `json:"counterparty_id" db:"counterparty_id"`
+	Note           *string    `json:"note" db:"note"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Transfer is both sides of coin moved between two characters
+type Transfer struct {
+	From *Transaction `json:"from"`
+	To   *Transaction `json:"to"`
+}
+
+// CoinRequest represents a request to deposit or withdraw coin
+type CoinRequest struct {
+	CP   int     `json:"cp" binding:"min=0,max=1000000"`
+	SP   int     `json:"sp" binding:"min=0,max=1000000"`
+	EP   int     `json:"ep" binding:"min=0,max=1000000"`
+	GP   int     `json:"gp" binding:"min=0,max=1000000"`
+	PP   int     `json:"pp" binding:"min=0,max=1000000"`
+	Note *string `json:"note" binding:"omitempty,max=255"`
+}
+
+// Coins returns the coin the request asks for
+func (r *CoinRequest) Coins() Coins {
+	return Coins{CP: r.CP, SP: r.SP, EP: r.EP, GP: r.GP, PP: r.PP}
+}
+
+// TransferRequest represents a request to move coin to another character
+type TransferRequest struct {
+	ToCharacterID string `json:"to_character_id" binding:"required,uuid"`
+	CoinRequest
+}
+
+// Filter narrows a transaction listing
+type Filter struct {
+	Kind   string `form:"kind" binding:"omitempty,oneof=deposit withdraw transfer_in transfer_out"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// Page is one page of a transaction listing, newest first
+type Page struct {
+	Transactions []*Transaction `json:"transactions"`
+	Total        int            `json:"total"`
+	Limit        int            `json:"limit"`
+	Offset       int            `json:"offset"`
+}
@@ -0,0 +1,199 @@
+package purse
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/character"
+)
+
+var (
+	// ErrEmptyAmount is returned for a transaction that moves no coin
+	ErrEmptyAmount = apperror.Field("amount", "must include at least one coin")
+	// ErrInsufficientFunds is returned when a purse is worth less than the
+	// coin taken out of it
+	ErrInsufficientFunds = apperror.Conflict("insufficient_funds", "Not enough coin in the purse")
+	// ErrSameCharacter is returned for a transfer to the paying character
+	ErrSameCharacter = apperror.Field("to_character_id", "must be a different character")
+	// ErrTransferTarget is returned for a transfer to a character the user
+	// doesn't own
+	ErrTransferTarget = apperror.Field("to_character_id", "must be another character you own")
+)
+
+// Service handles character purses
+type Service struct {
+	store      Store
+	characters *character.Service
+}
+
+// NewService creates a new purse service
+func NewService(store Store, characters *character.Service) *Service {
+	return &Service{store: store, characters: characters}
+}
+
+// GetPurse returns a character's coins
+func (s *Service) GetPurse(characterID, userID string) (*Purse, error) {
+	char, err := s.characters.Authorize(characterID, userID, character.AccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	coins, err := s.store.Get(characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Purse{
+		CharacterID: char.ID,
+		Coins:       coins,
+		TotalCP:     coins.Value(),
+		Weight:      float64(coins.Count()) / CoinsPerPound,
+	}, nil
+}
+
+// ListTransactions returns a page of a character's purse transactions
+func (s *Service) ListTransactions(characterID, userID string, filter *Filter) (*Page, error) {
+	if _, err := s.characters.Authorize(characterID, userID, character.AccessRead); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultLimit
+	}
+
+	entries, total, err := s.store.ListTransactions(characterID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{Transactions: entries, Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+// Deposit adds coin to a character the user owns, exactly as given
+func (s *Service) Deposit(characterID, userID string, req *CoinRequest) (*Transaction, error) {
+	amount := req.Coins()
+	if amount.IsZero() {
+		return nil, ErrEmptyAmount
+	}
+	char, err := s.characters.Authorize(characterID, userID, character.AccessOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := newTransaction(char.ID, userID, KindDeposit, amount, req.Note)
+	err = s.store.Update([]string{characterID}, func(purses map[string]*Coins) ([]*Transaction, error) {
+		coins := purses[characterID]
+		*coins = coins.Add(amount)
+		entry.Delta = amount
+		entry.Balance = *coins
+		return []*Transaction{entry}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Withdraw takes coin from a character the user owns, making change from
+// larger coins when the purse lacks the exact denominations
+func (s *Service) Withdraw(characterID, userID string, req *CoinRequest) (*Transaction, error) {
+	amount := req.Coins()
+	if amount.IsZero() {
+		return nil, ErrEmptyAmount
+	}
+	char, err := s.characters.Authorize(characterID, userID, character.AccessOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := newTransaction(char.ID, userID, KindWithdraw, amount, req.Note)
+	err = s.store.Update([]string{characterID}, func(purses map[string]*Coins) ([]*Transaction, error) {
+		if err := pay(purses[characterID], amount, entry); err != nil {
+			return nil, err
+		}
+		return []*Transaction{entry}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Transfer moves coin between two characters owned by the user. The payer
+// makes change as for a withdrawal and the payee receives exactly the coins
+// asked for; both purses change together or not at all.
+func (s *Service) Transfer(characterID, userID string, req *TransferRequest) (*Transfer, error) {
+	amount := req.Coins()
+	if amount.IsZero() {
+		return nil, ErrEmptyAmount
+	}
+	from, err := s.characters.Authorize(characterID, userID, character.AccessOwner)
+	if err != nil {
+		return nil, err
+	}
+	if req.ToCharacterID == from.ID.String() {
+		return nil, ErrSameCharacter
+	}
+	to, err := s.characters.Authorize(req.ToCharacterID, userID, character.AccessOwner)
+	if errors.Is(err, apperror.ErrNotFound) || errors.Is(err, apperror.ErrForbidden) {
+		return nil, ErrTransferTarget
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := newTransaction(from.ID, userID, KindTransferOut, amount, req.Note)
+	out.CounterpartyID = &to.ID
+	in := newTransaction(to.ID, userID, KindTransferIn, amount, req.Note)
+	in.CounterpartyID = &from.ID
+	in.CreatedAt = out.CreatedAt
+
+	fromID, toID := from.ID.String(), to.ID.String()
+	err = s.store.Update([]string{fromID, toID}, func(purses map[string]*Coins) ([]*Transaction, error) {
+		if err := pay(purses[fromID], amount, out); err != nil {
+			return nil, err
+		}
+		coins := purses[toID]
+		*coins = coins.Add(amount)
+		in.Delta = amount
+		in.Balance = *coins
+		return []*Transaction{out, in}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transfer{From: out, To: in}, nil
+}
+
+// pay takes amount out of coins, recording what was paid on entry
+func pay(coins *Coins, amount Coins, entry *Transaction) error {
+	left, ok := Pay(*coins, amount)
+	if !ok {
+		return ErrInsufficientFunds.WithDetails(map[string]int{
+			"balance_cp": coins.Value(),
+			"amount_cp":  amount.Value(),
+		})
+	}
+	entry.Delta = left.Sub(*coins)
+	entry.Balance = left
+	*coins = left
+	return nil
+}
+
+func newTransaction(characterID uuid.UUID, userID, kind string, amount Coins, note *string) *Transaction {
+	return &Transaction{
+		ID:          uuid.New(),
+		CharacterID: characterID,
+		UserID:      uuid.MustParse(userID),
+		Kind:        kind,
+		Amount:      amount,
+		Note:        note,
+		CreatedAt:   time.Now(),
+	}
+}
@@ -0,0 +1,21 @@
+package purse
+
+// Change alters the purses of the characters a write locked, keyed by
+// character ID, and returns the transactions to log. Returning an error
+// leaves every purse as it was.
+type Change func(purses map[string]*Coins) ([]*Transaction, error)
+
+// Store persists purses and their transaction log. Writes are serialized
+// per character, so a Change sees the coins exactly as the write will find
+// them.
+type Store interface {
+	// Get returns a character's coins, which are all zero before the first
+	// deposit
+	Get(characterID string) (Coins, error)
+	// Update applies change to the purses of characterIDs and logs its
+	// transactions, all at once or not at all
+	Update(characterIDs []string, change Change) error
+	// ListTransactions returns a character's transactions matching the
+	// filter, newest first, and how many match in total
+	ListTransactions(characterID string, filter *Filter) ([]*Transaction, int, error)
+}
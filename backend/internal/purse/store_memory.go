@@ -0,0 +1,90 @@
+package purse
+
+import "sync"
+
+// MemoryStore is a purse Store kept in process memory, for tests and demos
+// that run without a database
+type MemoryStore struct {
+	mu           sync.Mutex
+	purses       map[string]Coins
+	transactions map[string][]*Transaction
+}
+
+// NewMemoryStore creates an empty in-memory purse store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		purses:       make(map[string]Coins),
+		transactions: make(map[string][]*Transaction),
+	}
+}
+
+// Get returns a character's coins
+func (s *MemoryStore) Get(characterID string) (Coins, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.purses[characterID], nil
+}
+
+// Update applies change to the purses of characterIDs and logs its
+// transactions
+func (s *MemoryStore) Update(characterIDs []string, change Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purses := make(map[string]*Coins, len(characterIDs))
+	for _, id := range characterIDs {
+		coins := s.purses[id]
+		purses[id] = &coins
+	}
+
+	entries, err := change(purses)
+	if err != nil {
+		return err
+	}
+
+	for id, coins := range purses {
+		s.purses[id] = *coins
+	}
+	for _, entry := range entries {
+		id := entry.CharacterID.String()
+		s.transactions[id] = append(s.transactions[id], entry.clone())
+	}
+	return nil
+}
+
+// ListTransactions returns a character's transactions matching the filter,
+// newest first
+func (s *MemoryStore) ListTransactions(characterID string, filter *Filter) ([]*Transaction, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []*Transaction
+	all := s.transactions[characterID]
+	for i := len(all) - 1; i >= 0; i-- {
+		if filter.Kind == "" || all[i].Kind == filter.Kind {
+			matched = append(matched, all[i])
+		}
+	}
+
+	entries := []*Transaction{}
+	for i := filter.Offset; i < len(matched) && len(entries) < filter.Limit; i++ {
+		entries = append(entries, matched[i].clone())
+	}
+
+	return entries, len(matched), nil
+}
+
+// clone returns a copy of a transaction that can be handed out safely
+func (t *Transaction) clone() *Transaction {
+	cp := *t
+	if t.CounterpartyID != nil {
+		id := *t.CounterpartyID
+		cp.CounterpartyID = &id
+	}
+	if t.Note != nil {
+		note := *t.Note
+		cp.Note = &note
+	}
+	return &cp
+}
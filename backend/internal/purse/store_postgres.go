@@ -0,0 +1,184 @@
+package purse
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// PostgresStore is a purse Store backed by PostgreSQL
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a purse store using the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Get returns a character's coins
+func (s *PostgresStore) Get(characterID string) (Coins, error) {
+	return getCoins(s.db, characterID)
+}
+
+func getCoins(q queryRower, characterID string) (Coins, error) {
+	var c Coins
+	err := q.QueryRow(
+		"SELECT cp, sp, ep, gp, pp FROM purses WHERE character_id = $1",
+		characterID,
+	).Scan(&c.CP, &c.SP, &c.EP, &c.GP, &c.PP)
+	if err == sql.ErrNoRows {
+		return Coins{}, nil
+	}
+	if err != nil {
+		return Coins{}, fmt.Errorf("failed to get purse: %w", err)
+	}
+	return c, nil
+}
+
+// Update applies change to the purses of characterIDs and logs its
+// transactions in a single database transaction. The characters are locked
+// in ID order so concurrent transfers between the same pair can't deadlock.
+func (s *PostgresStore) Update(characterIDs []string, change Change) error {
+	ids := append([]string(nil), characterIDs...)
+	sort.Strings(ids)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	purses := make(map[string]*Coins, len(ids))
+	for _, id := range ids {
+		if _, err := tx.Exec("SELECT 1 FROM characters WHERE id = $1 FOR UPDATE", id); err != nil {
+			return fmt.Errorf("failed to lock character: %w", err)
+		}
+		coins, err := getCoins(tx, id)
+		if err != nil {
+			return err
+		}
+		purses[id] = &coins
+	}
+
+	entries, err := change(purses)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		c := purses[id]
+		_, err := tx.Exec(`
+			INSERT INTO purses (character_id, cp, sp, ep, gp, pp, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, NOW())
+			ON CONFLICT (character_id) DO UPDATE SET
+				cp = EXCLUDED.cp, sp = EXCLUDED.sp, ep = EXCLUDED.ep,
+				gp = EXCLUDED.gp, pp = EXCLUDED.pp, updated_at = EXCLUDED.updated_at`,
+			id, c.CP, c.SP, c.EP, c.GP, c.PP,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save purse: %w", err)
+		}
+	}
+
+	for _, entry := range entries {
+		if err := insertTransaction(tx, entry); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit purse: %w", err)
+	}
+
+	return nil
+}
+
+func insertTransaction(tx *sql.Tx, t *Transaction) error {
+	amount, err := json.Marshal(t.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to encode amount: %w", err)
+	}
+	delta, err := json.Marshal(t.Delta)
+	if err != nil {
+		return fmt.Errorf("failed to encode delta: %w", err)
+	}
+	balance, err := json.Marshal(t.Balance)
+	if err != nil {
+		return fmt.Errorf("failed to encode balance: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO purse_transactions (
+			id, character_id, user_id, kind, amount, delta, balance,
+			counterparty_id, note, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		t.ID, t.CharacterID, t.UserID, t.Kind, amount, delta, balance,
+		t.CounterpartyID, t.Note, t.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to log transaction: %w", err)
+	}
+	return nil
+}
+
+// ListTransactions returns a character's transactions matching the filter,
+// newest first
+func (s *PostgresStore) ListTransactions(characterID string, filter *Filter) ([]*Transaction, int, error) {
+	where := "character_id = $1"
+	args := []interface{}{characterID}
+	if filter.Kind != "" {
+		where += " AND kind = $2"
+		args = append(args, filter.Kind)
+	}
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM purse_transactions WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count transactions: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, character_id, user_id, kind, amount, delta, balance,
+			counterparty_id, note, created_at
+		FROM purse_transactions
+		WHERE %s
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+
+	rows, err := s.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*Transaction{}
+	for rows.Next() {
+		t := &Transaction{}
+		var amount, delta, balance []byte
+		err := rows.Scan(
+			&t.ID, &t.CharacterID, &t.UserID, &t.Kind, &amount, &delta, &balance,
+			&t.CounterpartyID, &t.Note, &t.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		for _, col := range []struct {
+			raw  []byte
+			dest *Coins
+		}{{amount, &t.Amount}, {delta, &t.Delta}, {balance, &t.Balance}} {
+			if err := json.Unmarshal(col.raw, col.dest); err != nil {
+				return nil, 0, fmt.Errorf("failed to decode transaction: %w", err)
+			}
+		}
+		entries = append(entries, t)
+	}
+
+	return entries, total, rows.Err()
+}
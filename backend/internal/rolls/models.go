@@ -0,0 +1,76 @@
+package rolls
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/dice"
+)
+
+// KindCustom marks a roll of a free-form expression; other kinds are the
+// character check kinds such as character.RollSkill
+const KindCustom = "custom"
+
+// DefaultLimit is the page size used when a listing doesn't ask for one
+const DefaultLimit = 50
+
+// Entry is a recorded roll
+type Entry struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	CharacterID uuid.UUID `json:"character_id" db:"character_id"`
+	// UserID is who made the roll, which may be a DM rather than the owner
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Kind       string    `json:"kind" db:"kind"`
+	Name       string    `json:"name,omitempty" db:"name"`
+	Label      *string   `json:"label" db:"label"`
+	Expression string    `json:"expression" db:"expression"`
+	Total      int       `json:"total" db:"total"`
+	// Natural is the kept d20 face, for rolls that include a d20
+	Natural   *int              `json:"natural" db:"natural_d20"`
+	Terms     []dice.TermResult `json:"terms" db:"breakdown"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+}
+
+// RollRequest represents a request to make and record a roll. Either
+// Expression is rolled as is, or Kind names a check using the character's
+// bonuses.
+type RollRequest struct {
+	Kind         string  `json:"kind" binding:"omitempty,oneof=custom skill save ability initiative"`
+	Name         string  `json:"name"`
+	Expression   string  `json:"expression" binding:"max=200"`
+	Extra        string  `json:"extra" binding:"max=100"`
+	Advantage    bool    `json:"advantage"`
+	Disadvantage bool    `json:"disadvantage"`
+	Label        *string `json:"label" binding:"omitempty,max=100"`
+}
+
+// Filter narrows a roll listing or the statistics computed over it
+type Filter struct {
+	Kind   string     `form:"kind" binding:"omitempty,oneof=custom skill save ability initiative"`
+	Name   string     `form:"name"`
+	Label  string     `form:"label"`
+	UserID string     `form:"user_id" binding:"omitempty,uuid"`
+	Since  *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until  *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit  int        `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset int        `form:"offset" binding:"omitempty,min=0"`
+}
+
+// Page is one page of a roll listing, newest first
+type Page struct {
+	Rolls  []*Entry `json:"rolls"`
+	Total  int      `json:"total"`
+	Limit  int      `json:"limit"`
+	Offset int      `json:"offset"`
+}
+
+// Stats summarizes the d20 rolls matching a filter
+type Stats struct {
+	Rolls    int `json:"rolls"`
+	D20Rolls int `json:"d20_rolls"`
+	// AverageD20 is null when there are no d20 rolls
+	AverageD20 *float64 `json:"average_d20"`
+	Nat20s     int      `json:"nat_20s"`
+	Nat1s      int      `json:"nat_1s"`
+}
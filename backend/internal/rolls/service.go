@@ -0,0 +1,108 @@
+package rolls
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/character"
+	"character-sheet-backend/internal/dice"
+)
+
+// Service makes and records rolls for characters
+type Service struct {
+	store      Store
+	characters *character.Service
+	roller     *dice.Roller
+}
+
+// NewService creates a new roll service
+func NewService(store Store, characters *character.Service, roller *dice.Roller) *Service {
+	return &Service{store: store, characters: characters, roller: roller}
+}
+
+// Record makes a roll for a character the user may read and stores it
+func (s *Service) Record(characterID, userID string, req *RollRequest) (*Entry, error) {
+	kind := req.Kind
+	if kind == "" {
+		kind = KindCustom
+	}
+
+	var result *dice.Result
+	var name string
+	if kind == KindCustom {
+		if req.Expression == "" {
+			return nil, apperror.Field("expression", "is required for custom rolls")
+		}
+		if _, err := s.characters.GetCharacterByID(characterID, userID); err != nil {
+			return nil, err
+		}
+
+		var err error
+		result, err = s.roller.RollNotation(req.Expression, dice.ModeFor(req.Advantage, req.Disadvantage))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		check, err := s.characters.RollCheck(characterID, userID, kind, &character.CheckRollRequest{
+			Name:         req.Name,
+			Advantage:    req.Advantage,
+			Disadvantage: req.Disadvantage,
+			Extra:        req.Extra,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result, name = check.Result, check.Name
+	}
+
+	entry := &Entry{
+		ID:          uuid.New(),
+		CharacterID: uuid.MustParse(characterID),
+		UserID:      uuid.MustParse(userID),
+		Kind:        kind,
+		Name:        name,
+		Label:       req.Label,
+		Expression:  result.Expression,
+		Total:       result.Total,
+		Terms:       result.Terms,
+		CreatedAt:   time.Now(),
+	}
+	if natural, ok := result.Natural(); ok {
+		entry.Natural = &natural
+	}
+
+	if err := s.store.Create(entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// List returns a page of a character's recorded rolls
+func (s *Service) List(characterID, userID string, filter *Filter) (*Page, error) {
+	if _, err := s.characters.GetCharacterByID(characterID, userID); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultLimit
+	}
+
+	entries, total, err := s.store.List(characterID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{Rolls: entries, Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+// Stats summarizes a character's recorded d20 rolls
+func (s *Service) Stats(characterID, userID string, filter *Filter) (*Stats, error) {
+	if _, err := s.characters.GetCharacterByID(characterID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.store.Stats(characterID, filter)
+}
@@ -0,0 +1,13 @@
+package rolls
+
+// Store persists recorded rolls
+type Store interface {
+	// Create records a roll
+	Create(entry *Entry) error
+	// List returns a character's rolls matching the filter, newest first,
+	// along with the number of matches ignoring limit and offset
+	List(characterID string, filter *Filter) ([]*Entry, int, error)
+	// Stats summarizes a character's rolls matching the filter, ignoring
+	// limit and offset
+	Stats(characterID string, filter *Filter) (*Stats, error)
+}
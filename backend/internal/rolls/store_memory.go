@@ -0,0 +1,105 @@
+package rolls
+
+import (
+	"strings"
+	"sync"
+)
+
+// MemoryStore is a roll Store kept in process memory, for tests and demos
+// that run without a database
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries map[string][]*Entry
+}
+
+// NewMemoryStore creates an empty in-memory roll store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string][]*Entry)}
+}
+
+// Create records a roll
+func (s *MemoryStore) Create(entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp := *entry
+	id := entry.CharacterID.String()
+	s.entries[id] = append(s.entries[id], &cp)
+	return nil
+}
+
+// List returns a character's rolls matching the filter, newest first
+func (s *MemoryStore) List(characterID string, filter *Filter) ([]*Entry, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := s.matching(characterID, filter)
+	entries := []*Entry{}
+	for i := filter.Offset; i < len(matched) && len(entries) < filter.Limit; i++ {
+		cp := *matched[i]
+		entries = append(entries, &cp)
+	}
+
+	return entries, len(matched), nil
+}
+
+// Stats summarizes a character's rolls matching the filter
+func (s *MemoryStore) Stats(characterID string, filter *Filter) (*Stats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := &Stats{}
+	sum := 0
+	for _, entry := range s.matching(characterID, filter) {
+		stats.Rolls++
+		if entry.Natural == nil {
+			continue
+		}
+		stats.D20Rolls++
+		sum += *entry.Natural
+		switch *entry.Natural {
+		case 20:
+			stats.Nat20s++
+		case 1:
+			stats.Nat1s++
+		}
+	}
+
+	if stats.D20Rolls > 0 {
+		avg := float64(sum) / float64(stats.D20Rolls)
+		stats.AverageD20 = &avg
+	}
+	return stats, nil
+}
+
+// matching returns the entries passing the filter, newest first; the
+// caller must hold the lock
+func (s *MemoryStore) matching(characterID string, filter *Filter) []*Entry {
+	stored := s.entries[characterID]
+	matched := []*Entry{}
+	for i := len(stored) - 1; i >= 0; i-- {
+		if filter.matches(stored[i]) {
+			matched = append(matched, stored[i])
+		}
+	}
+	return matched
+}
+
+// matches reports whether an entry passes every filter condition
+func (f *Filter) matches(e *Entry) bool {
+	switch {
+	case f.Kind != "" && e.Kind != f.Kind:
+		return false
+	case f.Name != "" && e.Name != f.Name:
+		return false
+	case f.UserID != "" && e.UserID.String() != f.UserID:
+		return false
+	case f.Since != nil && e.CreatedAt.Before(*f.Since):
+		return false
+	case f.Until != nil && !e.CreatedAt.Before(*f.Until):
+		return false
+	case f.Label != "":
+		return e.Label != nil && strings.Contains(strings.ToLower(*e.Label), strings.ToLower(f.Label))
+	}
+	return true
+}
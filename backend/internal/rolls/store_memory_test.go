@@ -0,0 +1,148 @@
+package rolls
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// testRolls records rolls for a character an hour apart, oldest first
+type testRolls struct {
+	t           *testing.T
+	store       *MemoryStore
+	characterID uuid.UUID
+	start       time.Time
+	count       int
+}
+
+func newTestRolls(t *testing.T) *testRolls {
+	return &testRolls{t: t, store: NewMemoryStore(), characterID: uuid.New(), start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// add records a roll with a natural d20, or none when natural is 0
+func (r *testRolls) add(kind, name, label string, userID uuid.UUID, natural int) *Entry {
+	r.t.Helper()
+	entry := &Entry{
+		ID:          uuid.New(),
+		CharacterID: r.characterID,
+		UserID:      userID,
+		Kind:        kind,
+		Name:        name,
+		CreatedAt:   r.start.Add(time.Duration(r.count) * time.Hour),
+	}
+	r.count++
+	if label != "" {
+		entry.Label = &label
+	}
+	if natural != 0 {
+		entry.Natural = &natural
+	}
+	if err := r.store.Create(entry); err != nil {
+		r.t.Fatal(err)
+	}
+	return entry
+}
+
+func TestMemoryStoreFilters(t *testing.T) {
+	r := newTestRolls(t)
+	owner, dm := uuid.New(), uuid.New()
+	r.add("skill", "stealth", "Sneak past the guards", owner, 15)
+	r.add("save", "dexterity", "", owner, 20)
+	r.add("custom", "", "Fireball damage", dm, 0)
+	r.add("skill", "perception", "", dm, 1)
+	r.add("skill", "stealth", "SNEAK attack", owner, 8)
+
+	since := r.start.Add(time.Hour)
+	until := r.start.Add(3 * time.Hour)
+	tests := []struct {
+		name   string
+		filter Filter
+		// want are the natural d20s of the matches, newest first, with 0
+		// for rolls without one
+		want []int
+	}{
+		{"nothing", Filter{}, []int{8, 1, 0, 20, 15}},
+		{"kind", Filter{Kind: "skill"}, []int{8, 1, 15}},
+		{"kind and name", Filter{Kind: "skill", Name: "stealth"}, []int{8, 15}},
+		{"user", Filter{UserID: dm.String()}, []int{1, 0}},
+		{"since is inclusive, until exclusive", Filter{Since: &since, Until: &until}, []int{0, 20}},
+		{"label in any case", Filter{Label: "sneak"}, []int{8, 15}},
+		{"label and kind", Filter{Label: "ball", Kind: "custom"}, []int{0}},
+		{"label and user", Filter{Label: "sneak", UserID: dm.String()}, []int{}},
+		{"nothing matching", Filter{Kind: "initiative"}, []int{}},
+	}
+
+	for _, tt := range tests {
+		filter := tt.filter
+		filter.Limit = DefaultLimit
+		entries, total, err := r.store.List(r.characterID.String(), &filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []int{}
+		for _, entry := range entries {
+			natural := 0
+			if entry.Natural != nil {
+				natural = *entry.Natural
+			}
+			got = append(got, natural)
+		}
+		if !reflect.DeepEqual(got, tt.want) || total != len(tt.want) {
+			t.Errorf("%s: matched %v (total %d), want %v", tt.name, got, total, tt.want)
+		}
+	}
+
+	// Paging counts every match
+	entries, total, err := r.store.List(r.characterID.String(), &Filter{Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 5 || len(entries) != 2 || *entries[0].Natural != 1 || entries[1].Natural != nil {
+		t.Errorf("page of 2 from 1 returned %d of %d", len(entries), total)
+	}
+	if entries, _, _ := r.store.List(uuid.NewString(), &Filter{Limit: DefaultLimit}); len(entries) != 0 {
+		t.Errorf("another character has %d rolls", len(entries))
+	}
+}
+
+func TestMemoryStoreStats(t *testing.T) {
+	r := newTestRolls(t)
+	owner := uuid.New()
+	for _, natural := range []int{20, 1, 0, 12, 20, 0, 7} {
+		r.add("skill", "athletics", "", owner, natural)
+	}
+	r.add("save", "wisdom", "", owner, 1)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   Stats
+		avg    float64
+	}{
+		{"every roll", Filter{}, Stats{Rolls: 8, D20Rolls: 6, Nat20s: 2, Nat1s: 2}, 61.0 / 6},
+		{"one kind", Filter{Kind: "skill"}, Stats{Rolls: 7, D20Rolls: 5, Nat20s: 2, Nat1s: 1}, 60.0 / 5},
+		// Limit and offset are ignored
+		{"a page", Filter{Kind: "save", Limit: 1, Offset: 3}, Stats{Rolls: 1, D20Rolls: 1, Nat1s: 1}, 1},
+		{"no matches", Filter{Kind: "initiative"}, Stats{}, 0},
+	}
+
+	for _, tt := range tests {
+		got, err := r.store.Stats(r.characterID.String(), &tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		avg := got.AverageD20
+		got.AverageD20 = nil
+		if *got != tt.want {
+			t.Errorf("%s: stats = %+v, want %+v", tt.name, *got, tt.want)
+		}
+		switch {
+		case tt.want.D20Rolls == 0 && avg != nil:
+			t.Errorf("%s: average = %v, want none", tt.name, *avg)
+		case tt.want.D20Rolls > 0 && (avg == nil || *avg != tt.avg):
+			t.Errorf("%s: average = %v, want %v", tt.name, avg, tt.avg)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package rolls
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PostgresStore is a roll Store backed by PostgreSQL
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a roll store using the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Create records a roll
+func (s *PostgresStore) Create(entry *Entry) error {
+	breakdown, err := json.Marshal(entry.Terms)
+	if err != nil {
+		return fmt.Errorf("failed to encode roll breakdown: %w", err)
+	}
+
+	query := `
+		INSERT INTO rolls (
+			id, character_id, user_id, kind, name, label, expression, total,
+			natural_d20, breakdown, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err = s.db.Exec(query,
+		entry.ID, entry.CharacterID, entry.UserID, entry.Kind, entry.Name, entry.Label,
+		entry.Expression, entry.Total, entry.Natural, breakdown, entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record roll: %w", err)
+	}
+
+	return nil
+}
+
+// List returns a character's rolls matching the filter, newest first
+func (s *PostgresStore) List(characterID string, filter *Filter) ([]*Entry, int, error) {
+	where, args := filter.where(characterID)
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM rolls WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count rolls: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, character_id, user_id, kind, name, label, expression, total,
+			natural_d20, breakdown, created_at
+		FROM rolls
+		WHERE %s
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+
+	rows, err := s.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query rolls: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*Entry{}
+	for rows.Next() {
+		e := &Entry{}
+		var breakdown []byte
+		err := rows.Scan(
+			&e.ID, &e.CharacterID, &e.UserID, &e.Kind, &e.Name, &e.Label, &e.Expression,
+			&e.Total, &e.Natural, &breakdown, &e.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan roll: %w", err)
+		}
+		if err := json.Unmarshal(breakdown, &e.Terms); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode roll breakdown: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, total, rows.Err()
+}
+
+// Stats summarizes a character's rolls matching the filter
+func (s *PostgresStore) Stats(characterID string, filter *Filter) (*Stats, error) {
+	where, args := filter.where(characterID)
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(natural_d20),
+			AVG(natural_d20)::float8,
+			COUNT(*) FILTER (WHERE natural_d20 = 20),
+			COUNT(*) FILTER (WHERE natural_d20 = 1)
+		FROM rolls
+		WHERE ` + where
+
+	stats := &Stats{}
+	var avg sql.NullFloat64
+	err := s.db.QueryRow(query, args...).Scan(&stats.Rolls, &stats.D20Rolls, &avg, &stats.Nat20s, &stats.Nat1s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute roll stats: %w", err)
+	}
+	if avg.Valid {
+		stats.AverageD20 = &avg.Float64
+	}
+
+	return stats, nil
+}
+
+// where builds the WHERE clause and arguments shared by List and Stats
+func (f *Filter) where(characterID string) (string, []interface{}) {
+	conds := []string{"character_id = $1"}
+	args := []interface{}{characterID}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Kind != "" {
+		add("kind = $%d", f.Kind)
+	}
+	if f.Name != "" {
+		add("name = $%d", f.Name)
+	}
+	if f.UserID != "" {
+		add("user_id = $%d", f.UserID)
+	}
+	if f.Since != nil {
+		add("created_at >= $%d", *f.Since)
+	}
+	if f.Until != nil {
+		add("created_at < $%d", *f.Until)
+	}
+	if f.Label != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(f.Label)
+		add("label ILIKE $%d", "%"+escaped+"%")
+	}
+
+	return strings.Join(conds, " AND "), args
+}
@@ -0,0 +1,44 @@
+package rolls
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFilterWhere(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+	userID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	tests := []struct {
+		name   string
+		filter Filter
+		where  string
+		args   []interface{}
+	}{
+		{"nothing", Filter{}, "character_id = $1", []interface{}{"char"}},
+		{"limit and offset", Filter{Limit: 10, Offset: 20}, "character_id = $1", []interface{}{"char"}},
+		{"kind", Filter{Kind: "skill"}, "character_id = $1 AND kind = $2", []interface{}{"char", "skill"}},
+		{"kind and name", Filter{Kind: "save", Name: "dexterity"},
+			"character_id = $1 AND kind = $2 AND name = $3", []interface{}{"char", "save", "dexterity"}},
+		{"user and time range", Filter{UserID: userID, Since: &since, Until: &until},
+			"character_id = $1 AND user_id = $2 AND created_at >= $3 AND created_at < $4",
+			[]interface{}{"char", userID, since, until}},
+		{"label", Filter{Label: "sneak"}, "character_id = $1 AND label ILIKE $2", []interface{}{"char", "%sneak%"}},
+		{"label with wildcards", Filter{Label: `50%_off\`}, "character_id = $1 AND label ILIKE $2", []interface{}{"char", `%50\%\_off\\%`}},
+		{"everything", Filter{Kind: "custom", Name: "x", Label: "y", UserID: userID, Since: &since, Until: &until},
+			"character_id = $1 AND kind = $2 AND name = $3 AND user_id = $4 AND created_at >= $5 AND created_at < $6 AND label ILIKE $7",
+			[]interface{}{"char", "custom", "x", userID, since, until, "%y%"}},
+	}
+
+	for _, tt := range tests {
+		where, args := tt.filter.where("char")
+		if where != tt.where {
+			t.Errorf("%s: where = %q, want %q", tt.name, where, tt.where)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: args = %v, want %v", tt.name, args, tt.args)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"fmt"
+
+	"character-sheet-backend/internal/config"
+	"character-sheet-backend/internal/jwtkeys"
+)
+
+// NewKeyring builds the token keyring described by the configuration.
+// JWT_SECRET always verifies tokens, even when signing moves to a private
+// key, so switching algorithms doesn't log anyone out.
+func NewKeyring(cfg *config.Config) (*jwtkeys.Keyring, error) {
+	secretKey, err := jwtkeys.HMACKey(cfg.JWTSecret)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_SECRET: %w", err)
+	}
+
+	verifyOnly := []*jwtkeys.Key{}
+	for _, secret := range cfg.JWTPreviousSecrets {
+		key, err := jwtkeys.HMACKey(secret)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PREVIOUS_SECRETS: %w", err)
+		}
+		verifyOnly = append(verifyOnly, key)
+	}
+	for _, path := range cfg.JWTPreviousPublicKeyFiles {
+		key, err := jwtkeys.LoadPublicKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PREVIOUS_PUBLIC_KEY_FILES: %w", err)
+		}
+		verifyOnly = append(verifyOnly, key)
+	}
+
+	current := secretKey
+	if cfg.JWTSigningAlg != jwtkeys.HS256 {
+		current, err = jwtkeys.LoadPrivateKey(cfg.JWTPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE: %w", err)
+		}
+		if current.Algorithm != cfg.JWTSigningAlg {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE holds a %s key but JWT_SIGNING_ALG is %s", current.Algorithm, cfg.JWTSigningAlg)
+		}
+		verifyOnly = append(verifyOnly, secretKey)
+	}
+
+	return jwtkeys.NewKeyring(cfg.JWTIssuer, cfg.JWTAudience, current, verifyOnly...)
+}
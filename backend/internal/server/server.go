@@ -0,0 +1,268 @@
+package server
+
+import (
+	"database/sql"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+
+	"character-sheet-backend/internal/auth"
+	"character-sheet-backend/internal/campaign"
+	"character-sheet-backend/internal/character"
+	"character-sheet-backend/internal/config"
+	"character-sheet-backend/internal/content"
+	"character-sheet-backend/internal/dice"
+	"character-sheet-backend/internal/homebrew"
+	"character-sheet-backend/internal/inventory"
+	"character-sheet-backend/internal/levelup"
+	"character-sheet-backend/internal/middleware"
+	"character-sheet-backend/internal/purse"
+	"character-sheet-backend/internal/rolls"
+	"character-sheet-backend/internal/spellcasting"
+)
+
+// Stores groups the storage backends used by the API
+type Stores struct {
+	Users      auth.UserStore
+	Sessions   auth.SessionStore
+	Characters character.CharacterStore
+	Campaigns  campaign.Store
+	Rolls      rolls.Store
+	Items      inventory.Store
+	Purses     purse.Store
+	Spells     spellcasting.Store
+	Packs      homebrew.Store
+	LevelUps   levelup.Store
+}
+
+// NewPostgresStores creates stores backed by PostgreSQL
+func NewPostgresStores(db *sql.DB) Stores {
+	// The level-up store shares the character store's ApplyTx, so a
+	// level-up's character and spell writes commit in one transaction
+	characters := character.NewPostgresStore(db)
+	return Stores{
+		Users:      auth.NewPostgresUserStore(db),
+		Sessions:   auth.NewPostgresSessionStore(db),
+		Characters: characters,
+		Campaigns:  campaign.NewPostgresStore(db),
+		Rolls:      rolls.NewPostgresStore(db),
+		Items:      inventory.NewPostgresStore(db),
+		Purses:     purse.NewPostgresStore(db),
+		Spells:     spellcasting.NewPostgresStore(db),
+		Packs:      homebrew.NewPostgresStore(db),
+		LevelUps:   levelup.NewPostgresStore(db, characters),
+	}
+}
+
+// NewMemoryStores creates empty in-memory stores
+func NewMemoryStores() Stores {
+	characters := character.NewMemoryStore()
+	spells := spellcasting.NewMemoryStore()
+	return Stores{
+		Users:      auth.NewMemoryUserStore(),
+		Sessions:   auth.NewMemorySessionStore(),
+		Characters: characters,
+		Campaigns:  campaign.NewMemoryStore(),
+		Rolls:      rolls.NewMemoryStore(),
+		Items:      inventory.NewMemoryStore(),
+		Purses:     purse.NewMemoryStore(),
+		Spells:     spells,
+		Packs:      homebrew.NewMemoryStore(),
+		LevelUps:   levelup.NewMemoryStore(characters, spells),
+	}
+}
+
+// NewRouter wires services and handlers onto a gin engine
+func NewRouter(cfg *config.Config, stores Stores) (*gin.Engine, error) {
+	keys, err := NewKeyring(cfg)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := content.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize services
+	authService := auth.NewService(stores.Users, stores.Sessions, auth.TokenConfig{
+		Keys:       keys,
+		AccessTTL:  cfg.AccessTokenTTL,
+		RefreshTTL: cfg.RefreshTokenTTL,
+	})
+	campaignService := campaign.NewService(stores.Campaigns, stores.Characters)
+	roller := dice.NewRandomRoller()
+	// Class rules come from the SRD and the homebrew packs a character uses
+	packRules := homebrew.NewRules(stores.Packs, catalog)
+	// Campaign DMs get access to the characters attached to their campaigns
+	characterService := character.NewService(stores.Characters, campaignService, roller, packRules)
+	rollService := rolls.NewService(stores.Rolls, characterService, roller)
+	inventoryService := inventory.NewService(stores.Items, characterService)
+	purseService := purse.NewService(stores.Purses, characterService)
+	spellService := spellcasting.NewService(stores.Spells, characterService)
+	homebrewService := homebrew.NewService(stores.Packs, packRules, characterService, campaignService)
+	levelUpService := levelup.NewService(stores.LevelUps, packRules, characterService, roller)
+
+	// Initialize handlers
+	authHandler := auth.NewHandler(authService)
+	characterHandler := character.NewHandler(characterService)
+	campaignHandler := campaign.NewHandler(campaignService)
+	diceHandler := dice.NewHandler(roller)
+	rollHandler := rolls.NewHandler(rollService)
+	inventoryHandler := inventory.NewHandler(inventoryService)
+	purseHandler := purse.NewHandler(purseService)
+	spellHandler := spellcasting.NewHandler(spellService)
+	contentHandler := content.NewHandler(catalog)
+	homebrewHandler := homebrew.NewHandler(homebrewService)
+	levelUpHandler := levelup.NewHandler(levelUpService)
+
+	requireAuth := middleware.AuthMiddleware(keys, authService)
+
+	// Setup router
+	r := gin.Default()
+	r.Use(middleware.RequestID(), middleware.ErrorHandler())
+
+	// CORS middleware
+	r.Use(cors.New(cors.Config{
+		AllowOrigins:     []string{"http://localhost:3000", "http://frontend:3000"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "ETag", middleware.RequestIDHeader},
+		AllowCredentials: true,
+	}))
+
+	// Health check
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+
+	// Public signing keys for services verifying our tokens
+	r.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.JSON(200, keys.JWKS())
+	})
+
+	// API routes
+	api := r.Group("/api")
+	{
+		// Auth routes
+		authRoutes := api.Group("/auth")
+		{
+			authRoutes.POST("/register", authHandler.Register)
+			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/refresh", authHandler.Refresh)
+			authRoutes.POST("/logout", authHandler.Logout)
+			authRoutes.POST("/logout-all", requireAuth, authHandler.LogoutAll)
+			authRoutes.GET("/me", requireAuth, authHandler.GetMe)
+		}
+
+		// Character routes (protected)
+		characterRoutes := api.Group("/characters")
+		characterRoutes.Use(requireAuth)
+		{
+			characterRoutes.GET("", characterHandler.GetCharacters)
+			characterRoutes.POST("", characterHandler.CreateCharacter)
+			characterRoutes.GET("/:id", characterHandler.GetCharacter)
+			characterRoutes.PUT("/:id", characterHandler.UpdateCharacter)
+			characterRoutes.DELETE("/:id", characterHandler.DeleteCharacter)
+			characterRoutes.GET("/:id/history", characterHandler.GetHistory)
+			characterRoutes.GET("/:id/history/diff", characterHandler.DiffRevisions)
+			characterRoutes.GET("/:id/history/:revision", characterHandler.GetRevision)
+			characterRoutes.POST("/:id/restore/:revision", characterHandler.RestoreRevision)
+			characterRoutes.POST("/:id/roll/:kind", characterHandler.RollCheck)
+			characterRoutes.POST("/:id/hp", characterHandler.ChangeHitPoints)
+			characterRoutes.POST("/:id/death-saves", characterHandler.RollDeathSave)
+			characterRoutes.POST("/:id/stabilize", characterHandler.Stabilize)
+			characterRoutes.POST("/:id/revive", characterHandler.Revive)
+			characterRoutes.POST("/:id/conditions", characterHandler.AddCondition)
+			characterRoutes.DELETE("/:id/conditions/:condition", characterHandler.RemoveCondition)
+			characterRoutes.PUT("/:id/exhaustion", characterHandler.SetExhaustion)
+			characterRoutes.POST("/:id/short-rest", characterHandler.ShortRest)
+			characterRoutes.POST("/:id/long-rest", characterHandler.LongRest)
+			characterRoutes.POST("/:id/resources", characterHandler.AddResource)
+			characterRoutes.PUT("/:id/resources/:resourceId", characterHandler.UpdateResource)
+			characterRoutes.DELETE("/:id/resources/:resourceId", characterHandler.RemoveResource)
+			characterRoutes.POST("/:id/resources/:resourceId/increment", characterHandler.IncrementResource)
+			characterRoutes.POST("/:id/resources/:resourceId/decrement", characterHandler.DecrementResource)
+			characterRoutes.POST("/:id/recharge", characterHandler.Recharge)
+			characterRoutes.POST("/:id/rolls", rollHandler.Record)
+			characterRoutes.GET("/:id/rolls", rollHandler.List)
+			characterRoutes.GET("/:id/rolls/stats", rollHandler.Stats)
+			characterRoutes.GET("/:id/items", inventoryHandler.GetInventory)
+			characterRoutes.POST("/:id/items", inventoryHandler.CreateItem)
+			characterRoutes.GET("/:id/items/:itemId", inventoryHandler.GetItem)
+			characterRoutes.PUT("/:id/items/:itemId", inventoryHandler.UpdateItem)
+			characterRoutes.DELETE("/:id/items/:itemId", inventoryHandler.DeleteItem)
+			characterRoutes.GET("/:id/purse", purseHandler.GetPurse)
+			characterRoutes.GET("/:id/purse/transactions", purseHandler.ListTransactions)
+			characterRoutes.POST("/:id/purse/deposit", purseHandler.Deposit)
+			characterRoutes.POST("/:id/purse/withdraw", purseHandler.Withdraw)
+			characterRoutes.POST("/:id/purse/transfer", purseHandler.Transfer)
+			characterRoutes.GET("/:id/spells", spellHandler.GetSpellbook)
+			characterRoutes.POST("/:id/spells", spellHandler.CreateSpell)
+			characterRoutes.PUT("/:id/spells/:spellId", spellHandler.UpdateSpell)
+			characterRoutes.DELETE("/:id/spells/:spellId", spellHandler.DeleteSpell)
+			characterRoutes.POST("/:id/spell-slots/expend", spellHandler.ExpendSlots)
+			characterRoutes.POST("/:id/spell-slots/restore", spellHandler.RestoreSlots)
+			characterRoutes.GET("/:id/content", homebrewHandler.GetCharacterContent)
+			characterRoutes.PUT("/:id/packs", homebrewHandler.SetCharacterPacks)
+			characterRoutes.POST("/:id/level-up", levelUpHandler.LevelUp)
+			characterRoutes.POST("/:id/level-up/undo", levelUpHandler.UndoLevelUp)
+			characterRoutes.GET("/:id/level-ups", levelUpHandler.ListLevelUps)
+		}
+
+		// Homebrew packs (protected)
+		homebrewRoutes := api.Group("/homebrew")
+		homebrewRoutes.Use(requireAuth)
+		{
+			homebrewRoutes.GET("", homebrewHandler.ListPacks)
+			homebrewRoutes.POST("", homebrewHandler.CreatePack)
+			homebrewRoutes.GET("/:id", homebrewHandler.GetPack)
+			homebrewRoutes.PUT("/:id", homebrewHandler.UpdatePack)
+			homebrewRoutes.DELETE("/:id", homebrewHandler.DeletePack)
+			homebrewRoutes.GET("/:id/versions", homebrewHandler.ListVersions)
+		}
+
+		// SRD content (public, read-only)
+		contentRoutes := api.Group("/content")
+		{
+			contentRoutes.GET("/races", contentHandler.ListRaces)
+			contentRoutes.GET("/races/:index", contentHandler.GetRace)
+			contentRoutes.GET("/subraces", contentHandler.ListSubraces)
+			contentRoutes.GET("/subraces/:index", contentHandler.GetSubrace)
+			contentRoutes.GET("/classes", contentHandler.ListClasses)
+			contentRoutes.GET("/classes/:index", contentHandler.GetClass)
+			contentRoutes.GET("/subclasses", contentHandler.ListSubclasses)
+			contentRoutes.GET("/subclasses/:index", contentHandler.GetSubclass)
+			contentRoutes.GET("/backgrounds", contentHandler.ListBackgrounds)
+			contentRoutes.GET("/backgrounds/:index", contentHandler.GetBackground)
+			contentRoutes.GET("/spells", contentHandler.ListSpells)
+			contentRoutes.GET("/spells/:index", contentHandler.GetSpell)
+			contentRoutes.GET("/equipment", contentHandler.ListEquipment)
+			contentRoutes.GET("/equipment/:index", contentHandler.GetEquipment)
+			contentRoutes.GET("/conditions", contentHandler.ListConditions)
+			contentRoutes.GET("/conditions/:index", contentHandler.GetCondition)
+		}
+
+		// Dice rolling (protected)
+		api.POST("/roll", requireAuth, diceHandler.Roll)
+
+		// Campaign routes (protected)
+		campaignRoutes := api.Group("/campaigns")
+		campaignRoutes.Use(requireAuth)
+		{
+			campaignRoutes.GET("", campaignHandler.ListCampaigns)
+			campaignRoutes.POST("", campaignHandler.CreateCampaign)
+			campaignRoutes.POST("/join", campaignHandler.JoinCampaign)
+			campaignRoutes.GET("/:id", campaignHandler.GetCampaign)
+			campaignRoutes.PUT("/:id", campaignHandler.UpdateCampaign)
+			campaignRoutes.DELETE("/:id", campaignHandler.DeleteCampaign)
+			campaignRoutes.POST("/:id/invite-code", campaignHandler.RegenerateInviteCode)
+			campaignRoutes.PUT("/:id/members/:userId", campaignHandler.UpdateMember)
+			campaignRoutes.DELETE("/:id/members/:userId", campaignHandler.RemoveMember)
+			campaignRoutes.GET("/:id/characters", campaignHandler.ListCharacters)
+			campaignRoutes.POST("/:id/characters", campaignHandler.AttachCharacter)
+			campaignRoutes.DELETE("/:id/characters/:characterId", campaignHandler.DetachCharacter)
+		}
+	}
+
+	return r, nil
+}
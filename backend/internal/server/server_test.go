@@ -0,0 +1,184 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"character-sheet-backend/internal/config"
+)
+
+// testAPI drives a router over the in-memory stores
+type testAPI struct {
+	t      *testing.T
+	router *gin.Engine
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{
+		JWTSecret:       "test-secret",
+		StorageDriver:   config.StorageMemory,
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+		JWTSigningAlg:   "HS256",
+		JWTIssuer:       "character-sheet-test",
+		JWTAudience:     "character-sheet-test",
+	}
+	router, err := NewRouter(cfg, NewMemoryStores())
+	if err != nil {
+		t.Fatalf("NewRouter returned %v", err)
+	}
+	return &testAPI{t: t, router: router}
+}
+
+// do sends a request with an optional JSON body and headers given as
+// name/value pairs
+func (a *testAPI) do(method, path, token string, body any, headers ...string) *httptest.ResponseRecorder {
+	a.t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			a.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	return w
+}
+
+// register signs up a user and returns their access token
+func (a *testAPI) register(email string) string {
+	a.t.Helper()
+	w := a.do(http.MethodPost, "/api/auth/register", "", map[string]string{
+		"name": "Test", "email": email, "password": "password123",
+	})
+	if w.Code != http.StatusCreated && w.Code != http.StatusOK {
+		a.t.Fatalf("register returned %d: %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	decode(a.t, w, &resp)
+	return resp.Token
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body, err)
+	}
+}
+
+// expectError checks a response is an error with the given status and code
+func expectError(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("got %d, want %d: %s", w.Code, status, w.Body)
+	}
+	var resp struct {
+		Code string `json:"code"`
+	}
+	decode(t, w, &resp)
+	if resp.Code != code {
+		t.Errorf("code = %q, want %q", resp.Code, code)
+	}
+}
+
+func TestCharacterWriteFlow(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.register("owner@example.com")
+
+	w := api.do(http.MethodPost, "/api/characters", token, map[string]any{
+		"name": "Tordek", "race": "Dwarf", "class": "Fighter", "level": 1, "background": "Soldier",
+		"strength": 16, "dexterity": 12, "constitution": 15,
+		"intelligence": 10, "wisdom": 13, "charisma": 8,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create returned %d: %s", w.Code, w.Body)
+	}
+	if etag := w.Header().Get("ETag"); etag != `"1"` {
+		t.Errorf("create ETag = %s, want \"1\"", etag)
+	}
+	var created struct {
+		ID      string `json:"id"`
+		Version int    `json:"version"`
+	}
+	decode(t, w, &created)
+	path := "/api/characters/" + created.ID
+
+	w = api.do(http.MethodGet, path, token, nil)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"1"` {
+		t.Fatalf("get returned %d with ETag %s", w.Code, w.Header().Get("ETag"))
+	}
+
+	// Writes must say which version they were made against
+	rename := map[string]any{"name": "Tordek Ironfist"}
+	expectError(t, api.do(http.MethodPut, path, token, rename), http.StatusPreconditionRequired, "version_required")
+	expectError(t, api.do(http.MethodDelete, path, token, nil), http.StatusPreconditionRequired, "version_required")
+
+	w = api.do(http.MethodPut, path, token, rename, "If-Match", `"1"`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update returned %d: %s", w.Code, w.Body)
+	}
+	if etag := w.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf("update ETag = %s, want \"2\"", etag)
+	}
+	var updated struct {
+		Name    string `json:"name"`
+		Version int    `json:"version"`
+	}
+	decode(t, w, &updated)
+	if updated.Name != "Tordek Ironfist" || updated.Version != 2 {
+		t.Errorf("update returned %+v", updated)
+	}
+
+	// A write against the old version conflicts and reports the current one
+	w = api.do(http.MethodPut, path, token, map[string]any{"name": "Stale"}, "If-Match", `"1"`)
+	expectError(t, w, http.StatusPreconditionFailed, "version_conflict")
+	var conflict struct {
+		Details struct {
+			Name    string `json:"name"`
+			Version int    `json:"version"`
+		} `json:"details"`
+	}
+	decode(t, w, &conflict)
+	if conflict.Details.Name != "Tordek Ironfist" || conflict.Details.Version != 2 {
+		t.Errorf("conflict details = %+v, want the current character", conflict.Details)
+	}
+	expectError(t, api.do(http.MethodDelete, path, token, nil, "If-Match", `"1"`), http.StatusPreconditionFailed, "version_conflict")
+
+	// The version may be sent in the body instead of If-Match
+	w = api.do(http.MethodPut, path, token, map[string]any{"name": "Tordek", "version": 2})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"3"` {
+		t.Fatalf("update with a body version returned %d with ETag %s: %s", w.Code, w.Header().Get("ETag"), w.Body)
+	}
+
+	// Other users can't see the character
+	other := api.register("other@example.com")
+	if w := api.do(http.MethodGet, path, other, nil); w.Code != http.StatusNotFound {
+		t.Errorf("another user's get returned %d, want 404", w.Code)
+	}
+
+	w = api.do(http.MethodDelete, path, token, nil, "If-Match", `"3"`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete returned %d: %s", w.Code, w.Body)
+	}
+	if w := api.do(http.MethodGet, path, token, nil); w.Code != http.StatusNotFound {
+		t.Errorf("get after delete returned %d, want 404", w.Code)
+	}
+}
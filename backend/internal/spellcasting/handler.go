@@ -0,0 +1,144 @@
+package spellcasting
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"character-sheet-backend/internal/apperror"
+)
+
+// Handler handles spellcasting HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new spellcasting handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetSpellbook returns a character's spellcasting, slots and spells
+func (h *Handler) GetSpellbook(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	book, err := h.service.GetSpellbook(c.Param("id"), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
+
+// CreateSpell adds a spell to a character
+func (h *Handler) CreateSpell(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req CreateSpellRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	spell, err := h.service.CreateSpell(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, spell)
+}
+
+// UpdateSpell changes a spell
+func (h *Handler) UpdateSpell(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req UpdateSpellRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	spell, err := h.service.UpdateSpell(c.Param("id"), userID.(string), c.Param("spellId"), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, spell)
+}
+
+// DeleteSpell removes a spell
+func (h *Handler) DeleteSpell(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	if err := h.service.DeleteSpell(c.Param("id"), userID.(string), c.Param("spellId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Spell deleted successfully"})
+}
+
+// ExpendSlots uses spell slots
+func (h *Handler) ExpendSlots(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req SlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	book, err := h.service.ExpendSlots(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
+
+// RestoreSlots gives back spent spell slots
+func (h *Handler) RestoreSlots(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Error(apperror.ErrNotAuthenticated)
+		return
+	}
+
+	var req RestoreSlotsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperror.FromBinding(err))
+		return
+	}
+
+	book, err := h.service.RestoreSlots(c.Param("id"), userID.(string), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
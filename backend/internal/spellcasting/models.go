@@ -0,0 +1,111 @@
+package spellcasting
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Revision actions recorded for slot changes
+const (
+	ActionExpendSlot   = "expend_slot"
+	ActionRestoreSlots = "restore_slots"
+)
+
+// Spell is a spell a character knows, or has in their spellbook
+type Spell struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	CharacterID uuid.UUID `json:"character_id" db:"character_id"`
+	Name        string    `json:"name" db:"name"`
+	// Level is 0 for cantrips
+	Level int `json:"level" db:"level"`
+	// Class is the class the spell was learned through, which sets its
+	// casting ability
+	Class string `json:"class" db:"class"`
+	// Prepared is always true for cantrips and for classes that know their
+	// spells rather than preparing them
+	Prepared  bool      `json:"prepared" db:"prepared"`
+	Notes     *string   `json:"notes" db:"notes"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// GrantedSpell is a spell a subclass keeps always prepared. It doesn't count
+// against the number of spells the class can prepare.
+type GrantedSpell struct {
+	Name     string `json:"name"`
+	Level    int    `json:"level"`
+	Class    string `json:"class"`
+	Subclass string `json:"subclass"`
+}
+
+// CastingClass is how a character casts spells through one class
+type CastingClass struct {
+	Class       string `json:"class"`
+	Subclass    string `json:"subclass,omitempty"`
+	Level       int    `json:"level"`
+	Progression string `json:"progression"`
+	Ability     string `json:"ability"`
+	SaveDC      int    `json:"save_dc"`
+	AttackBonus int    `json:"attack_bonus"`
+	// Prepares is set for classes that choose prepared spells each day.
+	// MaxPrepared is then how many leveled spells they can prepare.
+	Prepares    bool `json:"prepares"`
+	MaxPrepared *int `json:"max_prepared"`
+	Prepared    int  `json:"prepared"`
+}
+
+// Slot is the state of a character's spell slots of one level
+type Slot struct {
+	Level     int `json:"level"`
+	Total     int `json:"total"`
+	Expended  int `json:"expended"`
+	Available int `json:"available"`
+}
+
+// Spellbook is everything about a character's spellcasting
+type Spellbook struct {
+	Casting []*CastingClass `json:"casting"`
+	// CasterLevel is the multiclass spellcaster level the slots come from
+	CasterLevel int     `json:"caster_level"`
+	Slots       []*Slot `json:"slots"`
+	// PactSlots are a warlock's pact magic slots, all of the same level and
+	// restored on a short rest
+	PactSlots      *Slot           `json:"pact_slots"`
+	Spells         []*Spell        `json:"spells"`
+	AlwaysPrepared []*GrantedSpell `json:"always_prepared"`
+}
+
+// CreateSpellRequest represents the request to add a spell. Class defaults
+// to the character's first spellcasting class.
+type CreateSpellRequest struct {
+	Name     string  `json:"name" binding:"required,max=100"`
+	Level    *int    `json:"level" binding:"required,min=0,max=9"`
+	Class    string  `json:"class" binding:"max=100"`
+	Prepared bool    `json:"prepared"`
+	Notes    *string `json:"notes"`
+}
+
+// UpdateSpellRequest represents the request to change a spell
+type UpdateSpellRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Level    *int    `json:"level" binding:"omitempty,min=0,max=9"`
+	Class    *string `json:"class" binding:"omitempty,max=100"`
+	Prepared *bool   `json:"prepared"`
+	Notes    *string `json:"notes"`
+}
+
+// SlotRequest names spell slots to expend or restore: Count slots of Level,
+// or of the pact magic slots when Pact is set
+type SlotRequest struct {
+	Level int  `json:"level" binding:"omitempty,min=1,max=9"`
+	Pact  bool `json:"pact"`
+	Count int  `json:"count" binding:"omitempty,min=1,max=9"`
+}
+
+// RestoreSlotsRequest represents the request to restore spell slots. All
+// restores every slot, as a long rest does.
+type RestoreSlotsRequest struct {
+	SlotRequest
+	All bool `json:"all"`
+}
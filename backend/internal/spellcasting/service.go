@@ -0,0 +1,373 @@
+package spellcasting
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"character-sheet-backend/internal/apperror"
+	"character-sheet-backend/internal/character"
+)
+
+var (
+	// ErrNotSpellcaster is returned when adding spells to a character with
+	// no spellcasting class
+	ErrNotSpellcaster = apperror.Conflict("not_a_spellcaster", "This character has no spellcasting class")
+	// ErrInvalidClass is returned for a spell learned through a class the
+	// character can't cast spells with
+	ErrInvalidClass = apperror.Field("class", "must be one of the character's spellcasting classes")
+	// ErrSpellExists is returned when a class already has the spell
+	ErrSpellExists = apperror.Conflict("spell_exists", "The character already has this spell for this class")
+	// ErrPreparationLimit is returned when preparing more spells than the
+	// class allows
+	ErrPreparationLimit = apperror.Conflict("preparation_limit", "No more spells can be prepared for this class")
+	// ErrNoSlotAvailable is returned when expending a slot that is used up
+	// or that the character doesn't have
+	ErrNoSlotAvailable = apperror.Conflict("no_slot_available", "No spell slot of that level is available")
+	// ErrSlotLevelRequired is returned for slot requests naming neither a
+	// level nor pact slots
+	ErrSlotLevelRequired = apperror.Field("level", "is required unless pact is set")
+)
+
+// Service handles character spells and spell slots
+type Service struct {
+	store      Store
+	characters *character.Service
+}
+
+// NewService creates a new spellcasting service
+func NewService(store Store, characters *character.Service) *Service {
+	return &Service{store: store, characters: characters}
+}
+
+// GetSpellbook returns a character's spellcasting classes, slots and spells
+func (s *Service) GetSpellbook(characterID, userID string) (*Spellbook, error) {
+	char, err := s.characters.Authorize(characterID, userID, character.AccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.spellbook(char)
+}
+
+// CreateSpell adds a spell to a character the user owns
+func (s *Service) CreateSpell(characterID, userID string, req *CreateSpellRequest) (*Spell, error) {
+	char, err := s.characters.Authorize(characterID, userID, character.AccessOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	spell, casting, err := NewSpell(char, req.Class, req.Name, *req.Level)
+	if err != nil {
+		return nil, err
+	}
+	spell.Notes = req.Notes
+	if req.Prepared {
+		spell.Prepared = true
+	}
+
+	if err := s.store.Create(spell, checkSpell(spell, casting)); err != nil {
+		return nil, err
+	}
+
+	return spell, nil
+}
+
+// NewSpell builds a spell a character learns through one of its
+// spellcasting classes, the first one when class is empty. char must have
+// its derived stats. Cantrips and the spells of classes that don't prepare
+// theirs start out prepared.
+func NewSpell(char *character.Character, class, name string, level int) (*Spell, *CastingClass, error) {
+	casting, err := castingClass(char, class)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	spell := &Spell{
+		ID:          uuid.New(),
+		CharacterID: char.ID,
+		Name:        name,
+		Level:       level,
+		Class:       casting.Class,
+		Prepared:    !casting.Prepares || level == 0,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	return spell, casting, nil
+}
+
+// UpdateSpell changes a spell of a character the user owns
+func (s *Service) UpdateSpell(characterID, userID, spellID string, req *UpdateSpellRequest) (*Spell, error) {
+	char, err := s.characters.Authorize(characterID, userID, character.AccessOwner)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidID(spellID) {
+		return nil, ErrSpellNotFound
+	}
+
+	spell, err := s.store.Get(characterID, spellID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		spell.Name = *req.Name
+	}
+	if req.Level != nil {
+		spell.Level = *req.Level
+	}
+	if req.Class != nil {
+		spell.Class = *req.Class
+	}
+	if req.Prepared != nil {
+		spell.Prepared = *req.Prepared
+	}
+	if req.Notes != nil {
+		spell.Notes = req.Notes
+	}
+
+	casting, err := castingClass(char, spell.Class)
+	if err != nil {
+		return nil, err
+	}
+	spell.Class = casting.Class
+	if !casting.Prepares || spell.Level == 0 {
+		spell.Prepared = true
+	}
+	spell.UpdatedAt = time.Now()
+
+	if err := s.store.Update(spell, checkSpell(spell, casting)); err != nil {
+		return nil, err
+	}
+
+	return spell, nil
+}
+
+// DeleteSpell removes a spell from a character the user owns
+func (s *Service) DeleteSpell(characterID, userID, spellID string) error {
+	if _, err := s.characters.Authorize(characterID, userID, character.AccessOwner); err != nil {
+		return err
+	}
+	if !isValidID(spellID) {
+		return ErrSpellNotFound
+	}
+
+	return s.store.Delete(characterID, spellID)
+}
+
+// ExpendSlots uses spell slots of a character the user owns
+func (s *Service) ExpendSlots(characterID, userID string, req *SlotRequest) (*Spellbook, error) {
+	if !req.Pact && req.Level == 0 {
+		return nil, ErrSlotLevelRequired
+	}
+	count := max(req.Count, 1)
+
+	char, err := s.characters.Apply(characterID, userID, character.AccessOwner, ActionExpendSlot, func(c *character.Character) error {
+		classes := classLevels(c)
+		if req.Pact {
+			total, _ := PactSlotsFor(classes)
+			used := min(c.PactSlotsExpended, total)
+			if used+count > total {
+				return ErrNoSlotAvailable
+			}
+			c.PactSlotsExpended = used + count
+			return nil
+		}
+
+		totals := SlotsFor(SpellcasterLevel(classes))
+		if req.Level > len(totals) {
+			return ErrNoSlotAvailable
+		}
+		expended := expendedSlots(c)
+		used := min(expended[req.Level-1], totals[req.Level-1])
+		if used+count > totals[req.Level-1] {
+			return ErrNoSlotAvailable
+		}
+		expended[req.Level-1] = used + count
+		c.SpellSlotsExpended = expended
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.spellbook(char)
+}
+
+// RestoreSlots gives back spent spell slots of a character the user owns
+func (s *Service) RestoreSlots(characterID, userID string, req *RestoreSlotsRequest) (*Spellbook, error) {
+	if !req.All && !req.Pact && req.Level == 0 {
+		return nil, ErrSlotLevelRequired
+	}
+	count := max(req.Count, 1)
+
+	char, err := s.characters.Apply(characterID, userID, character.AccessOwner, ActionRestoreSlots, func(c *character.Character) error {
+		switch {
+		case req.All:
+			c.SpellSlotsExpended = []int{}
+			c.PactSlotsExpended = 0
+		case req.Pact:
+			total, _ := PactSlotsFor(classLevels(c))
+			c.PactSlotsExpended = max(min(c.PactSlotsExpended, total)-count, 0)
+		default:
+			totals := SlotsFor(SpellcasterLevel(classLevels(c)))
+			expended := expendedSlots(c)
+			total := 0
+			if req.Level <= len(totals) {
+				total = totals[req.Level-1]
+			}
+			expended[req.Level-1] = max(min(expended[req.Level-1], total)-count, 0)
+			c.SpellSlotsExpended = expended
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.spellbook(char)
+}
+
+// spellbook assembles the spellcasting state of a character with derived
+// stats
+func (s *Service) spellbook(char *character.Character) (*Spellbook, error) {
+	spells, err := s.store.List(char.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	classes := classLevels(char)
+	book := &Spellbook{
+		Casting:        []*CastingClass{},
+		CasterLevel:    SpellcasterLevel(classes),
+		Slots:          []*Slot{},
+		Spells:         spells,
+		AlwaysPrepared: []*GrantedSpell{},
+	}
+
+	for _, cl := range classes {
+		casting, ok := newCastingClass(char, cl)
+		if !ok {
+			continue
+		}
+		for _, spell := range spells {
+			if classKey(spell.Class) == classKey(cl.Class) && spell.Level > 0 && spell.Prepared {
+				casting.Prepared++
+			}
+		}
+		book.Casting = append(book.Casting, casting)
+
+		for _, granted := range alwaysPrepared[subclassKey(cl.Subclass)] {
+			if cl.Level >= granted.ClassLevel {
+				book.AlwaysPrepared = append(book.AlwaysPrepared, &GrantedSpell{
+					Name:     granted.Name,
+					Level:    granted.SpellLevel,
+					Class:    cl.Class,
+					Subclass: cl.Subclass,
+				})
+			}
+		}
+	}
+
+	expended := expendedSlots(char)
+	for i, total := range SlotsFor(book.CasterLevel) {
+		used := min(expended[i], total)
+		book.Slots = append(book.Slots, &Slot{Level: i + 1, Total: total, Expended: used, Available: total - used})
+	}
+
+	if total, level := PactSlotsFor(classes); total > 0 {
+		used := min(char.PactSlotsExpended, total)
+		book.PactSlots = &Slot{Level: level, Total: total, Expended: used, Available: total - used}
+	}
+
+	return book, nil
+}
+
+// newCastingClass works out how a character casts spells through one class
+func newCastingClass(char *character.Character, cl ClassLevel) (*CastingClass, bool) {
+	caster, ok := casterFor(cl)
+	if !ok {
+		return nil, false
+	}
+
+	mod := char.Derived.Modifiers.ModifierFor(caster.Ability)
+	prof := char.Derived.ProficiencyBonus
+	casting := &CastingClass{
+		Class:       cl.Class,
+		Subclass:    cl.Subclass,
+		Level:       cl.Level,
+		Progression: caster.Progression,
+		Ability:     caster.Ability,
+		SaveDC:      8 + prof + mod,
+		AttackBonus: prof + mod,
+		Prepares:    caster.Prepares,
+	}
+	if caster.Prepares {
+		// Paladins prepare from half their level, other classes their full
+		// level
+		levels := cl.Level
+		if caster.Progression == ProgressionHalf {
+			levels /= 2
+		}
+		maxPrepared := max(mod+levels, 1)
+		casting.MaxPrepared = &maxPrepared
+	}
+	return casting, true
+}
+
+// castingClass finds the spellcasting class a spell is learned through,
+// defaulting to the character's first one
+func castingClass(char *character.Character, class string) (*CastingClass, error) {
+	found := false
+	for _, cl := range classLevels(char) {
+		casting, ok := newCastingClass(char, cl)
+		if !ok {
+			continue
+		}
+		found = true
+		if class == "" || classKey(class) == classKey(cl.Class) {
+			return casting, nil
+		}
+	}
+	if !found {
+		return nil, ErrNotSpellcaster
+	}
+	return nil, ErrInvalidClass
+}
+
+// checkSpell returns a Check accepting spell if the class doesn't already
+// have it and preparing it stays within the class's limit
+func checkSpell(spell *Spell, casting *CastingClass) Check {
+	return func(spells []*Spell) error {
+		prepared := 0
+		for _, other := range spells {
+			if other.ID == spell.ID || classKey(other.Class) != classKey(spell.Class) {
+				continue
+			}
+			if strings.EqualFold(other.Name, spell.Name) {
+				return ErrSpellExists
+			}
+			if other.Level > 0 && other.Prepared {
+				prepared++
+			}
+		}
+
+		if casting.MaxPrepared != nil && spell.Level > 0 && spell.Prepared && prepared >= *casting.MaxPrepared {
+			return ErrPreparationLimit.WithDetails(map[string]interface{}{
+				"class":        casting.Class,
+				"max_prepared": *casting.MaxPrepared,
+			})
+		}
+		return nil
+	}
+}
+
+// isValidID reports whether id is a well-formed UUID, so malformed IDs are
+// treated as missing rather than reaching the database
+func isValidID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
@@ -0,0 +1,107 @@
+package spellcasting
+
+import "character-sheet-backend/internal/character"
+
+// MaxSpellLevel is the highest spell and slot level
+const MaxSpellLevel = 9
+
+// ClassLevel is a character's levels in one class
+type ClassLevel struct {
+	Class    string
+	Subclass string
+	Level    int
+}
+
+// classLevels lists the classes a character has levels in
+func classLevels(c *character.Character) []ClassLevel {
+	levels := make([]ClassLevel, 0, len(c.Classes))
+	for _, entry := range c.Classes {
+		levels = append(levels, ClassLevel{Class: entry.Class, Subclass: entry.Subclass, Level: entry.Levels})
+	}
+	return levels
+}
+
+// casterFor returns how a class entry casts spells. ok is false for classes
+// without spellcasting, or that don't have it yet at their level.
+func casterFor(cl ClassLevel) (caster casterClass, ok bool) {
+	caster, ok = casterClasses[classKey(cl.Class)]
+	if !ok {
+		caster, ok = casterSubclasses[subclassKey(cl.Subclass)]
+	}
+	return caster, ok && cl.Level >= caster.FirstLevel
+}
+
+// SpellcasterLevel is the level spell slots are looked up at. A character
+// with spellcasting from a single class follows that class's table, which
+// rounds half and third casters up; several classes pool their levels,
+// rounding down. Pact magic is separate and doesn't count.
+func SpellcasterLevel(classes []ClassLevel) int {
+	var casting []ClassLevel
+	var progressions []string
+	for _, cl := range classes {
+		if caster, ok := casterFor(cl); ok && caster.Progression != ProgressionPact {
+			casting = append(casting, cl)
+			progressions = append(progressions, caster.Progression)
+		}
+	}
+
+	total := 0
+	for i, cl := range casting {
+		switch {
+		case progressions[i] == ProgressionFull:
+			total += cl.Level
+		case len(casting) == 1 && progressions[i] == ProgressionHalf:
+			total += (cl.Level + 1) / 2
+		case len(casting) == 1 && progressions[i] == ProgressionThird:
+			total += (cl.Level + 2) / 3
+		case progressions[i] == ProgressionHalf:
+			total += cl.Level / 2
+		case progressions[i] == ProgressionThird:
+			total += cl.Level / 3
+		}
+	}
+	return min(total, 20)
+}
+
+// SlotsFor returns the number of slots of each level, 1st level first, at a
+// spellcaster level
+func SlotsFor(casterLevel int) []int {
+	if casterLevel < 1 {
+		return nil
+	}
+	return slotTable[min(casterLevel, 20)]
+}
+
+// PactSlotsFor returns the pact magic slots from a character's warlock
+// levels
+func PactSlotsFor(classes []ClassLevel) (count, level int) {
+	for _, cl := range classes {
+		if caster, ok := casterFor(cl); ok && caster.Progression == ProgressionPact {
+			return pactSlots(cl.Level)
+		}
+	}
+	return 0, 0
+}
+
+// HighestSpellLevel returns the highest level of spell a class entry can
+// learn, or 0 when it only has cantrips or no spellcasting. It follows the
+// class's own table, so levels in other classes don't raise it.
+func HighestSpellLevel(cl ClassLevel) int {
+	caster, ok := casterFor(cl)
+	if !ok {
+		return 0
+	}
+	if caster.Progression == ProgressionPact {
+		_, level := pactSlots(cl.Level)
+		return level
+	}
+	return len(SlotsFor(SpellcasterLevel([]ClassLevel{cl})))
+}
+
+// expendedSlots returns the character's used slots padded to one entry per
+// slot level
+func expendedSlots(c *character.Character) []int {
+	expended := make([]int, MaxSpellLevel)
+	copy(expended, c.SpellSlotsExpended)
+	return expended
+}
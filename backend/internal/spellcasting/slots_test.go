@@ -0,0 +1,102 @@
+package spellcasting
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpellcasterLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		classes []ClassLevel
+		want    int
+	}{
+		{"full caster", []ClassLevel{{Class: "Wizard", Level: 5}}, 5},
+		{"names ignore case", []ClassLevel{{Class: " wizard ", Level: 7}}, 7},
+		{"half caster before spellcasting", []ClassLevel{{Class: "Paladin", Level: 1}}, 0},
+		{"single half caster rounds up", []ClassLevel{{Class: "Paladin", Level: 2}}, 1},
+		{"half caster", []ClassLevel{{Class: "Ranger", Level: 5}}, 3},
+		{"third caster subclass", []ClassLevel{{Class: "Fighter", Subclass: "Eldritch Knight", Level: 3}}, 1},
+		{"third caster before its subclass level", []ClassLevel{{Class: "Fighter", Subclass: "Eldritch Knight", Level: 2}}, 0},
+		{"non-caster", []ClassLevel{{Class: "Fighter", Level: 7}}, 0},
+		{"multiclass half caster rounds down", []ClassLevel{{Class: "Wizard", Level: 3}, {Class: "Paladin", Level: 3}}, 4},
+		{"two half casters", []ClassLevel{{Class: "Paladin", Level: 5}, {Class: "Ranger", Level: 5}}, 4},
+		{"multiclass third caster", []ClassLevel{{Class: "Rogue", Subclass: "Arcane Trickster", Level: 8}, {Class: "Sorcerer", Level: 2}}, 4},
+		{"pact magic doesn't count", []ClassLevel{{Class: "Cleric", Level: 3}, {Class: "Warlock", Level: 5}}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SpellcasterLevel(tt.classes); got != tt.want {
+				t.Errorf("SpellcasterLevel(%+v) = %d, want %d", tt.classes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlotsFor(t *testing.T) {
+	tests := []struct {
+		level int
+		want  []int
+	}{
+		{0, nil},
+		{1, []int{2}},
+		{3, []int{4, 2}},
+		{5, []int{4, 3, 2}},
+		{9, []int{4, 3, 3, 3, 1}},
+		{17, []int{4, 3, 3, 3, 2, 1, 1, 1, 1}},
+		{20, []int{4, 3, 3, 3, 3, 2, 2, 1, 1}},
+		{25, []int{4, 3, 3, 3, 3, 2, 2, 1, 1}},
+	}
+	for _, tt := range tests {
+		if got := SlotsFor(tt.level); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SlotsFor(%d) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+	for level := 1; level <= 20; level++ {
+		if got := len(SlotsFor(level)); got > MaxSpellLevel {
+			t.Errorf("SlotsFor(%d) has %d levels", level, got)
+		}
+	}
+}
+
+func TestPactSlotsFor(t *testing.T) {
+	tests := []struct {
+		classes      []ClassLevel
+		count, level int
+	}{
+		{[]ClassLevel{{Class: "Warlock", Level: 1}}, 1, 1},
+		{[]ClassLevel{{Class: "Warlock", Level: 2}}, 2, 1},
+		{[]ClassLevel{{Class: "Warlock", Level: 5}}, 2, 3},
+		{[]ClassLevel{{Class: "Warlock", Level: 9}}, 2, 5},
+		{[]ClassLevel{{Class: "Warlock", Level: 11}}, 3, 5},
+		{[]ClassLevel{{Class: "Warlock", Level: 17}}, 4, 5},
+		{[]ClassLevel{{Class: "Sorcerer", Level: 3}, {Class: "Warlock", Level: 3}}, 2, 2},
+		{[]ClassLevel{{Class: "Wizard", Level: 5}}, 0, 0},
+	}
+	for _, tt := range tests {
+		count, level := PactSlotsFor(tt.classes)
+		if count != tt.count || level != tt.level {
+			t.Errorf("PactSlotsFor(%+v) = %d, %d, want %d, %d", tt.classes, count, level, tt.count, tt.level)
+		}
+	}
+}
+
+func TestHighestSpellLevel(t *testing.T) {
+	tests := []struct {
+		class ClassLevel
+		want  int
+	}{
+		{ClassLevel{Class: "Wizard", Level: 5}, 3},
+		{ClassLevel{Class: "Wizard", Level: 20}, 9},
+		{ClassLevel{Class: "Paladin", Level: 1}, 0},
+		{ClassLevel{Class: "Paladin", Level: 5}, 2},
+		{ClassLevel{Class: "Warlock", Level: 5}, 3},
+		{ClassLevel{Class: "Fighter", Subclass: "Eldritch Knight", Level: 7}, 2},
+		{ClassLevel{Class: "Fighter", Level: 7}, 0},
+	}
+	for _, tt := range tests {
+		if got := HighestSpellLevel(tt.class); got != tt.want {
+			t.Errorf("HighestSpellLevel(%+v) = %d, want %d", tt.class, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package spellcasting
+
+import "character-sheet-backend/internal/apperror"
+
+// ErrSpellNotFound is returned when a spell doesn't exist on the character
+var ErrSpellNotFound = apperror.NotFound("spell_not_found", "Spell not found")
+
+// Check inspects a character's current spells before a write is applied and
+// returns an error to reject it
+type Check func(spells []*Spell) error
+
+// Store persists the spells characters know. Writes to one character's
+// spells are serialized, so a Check sees the spells exactly as the write
+// will find them.
+type Store interface {
+	// List returns a character's spells by level, then name
+	List(characterID string) ([]*Spell, error)
+	// Get returns a single spell of a character
+	Get(characterID, spellID string) (*Spell, error)
+	// Create stores a new spell if check accepts the character's spells
+	Create(spell *Spell, check Check) error
+	// Update saves an existing spell if check accepts the character's spells
+	Update(spell *Spell, check Check) error
+	// Delete removes a spell
+	Delete(characterID, spellID string) error
+}
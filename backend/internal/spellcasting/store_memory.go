@@ -0,0 +1,111 @@
+package spellcasting
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a spell Store kept in process memory, for tests and demos
+// that run without a database
+type MemoryStore struct {
+	mu     sync.Mutex
+	spells map[string][]*Spell
+}
+
+// NewMemoryStore creates an empty in-memory spell store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{spells: make(map[string][]*Spell)}
+}
+
+// List returns a character's spells by level, then name
+func (s *MemoryStore) List(characterID string) ([]*Spell, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spells := cloneSpells(s.spells[characterID])
+	sort.SliceStable(spells, func(i, j int) bool {
+		if spells[i].Level != spells[j].Level {
+			return spells[i].Level < spells[j].Level
+		}
+		return spells[i].Name < spells[j].Name
+	})
+	return spells, nil
+}
+
+// Get returns a single spell of a character
+func (s *MemoryStore) Get(characterID, spellID string) (*Spell, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, spell := range s.spells[characterID] {
+		if spell.ID.String() == spellID {
+			return spell.clone(), nil
+		}
+	}
+	return nil, ErrSpellNotFound
+}
+
+// Create stores a new spell if check accepts the character's spells
+func (s *MemoryStore) Create(spell *Spell, check Check) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := spell.CharacterID.String()
+	if err := check(cloneSpells(s.spells[id])); err != nil {
+		return err
+	}
+
+	s.spells[id] = append(s.spells[id], spell.clone())
+	return nil
+}
+
+// Update saves an existing spell if check accepts the character's spells
+func (s *MemoryStore) Update(spell *Spell, check Check) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spells := s.spells[spell.CharacterID.String()]
+	for i, existing := range spells {
+		if existing.ID == spell.ID {
+			if err := check(cloneSpells(spells)); err != nil {
+				return err
+			}
+			spells[i] = spell.clone()
+			return nil
+		}
+	}
+	return ErrSpellNotFound
+}
+
+// Delete removes a spell
+func (s *MemoryStore) Delete(characterID, spellID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spells := s.spells[characterID]
+	for i, spell := range spells {
+		if spell.ID.String() == spellID {
+			s.spells[characterID] = append(spells[:i:i], spells[i+1:]...)
+			return nil
+		}
+	}
+	return ErrSpellNotFound
+}
+
+// clone returns a copy of a spell that can be handed out safely
+func (sp *Spell) clone() *Spell {
+	cp := *sp
+	if sp.Notes != nil {
+		notes := *sp.Notes
+		cp.Notes = &notes
+	}
+	return &cp
+}
+
+func cloneSpells(spells []*Spell) []*Spell {
+	cp := make([]*Spell, 0, len(spells))
+	for _, spell := range spells {
+		cp = append(cp, spell.clone())
+	}
+	return cp
+}
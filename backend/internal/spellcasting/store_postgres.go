@@ -0,0 +1,209 @@
+package spellcasting
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// spellColumns is the column list shared by every spell SELECT
+const spellColumns = `
+	id, character_id, name, level, class, prepared, notes, created_at, updated_at`
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// PostgresStore is a spell Store backed by PostgreSQL
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a spell store using the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func scanSpell(row rowScanner) (*Spell, error) {
+	spell := &Spell{}
+	err := row.Scan(
+		&spell.ID, &spell.CharacterID, &spell.Name, &spell.Level, &spell.Class,
+		&spell.Prepared, &spell.Notes, &spell.CreatedAt, &spell.UpdatedAt,
+	)
+	return spell, err
+}
+
+// List returns a character's spells by level, then name
+func (s *PostgresStore) List(characterID string) ([]*Spell, error) {
+	return listSpells(s.db, characterID)
+}
+
+// ListTx returns a character's spells within tx, for writes that change a
+// character and its spells together
+func ListTx(tx *sql.Tx, characterID string) ([]*Spell, error) {
+	return listSpells(tx, characterID)
+}
+
+// InsertTx stores a new spell within tx. Unlike Create it runs no check;
+// the caller is expected to hold the character's row lock.
+func InsertTx(tx *sql.Tx, spell *Spell) error {
+	_, err := tx.Exec(`
+		INSERT INTO character_spells (`+spellColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		spell.ID, spell.CharacterID, spell.Name, spell.Level, spell.Class,
+		spell.Prepared, spell.Notes, spell.CreatedAt, spell.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create spell: %w", err)
+	}
+	return nil
+}
+
+// DeleteTx removes spells of a character within tx. Spells that are already
+// gone are skipped.
+func DeleteTx(tx *sql.Tx, characterID string, spellIDs []uuid.UUID) error {
+	for _, id := range spellIDs {
+		if _, err := tx.Exec(
+			"DELETE FROM character_spells WHERE character_id = $1 AND id = $2",
+			characterID, id,
+		); err != nil {
+			return fmt.Errorf("failed to delete spell: %w", err)
+		}
+	}
+	return nil
+}
+
+func listSpells(q queryer, characterID string) ([]*Spell, error) {
+	query := `SELECT ` + spellColumns + `
+		FROM character_spells
+		WHERE character_id = $1
+		ORDER BY level, name
+	`
+
+	rows, err := q.Query(query, characterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spells: %w", err)
+	}
+	defer rows.Close()
+
+	spells := []*Spell{}
+	for rows.Next() {
+		spell, err := scanSpell(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan spell: %w", err)
+		}
+		spells = append(spells, spell)
+	}
+
+	return spells, rows.Err()
+}
+
+// Get returns a single spell of a character
+func (s *PostgresStore) Get(characterID, spellID string) (*Spell, error) {
+	query := `SELECT ` + spellColumns + `
+		FROM character_spells
+		WHERE character_id = $1 AND id = $2
+	`
+
+	spell, err := scanSpell(s.db.QueryRow(query, characterID, spellID))
+	if err == sql.ErrNoRows {
+		return nil, ErrSpellNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spell: %w", err)
+	}
+
+	return spell, nil
+}
+
+// Create stores a new spell if check accepts the character's spells
+func (s *PostgresStore) Create(spell *Spell, check Check) error {
+	return s.write(spell.CharacterID.String(), check, func(tx *sql.Tx) error {
+		return InsertTx(tx, spell)
+	})
+}
+
+// Update saves an existing spell if check accepts the character's spells
+func (s *PostgresStore) Update(spell *Spell, check Check) error {
+	return s.write(spell.CharacterID.String(), check, func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			UPDATE character_spells SET
+				name = $3, level = $4, class = $5, prepared = $6, notes = $7, updated_at = $8
+			WHERE character_id = $1 AND id = $2`,
+			spell.CharacterID, spell.ID, spell.Name, spell.Level, spell.Class,
+			spell.Prepared, spell.Notes, spell.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update spell: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if n == 0 {
+			return ErrSpellNotFound
+		}
+		return nil
+	})
+}
+
+// Delete removes a spell
+func (s *PostgresStore) Delete(characterID, spellID string) error {
+	result, err := s.db.Exec(
+		"DELETE FROM character_spells WHERE character_id = $1 AND id = $2",
+		characterID, spellID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete spell: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrSpellNotFound
+	}
+
+	return nil
+}
+
+// write runs apply in a transaction holding a lock on the character row, so
+// concurrent writes to the same spell list take turns and check sees the
+// spells as they are when apply runs
+func (s *PostgresStore) write(characterID string, check Check, apply func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT 1 FROM characters WHERE id = $1 FOR UPDATE", characterID); err != nil {
+		return fmt.Errorf("failed to lock character: %w", err)
+	}
+
+	spells, err := listSpells(tx, characterID)
+	if err != nil {
+		return err
+	}
+	if err := check(spells); err != nil {
+		return err
+	}
+
+	if err := apply(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit spells: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package spellcasting
+
+import (
+	"strings"
+
+	"character-sheet-backend/internal/character"
+)
+
+// Caster progressions, i.e. how a class's levels count towards the
+// multiclass spellcaster level
+const (
+	ProgressionFull  = "full"
+	ProgressionHalf  = "half"
+	ProgressionThird = "third"
+	ProgressionPact  = "pact"
+)
+
+// casterClass describes how a class (or a subclass of a non-casting class)
+// casts spells
+type casterClass struct {
+	Progression string
+	Ability     string
+	// Prepares is set for classes that prepare spells from a list each day
+	// rather than knowing a fixed set
+	Prepares bool
+	// FirstLevel is the class level spellcasting starts at
+	FirstLevel int
+}
+
+// casterClasses are the SRD spellcasting classes, keyed by lower case name
+var casterClasses = map[string]casterClass{
+	"bard":     {ProgressionFull, character.Charisma, false, 1},
+	"cleric":   {ProgressionFull, character.Wisdom, true, 1},
+	"druid":    {ProgressionFull, character.Wisdom, true, 1},
+	"sorcerer": {ProgressionFull, character.Charisma, false, 1},
+	"wizard":   {ProgressionFull, character.Intelligence, true, 1},
+	"paladin":  {ProgressionHalf, character.Charisma, true, 2},
+	"ranger":   {ProgressionHalf, character.Wisdom, false, 2},
+	"warlock":  {ProgressionPact, character.Charisma, false, 1},
+}
+
+// casterSubclasses grant spellcasting to classes that otherwise have none
+var casterSubclasses = map[string]casterClass{
+	"eldritch knight":  {ProgressionThird, character.Intelligence, false, 3},
+	"arcane trickster": {ProgressionThird, character.Intelligence, false, 3},
+}
+
+// slotTable is the multiclass spellcaster table: slots per spell level for
+// each spellcaster level, which for a single full caster is the class level
+var slotTable = [21][]int{
+	1:  {2},
+	2:  {3},
+	3:  {4, 2},
+	4:  {4, 3},
+	5:  {4, 3, 2},
+	6:  {4, 3, 3},
+	7:  {4, 3, 3, 1},
+	8:  {4, 3, 3, 2},
+	9:  {4, 3, 3, 3, 1},
+	10: {4, 3, 3, 3, 2},
+	11: {4, 3, 3, 3, 2, 1},
+	12: {4, 3, 3, 3, 2, 1},
+	13: {4, 3, 3, 3, 2, 1, 1},
+	14: {4, 3, 3, 3, 2, 1, 1},
+	15: {4, 3, 3, 3, 2, 1, 1, 1},
+	16: {4, 3, 3, 3, 2, 1, 1, 1},
+	17: {4, 3, 3, 3, 2, 1, 1, 1, 1},
+	18: {4, 3, 3, 3, 3, 1, 1, 1, 1},
+	19: {4, 3, 3, 3, 3, 2, 1, 1, 1},
+	20: {4, 3, 3, 3, 3, 2, 2, 1, 1},
+}
+
+// pactSlots returns the number and level of a warlock's pact magic slots
+func pactSlots(warlockLevel int) (count, level int) {
+	switch {
+	case warlockLevel < 1:
+		return 0, 0
+	case warlockLevel == 1:
+		return 1, 1
+	case warlockLevel < 11:
+		return 2, min((warlockLevel+1)/2, 5)
+	case warlockLevel < 17:
+		return 3, 5
+	default:
+		return 4, 5
+	}
+}
+
+// alwaysPrepared lists the spells SRD subclasses always have prepared, by
+// the class level they are gained at
+var alwaysPrepared = map[string][]grantedSpell{
+	"life": {
+		{1, "Bless", 1}, {1, "Cure Wounds", 1},
+		{3, "Lesser Restoration", 2}, {3, "Spiritual Weapon", 2},
+		{5, "Beacon of Hope", 3}, {5, "Revivify", 3},
+		{7, "Death Ward", 4}, {7, "Guardian of Faith", 4},
+		{9, "Mass Cure Wounds", 5}, {9, "Raise Dead", 5},
+	},
+	"devotion": {
+		{3, "Protection from Evil and Good", 1}, {3, "Sanctuary", 1},
+		{5, "Lesser Restoration", 2}, {5, "Zone of Truth", 2},
+		{9, "Beacon of Hope", 3}, {9, "Dispel Magic", 3},
+		{13, "Freedom of Movement", 4}, {13, "Guardian of Faith", 4},
+		{17, "Commune", 5}, {17, "Flame Strike", 5},
+	},
+}
+
+// grantedSpell is a spell a subclass grants from a class level on
+type grantedSpell struct {
+	ClassLevel int
+	Name       string
+	SpellLevel int
+}
+
+// subclassKey normalizes subclass names so "Life Domain" and "Oath of
+// Devotion" find their entries
+func subclassKey(subclass string) string {
+	key := strings.ToLower(strings.TrimSpace(subclass))
+	key = strings.TrimSuffix(key, " domain")
+	key = strings.TrimPrefix(key, "oath of ")
+	return key
+}
+
+func classKey(class string) string {
+	return strings.ToLower(strings.TrimSpace(class))
+}
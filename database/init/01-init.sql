@@ -1,52 +1,11 @@
--- Create database and user for character sheets application
--- This script is run when the PostgreSQL container starts up
+-- This script is run when the PostgreSQL container starts up.
+--
+-- The schema itself is owned by the backend: numbered migrations in
+-- backend/internal/database/migrations are embedded in the binary and applied
+-- on startup (or with `main migrate up`). Only extensions belong here.
 
--- Enable UUID generation
-CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
-
--- Create users table
-CREATE TABLE IF NOT EXISTS users (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    name VARCHAR(255) NOT NULL,
-    email VARCHAR(255) UNIQUE NOT NULL,
-    password_hash VARCHAR(255) NOT NULL,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-);
-
--- Create characters table
-CREATE TABLE IF NOT EXISTS characters (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-    name VARCHAR(255) NOT NULL,
-    race VARCHAR(100) NOT NULL,
-    class VARCHAR(100) NOT NULL,
-    level INTEGER NOT NULL DEFAULT 1,
-    background VARCHAR(100) NOT NULL,
-    
-    -- Ability scores
-    strength INTEGER NOT NULL DEFAULT 10,
-    dexterity INTEGER NOT NULL DEFAULT 10,
-    constitution INTEGER NOT NULL DEFAULT 10,
-    intelligence INTEGER NOT NULL DEFAULT 10,
-    wisdom INTEGER NOT NULL DEFAULT 10,
-    charisma INTEGER NOT NULL DEFAULT 10,
-    
-    -- Combat stats
-    max_hp INTEGER DEFAULT 0,
-    current_hp INTEGER DEFAULT 0,
-    armor_class INTEGER DEFAULT 10,
-    
-    -- Additional fields
-    notes TEXT,
-    
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-);
-
--- Create indexes for better performance
-CREATE INDEX IF NOT EXISTS idx_characters_user_id ON characters(user_id);
-CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+-- Provides gen_random_uuid() on PostgreSQL versions older than 13
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
 
 -- Grant appropriate permissions (if needed for specific user)
--- GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO your_app_user; 
\ No newline at end of file
+-- GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO your_app_user;
@@ -27,10 +27,18 @@ const CharacterSheet = () => {
 
   const updateCharacter = async (updates) => {
     try {
-      const response = await axios.put(`/api/characters/${id}`, updates);
+      const response = await axios.put(`/api/characters/${id}`, updates, {
+        headers: { 'If-Match': `"${character.version}"` },
+      });
       setCharacter(response.data);
       toast.success('Character updated successfully');
     } catch (error) {
+      if (error.response?.status === 412) {
+        // Someone else saved first; show their version
+        setCharacter(error.response.data.details);
+        toast.error('Character was changed elsewhere, please review and try again');
+        return;
+      }
       toast.error('Failed to update character');
     }
   };
@@ -79,7 +87,7 @@ const CharacterSheet = () => {
         <div className="bg-gray-50 p-4 rounded-lg">
           <h3 className="font-semibold text-gray-700 mb-2">Basic Info</h3>
           <p><strong>Race:</strong> {character.race}</p>
-          <p><strong>Class:</strong> {character.class}</p>
+          <p><strong>Class:</strong> {(character.classes || []).map(c => `${c.class} ${c.levels}`).join(' / ')}</p>
           <p><strong>Level:</strong> {character.level}</p>
           <p><strong>Background:</strong> {character.background}</p>
         </div>
@@ -92,8 +100,37 @@ const CharacterSheet = () => {
 
         <div className="bg-gray-50 p-4 rounded-lg">
           <h3 className="font-semibold text-gray-700 mb-2">Armor Class</h3>
-          <p><strong>AC:</strong> {character.armor_class || 'Not set'}</p>
+          {character.derived && character.derived.armor_class ? (
+            <>
+              <p><strong>AC:</strong> {character.derived.armor_class.total}</p>
+              {character.derived.armor_class.overridden && (
+                <p className="text-sm text-gray-500">Set manually (computed: {character.derived.armor_class.computed})</p>
+              )}
+              {character.derived.armor_class.breakdown.map(part => (
+                <p key={part.source} className="text-sm text-gray-600">{part.source}: {formatModifier(part.value)}</p>
+              ))}
+            </>
+          ) : (
+            <p><strong>AC:</strong> {character.armor_class || 'Not set'}</p>
+          )}
         </div>
+
+        {character.derived && (
+          <div className="bg-gray-50 p-4 rounded-lg">
+            <h3 className="font-semibold text-gray-700 mb-2">Combat</h3>
+            <p><strong>Proficiency Bonus:</strong> {formatModifier(character.derived.proficiency_bonus)}</p>
+            <p><strong>Initiative:</strong> {formatModifier(character.derived.initiative)}</p>
+          </div>
+        )}
+
+        {character.derived && (
+          <div className="bg-gray-50 p-4 rounded-lg">
+            <h3 className="font-semibold text-gray-700 mb-2">Passive Scores</h3>
+            <p><strong>Perception:</strong> {character.derived.passive_perception}</p>
+            <p><strong>Investigation:</strong> {character.derived.passive_investigation}</p>
+            <p><strong>Insight:</strong> {character.derived.passive_insight}</p>
+          </div>
+        )}
       </div>
 
       <div className="mb-8">
@@ -101,7 +138,9 @@ const CharacterSheet = () => {
         <div className="grid grid-cols-2 md:grid-cols-3 gap-4">
           {['strength', 'dexterity', 'constitution', 'intelligence', 'wisdom', 'charisma'].map(ability => {
             const score = character[ability];
-            const modifier = getModifier(score);
+            const modifier = character.derived
+              ? character.derived.modifiers[ability]
+              : getModifier(score);
             
             return (
               <div key={ability} className="bg-gray-50 p-4 rounded-lg text-center">
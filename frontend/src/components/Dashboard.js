@@ -21,13 +21,16 @@ const Dashboard = () => {
     setLoading(false);
   };
 
-  const deleteCharacter = async (id) => {
+  const deleteCharacter = async (character) => {
+    const { id } = character;
     if (!window.confirm('Are you sure you want to delete this character?')) {
       return;
     }
 
     try {
-      await axios.delete(`/api/characters/${id}`);
+      await axios.delete(`/api/characters/${id}`, {
+        headers: { 'If-Match': `"${character.version}"` },
+      });
       setCharacters(characters.filter(char => char.id !== id));
       toast.success('Character deleted successfully');
     } catch (error) {
@@ -70,7 +73,7 @@ const Dashboard = () => {
           {characters.map((character) => (
             <div key={character.id} className="bg-white rounded-lg shadow-md p-6">
               <h2 className="text-xl font-semibold mb-2">{character.name}</h2>
-              <p className="text-gray-600 mb-2">Class: {character.class}</p>
+              <p className="text-gray-600 mb-2">Class: {(character.classes || []).map(c => `${c.class} ${c.levels}`).join(' / ')}</p>
               <p className="text-gray-600 mb-2">Level: {character.level}</p>
               <p className="text-gray-600 mb-4">Race: {character.race}</p>
               
@@ -82,7 +85,7 @@ const Dashboard = () => {
                   View
                 </Link>
                 <button
-                  onClick={() => deleteCharacter(character.id)}
+                  onClick={() => deleteCharacter(character)}
                   className="bg-red-600 hover:bg-red-700 text-white px-3 py-2 rounded text-sm transition-colors"
                 >
                   Delete
@@ -11,11 +11,71 @@ export const useAuth = () => {
   return context;
 };
 
+const storeTokens = ({ token, refresh_token }) => {
+  localStorage.setItem('token', token);
+  localStorage.setItem('refreshToken', refresh_token);
+  axios.defaults.headers.common['Authorization'] = `Bearer ${token}`;
+};
+
+const clearTokens = () => {
+  localStorage.removeItem('token');
+  localStorage.removeItem('refreshToken');
+  delete axios.defaults.headers.common['Authorization'];
+};
+
+// Shared so that concurrent 401s trigger a single refresh; refresh tokens
+// only work once
+let refreshPromise = null;
+
+const refreshTokens = () => {
+  if (!refreshPromise) {
+    const refreshToken = localStorage.getItem('refreshToken');
+    refreshPromise = axios
+      .post('/api/auth/refresh', { refresh_token: refreshToken })
+      .then((response) => {
+        storeTokens(response.data);
+        return response.data.token;
+      })
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+  return refreshPromise;
+};
+
 export const AuthProvider = ({ children }) => {
   const [user, setUser] = useState(null);
   const [loading, setLoading] = useState(true);
 
   useEffect(() => {
+    // Access tokens are short-lived; on a 401 swap the refresh token for a
+    // new pair and retry the request once
+    const interceptor = axios.interceptors.response.use(
+      (response) => response,
+      async (error) => {
+        const request = error.config;
+        if (
+          error.response?.status !== 401 ||
+          request._retried ||
+          (request.url.startsWith('/api/auth/') && request.url !== '/api/auth/me') ||
+          !localStorage.getItem('refreshToken')
+        ) {
+          return Promise.reject(error);
+        }
+
+        request._retried = true;
+        try {
+          const token = await refreshTokens();
+          request.headers['Authorization'] = `Bearer ${token}`;
+          return axios(request);
+        } catch (refreshError) {
+          clearTokens();
+          setUser(null);
+          return Promise.reject(error);
+        }
+      }
+    );
+
     const token = localStorage.getItem('token');
     if (token) {
       axios.defaults.headers.common['Authorization'] = `Bearer ${token}`;
@@ -23,6 +83,8 @@ export const AuthProvider = ({ children }) => {
     } else {
       setLoading(false);
     }
+
+    return () => axios.interceptors.response.eject(interceptor);
   }, []);
 
   const validateToken = async () => {
@@ -30,8 +92,7 @@ export const AuthProvider = ({ children }) => {
       const response = await axios.get('/api/auth/me');
       setUser(response.data);
     } catch (error) {
-      localStorage.removeItem('token');
-      delete axios.defaults.headers.common['Authorization'];
+      clearTokens();
     }
     setLoading(false);
   };
@@ -39,11 +100,8 @@ export const AuthProvider = ({ children }) => {
   const login = async (email, password) => {
     try {
       const response = await axios.post('/api/auth/login', { email, password });
-      const { token, user } = response.data;
-      
-      localStorage.setItem('token', token);
-      axios.defaults.headers.common['Authorization'] = `Bearer ${token}`;
-      setUser(user);
+      storeTokens(response.data);
+      setUser(response.data.user);
       
       return { success: true };
     } catch (error) {
@@ -57,11 +115,8 @@ export const AuthProvider = ({ children }) => {
   const register = async (name, email, password) => {
     try {
       const response = await axios.post('/api/auth/register', { name, email, password });
-      const { token, user } = response.data;
-      
-      localStorage.setItem('token', token);
-      axios.defaults.headers.common['Authorization'] = `Bearer ${token}`;
-      setUser(user);
+      storeTokens(response.data);
+      setUser(response.data.user);
       
       return { success: true };
     } catch (error) {
@@ -72,9 +127,16 @@ export const AuthProvider = ({ children }) => {
     }
   };
 
-  const logout = () => {
-    localStorage.removeItem('token');
-    delete axios.defaults.headers.common['Authorization'];
+  const logout = async ({ allDevices = false } = {}) => {
+    const refreshToken = localStorage.getItem('refreshToken');
+    if (refreshToken) {
+      try {
+        await axios.post('/api/auth/logout', { refresh_token: refreshToken, all: allDevices });
+      } catch (error) {
+        // The session may already be gone; log out locally regardless
+      }
+    }
+    clearTokens();
     setUser(null);
   };
 